@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Haato3o/eskema/core/diff"
+	"io/fs"
+	"os"
+)
+
+// checkGeneratedFile compares the file on disk with what would be written to
+// it and prints the changes as a unified diff, files that don't exist yet are
+// diffed against /dev/null
+func checkGeneratedFile(fileName string, content string) (bool, error) {
+	current, err := os.ReadFile(fileName)
+	currentName := fileName
+
+	if errors.Is(err, fs.ErrNotExist) {
+		currentName = os.DevNull
+	} else if err != nil {
+		return false, err
+	}
+
+	if string(current) == content {
+		return true, nil
+	}
+
+	fmt.Print(diff.Unified(currentName, fileName, string(current), content))
+
+	return false, nil
+}
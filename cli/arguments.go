@@ -1,18 +1,43 @@
 package cli
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrMissingFileName = errors.New("missing filename parameter")
 	ErrMissingLanguage = errors.New("missing language parameter")
+	ErrMissingFormat   = errors.New("missing import format, usage: eskema import <format> [flags] <file>")
+	ErrMissingSamples  = errors.New("missing samples, usage: eskema infer [flags] <sample.json>...")
+	ErrMissingVersions = errors.New("missing schemas, usage: eskema diff [flags] <old.skm> <new.skm>")
+	ErrCheckWithStdout = errors.New("--check compares against the output file, it can't be used without --output")
+	ErrCheckWithWatch  = errors.New("--check and --watch can't be used together")
+)
+
+const (
+	TextDiagnostics = "text"
+	JsonDiagnostics = "json"
 )
 
+func verifyDiagnosticsFormat(format string) error {
+	if format != TextDiagnostics && format != JsonDiagnostics {
+		return fmt.Errorf("unknown diagnostics format '%s', expected text or json", format)
+	}
+
+	return nil
+}
+
 type EskemaArguments struct {
 	FileName                      string
 	Language                      string
+	Templates                     string
 	Output                        string
 	ShouldPrintAST                bool
 	ShouldPrintSupportedLanguages bool
+	ShouldCheck                   bool
+	ShouldWatch                   bool
+	DiagnosticsFormat             string
 }
 
 func (a *EskemaArguments) VerifyRequired() error {
@@ -25,5 +50,91 @@ func (a *EskemaArguments) VerifyRequired() error {
 		return ErrMissingLanguage
 	}
 
+	if a.ShouldCheck && a.Output == "" {
+		return ErrCheckWithStdout
+	}
+
+	if a.ShouldCheck && a.ShouldWatch {
+		return ErrCheckWithWatch
+	}
+
+	return verifyDiagnosticsFormat(a.DiagnosticsFormat)
+}
+
+type ImportArguments struct {
+	Format   string
+	FileName string
+	Output   string
+}
+
+func (a *ImportArguments) VerifyRequired() error {
+
+	if a.Format == "" {
+		return ErrMissingFormat
+	}
+
+	if a.FileName == "" {
+		return ErrMissingFileName
+	}
+
+	return nil
+}
+
+type InferArguments struct {
+	FileNames []string
+	RootName  string
+	Output    string
+}
+
+func (a *InferArguments) VerifyRequired() error {
+
+	if len(a.FileNames) == 0 {
+		return ErrMissingSamples
+	}
+
 	return nil
 }
+
+type FmtArguments struct {
+	FileNames         []string
+	ShouldWrite       bool
+	ShouldList        bool
+	ShouldPrintDiff   bool
+	DiagnosticsFormat string
+}
+
+func (a *FmtArguments) VerifyRequired() error {
+	return verifyDiagnosticsFormat(a.DiagnosticsFormat)
+}
+
+type DiffArguments struct {
+	OldFileName       string
+	NewFileName       string
+	Policy            string
+	Format            string
+	DiagnosticsFormat string
+}
+
+func (a *DiffArguments) VerifyRequired() error {
+
+	if a.OldFileName == "" || a.NewFileName == "" {
+		return ErrMissingVersions
+	}
+
+	return verifyDiagnosticsFormat(a.DiagnosticsFormat)
+}
+
+type GenerateArguments struct {
+	ProjectFileName   string
+	ShouldCheck       bool
+	ShouldWatch       bool
+	DiagnosticsFormat string
+}
+
+func (a *GenerateArguments) VerifyRequired() error {
+	if a.ShouldCheck && a.ShouldWatch {
+		return ErrCheckWithWatch
+	}
+
+	return verifyDiagnosticsFormat(a.DiagnosticsFormat)
+}
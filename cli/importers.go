@@ -0,0 +1,28 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Haato3o/eskema/importer"
+	"github.com/Haato3o/eskema/importer/golang"
+	"github.com/Haato3o/eskema/importer/jsonschema"
+	"github.com/Haato3o/eskema/importer/proto"
+)
+
+const (
+	ErrUnsupportedImportFormat = "import format '%s' is not supported"
+)
+
+var supportedImporters = map[string]importer.SchemaImporter{
+	"proto":      proto.NewProtoImporter(),
+	"jsonschema": jsonschema.NewJsonSchemaImporter(),
+	"go":         golang.NewGoLangImporter(),
+}
+
+func GetSchemaImporter(format string) (importer.SchemaImporter, error) {
+	if schemaImporter, isSupported := supportedImporters[format]; isSupported {
+		return schemaImporter, nil
+	}
+
+	return nil, errors.New(fmt.Sprintf(ErrUnsupportedImportFormat, format))
+}
@@ -1,21 +1,135 @@
 package cli
 
-import "flag"
+import (
+	"flag"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	diagnosticsFormatUsage = "Format of the errors found in schemas: text or json"
+	checkUsage             = "Compare the generated code with the files on disk instead of writing them, exits with 1 when they differ"
+	watchUsage             = "Keep running and generate the code again whenever a schema changes"
+)
+
+// DefaultPolicy is the strictest policy, so clients on old and new schemas alike
+// keep working unless a looser one is asked for
+const DefaultPolicy = "full"
 
 func ParseArguments() *EskemaArguments {
 	fileName := flag.String("filename", "", "Path to the eskema file")
 	language := flag.String("language", "", "Language to parse the schema to")
+	templates := flag.String("templates", "", "Directory of the templates rendered by the template language")
 	output := flag.String("output", "", "Path to where Eskema should save the parsed file. If empty, eskema will output it to STDOUT")
 	shouldPrintAst := flag.Bool("ast", false, "Whether the generated AST should be displayed or not")
 	shouldPrintSupportedLanguages := flag.Bool("langs", false, "Use this command to display the supported languages for Eskema")
+	shouldCheck := flag.Bool("check", false, checkUsage)
+	shouldWatch := flag.Bool("watch", false, watchUsage)
+	diagnosticsFormat := flag.String("diagnostics-format", TextDiagnostics, diagnosticsFormatUsage)
 
 	flag.Parse()
 
 	return &EskemaArguments{
 		FileName:                      *fileName,
 		Language:                      *language,
+		Templates:                     *templates,
 		Output:                        *output,
 		ShouldPrintAST:                *shouldPrintAst,
 		ShouldPrintSupportedLanguages: *shouldPrintSupportedLanguages,
+		ShouldCheck:                   *shouldCheck,
+		ShouldWatch:                   *shouldWatch,
+		DiagnosticsFormat:             *diagnosticsFormat,
+	}
+}
+
+func ParseImportArguments(args []string) *ImportArguments {
+	arguments := &ImportArguments{}
+
+	if len(args) > 0 {
+		arguments.Format = args[0]
+		args = args[1:]
+	}
+
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	output := flags.String("output", "", "Path to where Eskema should save the imported schema. If empty, eskema will output it to STDOUT")
+
+	_ = flags.Parse(args)
+
+	arguments.FileName = flags.Arg(0)
+	arguments.Output = *output
+
+	return arguments
+}
+
+func ParseInferArguments(args []string) *InferArguments {
+	flags := flag.NewFlagSet("infer", flag.ExitOnError)
+	rootName := flags.String("name", "", "Name of the root schema. If empty, eskema will name it after the first sample file")
+	output := flags.String("output", "", "Path to where Eskema should save the inferred schema. If empty, eskema will output it to STDOUT")
+
+	_ = flags.Parse(args)
+
+	arguments := &InferArguments{
+		FileNames: flags.Args(),
+		RootName:  *rootName,
+		Output:    *output,
+	}
+
+	if arguments.RootName == "" && len(arguments.FileNames) > 0 {
+		fileName := filepath.Base(arguments.FileNames[0])
+		arguments.RootName = strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	}
+
+	return arguments
+}
+
+func ParseFmtArguments(args []string) *FmtArguments {
+	flags := flag.NewFlagSet("fmt", flag.ExitOnError)
+	shouldWrite := flags.Bool("w", false, "Write the result to the source file instead of STDOUT")
+	shouldList := flags.Bool("l", false, "List files whose formatting differs from eskema's")
+	shouldPrintDiff := flags.Bool("d", false, "Display diffs instead of rewriting files")
+	diagnosticsFormat := flags.String("diagnostics-format", TextDiagnostics, diagnosticsFormatUsage)
+
+	_ = flags.Parse(args)
+
+	return &FmtArguments{
+		FileNames:         flags.Args(),
+		ShouldWrite:       *shouldWrite,
+		ShouldList:        *shouldList,
+		ShouldPrintDiff:   *shouldPrintDiff,
+		DiagnosticsFormat: *diagnosticsFormat,
+	}
+}
+
+func ParseDiffArguments(args []string) *DiffArguments {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	policy := flags.String("policy", DefaultPolicy, "Compatibility policy to enforce: backward, forward or full")
+	format := flags.String("format", "text", "Format of the report: text or json")
+	diagnosticsFormat := flags.String("diagnostics-format", TextDiagnostics, diagnosticsFormatUsage)
+
+	_ = flags.Parse(args)
+
+	return &DiffArguments{
+		OldFileName:       flags.Arg(0),
+		NewFileName:       flags.Arg(1),
+		Policy:            *policy,
+		Format:            *format,
+		DiagnosticsFormat: *diagnosticsFormat,
+	}
+}
+
+func ParseGenerateArguments(args []string) *GenerateArguments {
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	projectFileName := flags.String("project", "", "Path to the project file. If empty, eskema looks for eskema.yaml, eskema.yml or eskema.json in the current directory")
+	shouldCheck := flags.Bool("check", false, checkUsage)
+	shouldWatch := flags.Bool("watch", false, watchUsage)
+	diagnosticsFormat := flags.String("diagnostics-format", TextDiagnostics, diagnosticsFormatUsage)
+
+	_ = flags.Parse(args)
+
+	return &GenerateArguments{
+		ProjectFileName:   *projectFileName,
+		ShouldCheck:       *shouldCheck,
+		ShouldWatch:       *shouldWatch,
+		DiagnosticsFormat: *diagnosticsFormat,
 	}
 }
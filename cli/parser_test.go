@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"github.com/Haato3o/eskema/core/compatibility"
+	"testing"
+)
+
+func TestParseDiffArguments(t *testing.T) {
+	t.Run("should enforce the full policy by default", func(t *testing.T) {
+		args := ParseDiffArguments([]string{"old.skm", "new.skm"})
+
+		if policy, err := compatibility.ParsePolicy(args.Policy); err != nil || policy != compatibility.FullPolicy {
+			t.Errorf("got %s, expected the full policy", args.Policy)
+		}
+
+		if args.OldFileName != "old.skm" || args.NewFileName != "new.skm" {
+			t.Errorf("got %s and %s, expected old.skm and new.skm", args.OldFileName, args.NewFileName)
+		}
+	})
+
+	t.Run("should use the policy that is passed", func(t *testing.T) {
+		if args := ParseDiffArguments([]string{"--policy", "backward", "old.skm", "new.skm"}); args.Policy != "backward" {
+			t.Errorf("got %s, expected backward", args.Policy)
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package compatibility
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type ChangeKind int
+
+const (
+	DeclarationAdded ChangeKind = iota
+	DeclarationRemoved
+	DeclarationRenamed
+	DeclarationKindChanged
+	GenericsChanged
+	FieldAdded
+	FieldRemoved
+	OptionalityChanged
+	TypeChanged
+	EnumValueAdded
+	EnumValueRemoved
+	EnumValueChanged
+	VariantAdded
+	VariantRemoved
+	DiscriminatorChanged
+	ConstValueChanged
+)
+
+var changeKindNames = map[ChangeKind]string{
+	DeclarationAdded:       "declaration-added",
+	DeclarationRemoved:     "declaration-removed",
+	DeclarationRenamed:     "declaration-renamed",
+	DeclarationKindChanged: "declaration-kind-changed",
+	GenericsChanged:        "generics-changed",
+	FieldAdded:             "field-added",
+	FieldRemoved:           "field-removed",
+	OptionalityChanged:     "optionality-changed",
+	TypeChanged:            "type-changed",
+	EnumValueAdded:         "enum-value-added",
+	EnumValueRemoved:       "enum-value-removed",
+	EnumValueChanged:       "enum-value-changed",
+	VariantAdded:           "variant-added",
+	VariantRemoved:         "variant-removed",
+	DiscriminatorChanged:   "discriminator-changed",
+	ConstValueChanged:      "const-value-changed",
+}
+
+func (k ChangeKind) String() string {
+	return changeKindNames[k]
+}
+
+func (k ChangeKind) MarshalJSON() ([]byte, error) {
+	return json.Marshal(k.String())
+}
+
+// Change is a single difference between two versions of a tree, whether it
+// breaks readers depends on the policy it is evaluated against
+type Change struct {
+	Kind        ChangeKind `json:"kind"`
+	Path        string     `json:"path"`
+	Description string     `json:"description"`
+
+	// IsBackwardBreaking means readers using the new version can't read data
+	// written with the old one
+	IsBackwardBreaking bool `json:"-"`
+
+	// IsForwardBreaking means readers still using the old version can't read
+	// data written with the new one
+	IsForwardBreaking bool `json:"-"`
+}
+
+func (c *Change) String() string {
+	return fmt.Sprintf("%s: %s", c.Path, c.Description)
+}
@@ -0,0 +1,526 @@
+package compatibility
+
+import (
+	"fmt"
+	"github.com/Haato3o/eskema/core/parser"
+	"strings"
+)
+
+// widenings lists the primitives each type can be safely widened to, a reader
+// of the wider type understands every value written with the narrower one
+var widenings = map[string][]string{
+	"Int8":   {"Int16", "Int32", "Int64"},
+	"Int16":  {"Int32", "Int64"},
+	"Int32":  {"Int64"},
+	"UInt8":  {"UInt16", "UInt32", "UInt64"},
+	"UInt16": {"UInt32", "UInt64"},
+	"UInt32": {"UInt64"},
+	"Float":  {"Double"},
+}
+
+type comparer struct {
+	old     *parser.EskemaTree
+	new     *parser.EskemaTree
+	renames map[string]string
+	changes []*Change
+}
+
+// Compare lists every change needed to turn the old tree into the new one, a
+// removed declaration that matches an added one field by field is a rename.
+// Schemas are compared by the fields they end up with, moving a field into a
+// parent doesn't change what is written
+func Compare(old *parser.EskemaTree, new *parser.EskemaTree) []*Change {
+	c := &comparer{
+		old:     old,
+		new:     new,
+		renames: make(map[string]string),
+		changes: make([]*Change, 0),
+	}
+
+	newByName := indexByName(new.Declarations)
+	oldByName := indexByName(old.Declarations)
+
+	added := make([]parser.Declaration, 0)
+
+	for _, decl := range new.Declarations {
+		if _, exists := oldByName[decl.Name()]; !exists {
+			added = append(added, decl)
+		}
+	}
+
+	for _, decl := range old.Declarations {
+		if _, exists := newByName[decl.Name()]; exists {
+			continue
+		}
+
+		if renamed := findRename(old, decl, new, added, c.renames); renamed != nil {
+			c.renames[decl.Name()] = renamed.Name()
+		}
+	}
+
+	renamedTo := make(map[string]bool)
+
+	for _, newName := range c.renames {
+		renamedTo[newName] = true
+	}
+
+	for _, decl := range old.Declarations {
+		if current, exists := newByName[decl.Name()]; exists {
+			c.compareDeclarations(decl, current)
+			continue
+		}
+
+		if newName, isRenamed := c.renames[decl.Name()]; isRenamed {
+			c.add(&Change{
+				Kind:               DeclarationRenamed,
+				Path:               decl.Name(),
+				Description:        fmt.Sprintf("%s renamed to %s", kindOf(decl), newName),
+				IsBackwardBreaking: true,
+				IsForwardBreaking:  true,
+			})
+
+			c.compareDeclarations(decl, newByName[newName])
+			continue
+		}
+
+		// Aliases aren't written anywhere, removing one only matters to the
+		// fields using it, which are compared with the alias resolved.
+		// Constants aren't written either
+		isWritten := kindOf(decl) != "alias" && kindOf(decl) != "const"
+
+		c.add(&Change{
+			Kind:               DeclarationRemoved,
+			Path:               decl.Name(),
+			Description:        fmt.Sprintf("%s removed", kindOf(decl)),
+			IsBackwardBreaking: isWritten,
+			IsForwardBreaking:  isWritten,
+		})
+	}
+
+	for _, decl := range added {
+		if renamedTo[decl.Name()] {
+			continue
+		}
+
+		c.add(&Change{
+			Kind:        DeclarationAdded,
+			Path:        decl.Name(),
+			Description: fmt.Sprintf("%s added", kindOf(decl)),
+		})
+	}
+
+	return c.changes
+}
+
+func (c *comparer) add(change *Change) {
+	c.changes = append(c.changes, change)
+}
+
+func (c *comparer) compareDeclarations(old parser.Declaration, new parser.Declaration) {
+	if kindOf(old) != kindOf(new) {
+		c.add(&Change{
+			Kind:               DeclarationKindChanged,
+			Path:               new.Name(),
+			Description:        fmt.Sprintf("changed from %s to %s", kindOf(old), kindOf(new)),
+			IsBackwardBreaking: true,
+			IsForwardBreaking:  true,
+		})
+
+		return
+	}
+
+	switch old := old.(type) {
+	case *parser.SchemaDefinition:
+		c.compareSchemas(old, new.(*parser.SchemaDefinition))
+	case *parser.EnumDefinition:
+		c.compareEnums(old, new.(*parser.EnumDefinition))
+	case *parser.UnionDefinition:
+		c.compareUnions(old, new.(*parser.UnionDefinition))
+	case *parser.ConstDefinition:
+		c.compareConsts(old, new.(*parser.ConstDefinition))
+	}
+}
+
+// compareConsts reports values that changed, services built with different
+// versions of a constant such as a topic name no longer agree on it
+func (c *comparer) compareConsts(old *parser.ConstDefinition, new *parser.ConstDefinition) {
+	if old.Value.Value == new.Value.Value {
+		return
+	}
+
+	c.add(&Change{
+		Kind:               ConstValueChanged,
+		Path:               new.Id.Name,
+		Description:        fmt.Sprintf("value changed from %s to %s", old.Value.Raw, new.Value.Raw),
+		IsBackwardBreaking: true,
+		IsForwardBreaking:  true,
+	})
+}
+
+func (c *comparer) compareSchemas(old *parser.SchemaDefinition, new *parser.SchemaDefinition) {
+	path := new.Id.Name
+
+	if len(old.Generics) != len(new.Generics) {
+		c.add(&Change{
+			Kind:               GenericsChanged,
+			Path:               path,
+			Description:        fmt.Sprintf("generic parameters changed from %d to %d", len(old.Generics), len(new.Generics)),
+			IsBackwardBreaking: true,
+			IsForwardBreaking:  true,
+		})
+
+		return
+	}
+
+	// Generic parameters are positional, renaming one doesn't change any field
+	generics := make(map[string]string)
+
+	for i, generic := range old.Generics {
+		generics[generic.Id.Name] = new.Generics[i].Id.Name
+	}
+
+	newFields := make(map[string]*parser.FieldExpression)
+
+	for _, field := range c.new.Fields(new) {
+		newFields[field.Id.Name] = field
+	}
+
+	oldFields := make(map[string]*parser.FieldExpression)
+
+	for _, field := range c.old.Fields(old) {
+		oldFields[field.Id.Name] = field
+		fieldPath := path + "." + field.Id.Name
+		current, exists := newFields[field.Id.Name]
+
+		if !exists {
+			c.add(&Change{
+				Kind:              FieldRemoved,
+				Path:              fieldPath,
+				Description:       fmt.Sprintf("%s field removed", optionalityOf(field)),
+				IsForwardBreaking: !field.IsOptional,
+			})
+
+			continue
+		}
+
+		if field.IsOptional != current.IsOptional {
+			c.add(&Change{
+				Kind:               OptionalityChanged,
+				Path:               fieldPath,
+				Description:        fmt.Sprintf("changed from %s to %s", optionalityOf(field), optionalityOf(current)),
+				IsBackwardBreaking: field.IsOptional,
+				IsForwardBreaking:  current.IsOptional,
+			})
+		}
+
+		oldType := c.typeString(c.old.ResolveType(field.Type), generics)
+		newType := c.typeString(c.new.ResolveType(current.Type), nil)
+
+		if oldType == newType {
+			continue
+		}
+
+		isWidened := isWidening(oldType, newType)
+
+		c.add(&Change{
+			Kind:               TypeChanged,
+			Path:               fieldPath,
+			Description:        fmt.Sprintf("type changed from %s to %s", oldType, newType),
+			IsBackwardBreaking: !isWidened,
+			IsForwardBreaking:  true,
+		})
+	}
+
+	for _, field := range c.new.Fields(new) {
+		if _, exists := oldFields[field.Id.Name]; exists {
+			continue
+		}
+
+		c.add(&Change{
+			Kind:               FieldAdded,
+			Path:               path + "." + field.Id.Name,
+			Description:        fmt.Sprintf("%s field added", optionalityOf(field)),
+			IsBackwardBreaking: !field.IsOptional,
+		})
+	}
+}
+
+func (c *comparer) compareEnums(old *parser.EnumDefinition, new *parser.EnumDefinition) {
+	newValues := make(map[string]*parser.EnumValue)
+	newPositions := make(map[string]int)
+
+	for i, value := range new.Values {
+		newValues[value.Id.Name] = value
+		newPositions[value.Id.Name] = i
+	}
+
+	// Members without explicit values are numbered by their position in some
+	// languages, so moving them changes what is written
+	_, oldHasValues := old.ValueKind()
+	_, newHasValues := new.ValueKind()
+	isPositional := !oldHasValues && !newHasValues
+
+	oldValues := make(map[string]bool)
+
+	for position, value := range old.Values {
+		oldValues[value.Id.Name] = true
+		newValue, exists := newValues[value.Id.Name]
+
+		if !exists {
+			c.add(&Change{
+				Kind:               EnumValueRemoved,
+				Path:               new.Id.Name + "." + value.Id.Name,
+				Description:        "enum value removed",
+				IsBackwardBreaking: true,
+			})
+
+			continue
+		}
+
+		// Members are written as their value, readers of either version
+		// would read the other one as a different member
+		if wire, newWire := enumWire(value), enumWire(newValue); wire != newWire {
+			c.add(&Change{
+				Kind:               EnumValueChanged,
+				Path:               new.Id.Name + "." + value.Id.Name,
+				Description:        fmt.Sprintf("enum value changed from %s to %s", wire, newWire),
+				IsBackwardBreaking: true,
+				IsForwardBreaking:  true,
+			})
+		}
+
+		if newPosition := newPositions[value.Id.Name]; isPositional && newPosition != position {
+			c.add(&Change{
+				Kind:               EnumValueChanged,
+				Path:               new.Id.Name + "." + value.Id.Name,
+				Description:        fmt.Sprintf("enum value moved from position %d to %d, which changes its number where members are numbered by position", position, newPosition),
+				IsBackwardBreaking: true,
+				IsForwardBreaking:  true,
+			})
+		}
+	}
+
+	for _, value := range new.Values {
+		if !oldValues[value.Id.Name] {
+			c.add(&Change{
+				Kind:              EnumValueAdded,
+				Path:              new.Id.Name + "." + value.Id.Name,
+				Description:       "enum value added",
+				IsForwardBreaking: true,
+			})
+		}
+	}
+}
+
+// enumWire is how the member is written, its name when it has no value
+func enumWire(value *parser.EnumValue) string {
+	if value.Value == nil {
+		return value.Id.Name
+	}
+
+	return value.Value.Raw
+}
+
+// compareUnions works like compareEnums, readers fail on variants they don't
+// know about. Renamed variants are still the same variant but their name is
+// what is written in the discriminator, so they are reported as removed and
+// added
+func (c *comparer) compareUnions(old *parser.UnionDefinition, new *parser.UnionDefinition) {
+	if old.Discriminator.Name != new.Discriminator.Name {
+		c.add(&Change{
+			Kind:               DiscriminatorChanged,
+			Path:               new.Id.Name,
+			Description:        fmt.Sprintf("discriminator changed from %s to %s", old.Discriminator.Name, new.Discriminator.Name),
+			IsBackwardBreaking: true,
+			IsForwardBreaking:  true,
+		})
+	}
+
+	newVariants := make(map[string]bool)
+
+	for _, variant := range new.Variants {
+		newVariants[variant.Name()] = true
+	}
+
+	oldVariants := make(map[string]bool)
+
+	for _, variant := range old.Variants {
+		oldVariants[variant.Name()] = true
+
+		if !newVariants[variant.Name()] {
+			c.add(&Change{
+				Kind:               VariantRemoved,
+				Path:               new.Id.Name + "." + variant.Name(),
+				Description:        "union variant removed",
+				IsBackwardBreaking: true,
+			})
+		}
+	}
+
+	for _, variant := range new.Variants {
+		if !oldVariants[variant.Name()] {
+			c.add(&Change{
+				Kind:              VariantAdded,
+				Path:              new.Id.Name + "." + variant.Name(),
+				Description:       "union variant added",
+				IsForwardBreaking: true,
+			})
+		}
+	}
+}
+
+// typeString prints an old type as if it was declared in the new tree, so
+// renamed declarations and generic parameters don't count as type changes
+func (c *comparer) typeString(typeExpr *parser.TypeExpression, generics map[string]string) string {
+	name := typeExpr.Id.Name
+
+	if renamed, isRenamed := c.renames[name]; isRenamed {
+		name = renamed
+	}
+
+	if renamed, isGeneric := generics[name]; isGeneric {
+		name = renamed
+	}
+
+	if len(typeExpr.Generics) == 0 {
+		return name
+	}
+
+	arguments := make([]string, 0, len(typeExpr.Generics))
+
+	for _, generic := range typeExpr.Generics {
+		arguments = append(arguments, c.typeString(generic, generics))
+	}
+
+	if typeExpr.Size != nil {
+		arguments = append(arguments, typeExpr.Size.Value)
+	}
+
+	return fmt.Sprintf("%s<%s>", name, strings.Join(arguments, ", "))
+}
+
+func isWidening(from string, to string) bool {
+	for _, wider := range widenings[from] {
+		if wider == to {
+			return true
+		}
+	}
+
+	return false
+}
+
+// findRename looks for an added declaration with the same shape as the removed
+// one, references to the declaration itself are ignored when comparing
+func findRename(old *parser.EskemaTree, removed parser.Declaration, new *parser.EskemaTree, added []parser.Declaration, renames map[string]string) parser.Declaration {
+	// Renaming an alias or a constant doesn't change anything that is written
+	if kind := kindOf(removed); kind == "alias" || kind == "const" {
+		return nil
+	}
+
+	for _, candidate := range added {
+		isTaken := false
+
+		for _, newName := range renames {
+			isTaken = isTaken || newName == candidate.Name()
+		}
+
+		if !isTaken && signatureOf(old, removed) == signatureOf(new, candidate) {
+			return candidate
+		}
+	}
+
+	return nil
+}
+
+func signatureOf(tree *parser.EskemaTree, decl parser.Declaration) string {
+	var builder strings.Builder
+
+	switch data := decl.(type) {
+	case *parser.SchemaDefinition:
+		builder.WriteString(fmt.Sprintf("schema<%d>", len(data.Generics)))
+
+		positions := make(map[string]string)
+
+		for i, generic := range data.Generics {
+			positions[generic.Id.Name] = fmt.Sprintf("$%d", i)
+		}
+
+		positions[decl.Name()] = "$self"
+
+		for _, field := range tree.Fields(data) {
+			builder.WriteString(field.Id.Name)
+			builder.WriteString(":")
+			writeSignatureType(&builder, field.Type, positions)
+			builder.WriteString(optionalityOf(field))
+			builder.WriteString(";")
+		}
+	case *parser.EnumDefinition:
+		builder.WriteString("enum")
+		for i, value := range data.Values {
+			if i > 0 {
+				builder.WriteString(",")
+			}
+
+			builder.WriteString(value.Id.Name)
+		}
+	case *parser.UnionDefinition:
+		builder.WriteString("union:")
+		builder.WriteString(data.Discriminator.Name)
+
+		for _, variant := range data.Variants {
+			builder.WriteString(",")
+			builder.WriteString(variant.Name())
+		}
+	}
+
+	return builder.String()
+}
+
+func writeSignatureType(builder *strings.Builder, typeExpr *parser.TypeExpression, positions map[string]string) {
+	name := typeExpr.Id.Name
+
+	if position, exists := positions[name]; exists {
+		name = position
+	}
+
+	builder.WriteString(name)
+
+	for _, generic := range typeExpr.Generics {
+		builder.WriteString("<")
+		writeSignatureType(builder, generic, positions)
+		builder.WriteString(">")
+	}
+}
+
+func indexByName(declarations []parser.Declaration) map[string]parser.Declaration {
+	index := make(map[string]parser.Declaration, len(declarations))
+
+	for _, decl := range declarations {
+		index[decl.Name()] = decl
+	}
+
+	return index
+}
+
+func kindOf(decl parser.Declaration) string {
+	switch decl.(type) {
+	case *parser.EnumDefinition:
+		return "enum"
+	case *parser.UnionDefinition:
+		return "union"
+	case *parser.AliasDefinition:
+		return "alias"
+	case *parser.ConstDefinition:
+		return "const"
+	default:
+		return "schema"
+	}
+}
+
+func optionalityOf(field *parser.FieldExpression) string {
+	if field.IsOptional {
+		return "optional"
+	}
+
+	return "required"
+}
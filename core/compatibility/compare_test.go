@@ -0,0 +1,275 @@
+package compatibility
+
+import (
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/syntax"
+	"testing"
+)
+
+func parse(t *testing.T, source string) *parser.EskemaTree {
+	eskemaParser := parser.New(syntax.NewLexer([]byte(source), "test.skm").Lex())
+	tree := eskemaParser.Parse()
+
+	if errs := eskemaParser.Errors(); len(errs) > 0 {
+		t.Fatalf("got %v, expected no syntax errors", errs)
+	}
+
+	return tree
+}
+
+func TestCompare(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Old      string
+		New      string
+		Expected []string
+		Breaking map[Policy][]bool
+	}{
+		{
+			"should report nothing when trees are equal",
+			"schema A { a: String };",
+			"schema A { a: String };",
+			[]string{},
+			map[Policy][]bool{},
+		},
+		{
+			"should break old readers when a required field is removed",
+			"schema A { a: String, b: Int32? };",
+			"schema A { };",
+			[]string{
+				"field-removed A.a: required field removed",
+				"field-removed A.b: optional field removed",
+			},
+			map[Policy][]bool{
+				BackwardPolicy: {false, false},
+				ForwardPolicy:  {true, false},
+				FullPolicy:     {true, false},
+			},
+		},
+		{
+			"should break new readers when a required field is added",
+			"schema A { a: String };",
+			"schema A { a: String, b: Int32, c: Int32? };",
+			[]string{
+				"field-added A.b: required field added",
+				"field-added A.c: optional field added",
+			},
+			map[Policy][]bool{
+				BackwardPolicy: {true, false},
+				ForwardPolicy:  {false, false},
+			},
+		},
+		{
+			"should classify optionality changes by direction",
+			"schema A { a: String, b: String? };",
+			"schema A { a: String?, b: String };",
+			[]string{
+				"optionality-changed A.a: changed from required to optional",
+				"optionality-changed A.b: changed from optional to required",
+			},
+			map[Policy][]bool{
+				BackwardPolicy: {false, true},
+				ForwardPolicy:  {true, false},
+			},
+		},
+		{
+			"should allow numeric widening for new readers only",
+			"schema A { a: Int32, b: Int64, c: Array<String>, d: Array<UInt8, 16> };",
+			"schema A { a: Int64, b: Int32, c: Array<Int32>, d: Array<UInt8, 32> };",
+			[]string{
+				"type-changed A.a: type changed from Int32 to Int64",
+				"type-changed A.b: type changed from Int64 to Int32",
+				"type-changed A.c: type changed from Array<String> to Array<Int32>",
+				"type-changed A.d: type changed from Array<UInt8, 16> to Array<UInt8, 32>",
+			},
+			map[Policy][]bool{
+				BackwardPolicy: {false, true, true, true},
+				ForwardPolicy:  {true, true, true, true},
+			},
+		},
+		{
+			"should classify enum values by direction",
+			"enum E { X, Y };",
+			"enum E { X, Z };",
+			[]string{
+				"enum-value-removed E.Y: enum value removed",
+				"enum-value-added E.Z: enum value added",
+			},
+			map[Policy][]bool{
+				BackwardPolicy: {true, false},
+				ForwardPolicy:  {false, true},
+				FullPolicy:     {true, true},
+			},
+		},
+		{
+			"should break every reader when the value of an enum member changes",
+			"enum E { X = 1, Y = 2 };",
+			"enum E { X = 1, Y = 3 };",
+			[]string{
+				"enum-value-changed E.Y: enum value changed from 2 to 3",
+			},
+			map[Policy][]bool{
+				BackwardPolicy: {true},
+				ForwardPolicy:  {true},
+			},
+		},
+		{
+			"should break every reader when members without values are reordered",
+			"enum S { A, B, C };",
+			"enum S { C, A, B };",
+			[]string{
+				"enum-value-changed S.A: enum value moved from position 0 to 1, which changes its number where members are numbered by position",
+				"enum-value-changed S.B: enum value moved from position 1 to 2, which changes its number where members are numbered by position",
+				"enum-value-changed S.C: enum value moved from position 2 to 0, which changes its number where members are numbered by position",
+			},
+			map[Policy][]bool{
+				FullPolicy: {true, true, true},
+			},
+		},
+		{
+			"should break every reader when a member without a value is inserted before others",
+			"enum S { A, B };",
+			"enum S { A, X, B, Y };",
+			[]string{
+				"enum-value-changed S.B: enum value moved from position 1 to 2, which changes its number where members are numbered by position",
+				"enum-value-added S.X: enum value added",
+				"enum-value-added S.Y: enum value added",
+			},
+			map[Policy][]bool{
+				BackwardPolicy: {true, false, false},
+				ForwardPolicy:  {true, true, true},
+			},
+		},
+		{
+			"should not care about the order of members with values",
+			"enum S { A = 1, B = 2 };",
+			"enum S { B = 2, A = 1 };",
+			[]string{},
+			map[Policy][]bool{},
+		},
+		{
+			"should classify union variants by direction and break on discriminator changes",
+			"schema A { }; schema B { }; schema C { }; union U: type { A, B };",
+			"schema A { }; schema B { }; schema C { }; union U: kind { A, C };",
+			[]string{
+				"discriminator-changed U: discriminator changed from type to kind",
+				"variant-removed U.B: union variant removed",
+				"variant-added U.C: union variant added",
+			},
+			map[Policy][]bool{
+				BackwardPolicy: {true, true, false},
+				ForwardPolicy:  {true, false, true},
+			},
+		},
+		{
+			"should compare the fields schemas inherit",
+			"schema A { id: Int64, name: String };",
+			"schema Base { id: Int64 }; schema A extends Base { name: String, at: DateTime };",
+			[]string{
+				"field-added A.at: required field added",
+				"declaration-added Base: schema added",
+			},
+			map[Policy][]bool{
+				BackwardPolicy: {true, false},
+			},
+		},
+		{
+			"should compare fields with their aliases resolved",
+			"alias Id = Int32; schema A { id: Id, owner: Int64 };",
+			"alias Id = Int64; alias OwnerId = Int64; schema A { id: Id, owner: OwnerId };",
+			[]string{
+				"type-changed A.id: type changed from Int32 to Int64",
+				"declaration-added OwnerId: alias added",
+			},
+			map[Policy][]bool{
+				BackwardPolicy: {false, false},
+				ForwardPolicy:  {true, false},
+			},
+		},
+		{
+			"should report constants whose value changed",
+			"const TOPIC: String = \"orders\"; const MAX: Int32 = 10; const OLD: Bool = true;",
+			"const TOPIC: String = \"orders.v2\"; const MAX: Int32 = 10; const NEW: Bool = true;",
+			[]string{
+				"const-value-changed TOPIC: value changed from \"orders\" to \"orders.v2\"",
+				"declaration-removed OLD: const removed",
+				"declaration-added NEW: const added",
+			},
+			map[Policy][]bool{
+				BackwardPolicy: {true, false, false},
+				ForwardPolicy:  {true, false, false},
+			},
+		},
+		{
+			"should detect renamed schemas and follow their references",
+			"schema Person { name: String, friends: Array<Person> }; schema Post { author: Person };",
+			"schema User { name: String, friends: Array<User> }; schema Post { author: User };",
+			[]string{
+				"declaration-renamed Person: schema renamed to User",
+			},
+			map[Policy][]bool{
+				BackwardPolicy: {true},
+			},
+		},
+		{
+			"should ignore renamed generic parameters",
+			"schema Page<T> { items: Array<T> };",
+			"schema Page<TItem> { items: Array<TItem> };",
+			[]string{},
+			map[Policy][]bool{},
+		},
+		{
+			"should report added and removed declarations",
+			"schema A { a: String }; enum B { X };",
+			"schema A { a: String }; schema C { c: Int32 };",
+			[]string{
+				"declaration-removed B: enum removed",
+				"declaration-added C: schema added",
+			},
+			map[Policy][]bool{
+				BackwardPolicy: {true, false},
+				ForwardPolicy:  {true, false},
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			changes := Compare(parse(t, testCase.Old), parse(t, testCase.New))
+
+			if len(changes) != len(testCase.Expected) {
+				t.Fatalf("got %v, expected %v", changes, testCase.Expected)
+			}
+
+			for i, change := range changes {
+				if actual := change.Kind.String() + " " + change.String(); actual != testCase.Expected[i] {
+					t.Errorf("got '%s', expected '%s'", actual, testCase.Expected[i])
+				}
+			}
+
+			for policy, expected := range testCase.Breaking {
+				for i, change := range changes {
+					if policy.IsBreaking(change) != expected[i] {
+						t.Errorf("got breaking %v for '%s' under %s, expected %v", !expected[i], change, policy, expected[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestReport(t *testing.T) {
+	changes := Compare(parse(t, "enum E { X };"), parse(t, "enum E { X, Y };"))
+
+	if report := NewReport(BackwardPolicy, changes); report.IsBreaking {
+		t.Errorf("got a breaking report, expected adding an enum value to be backward compatible")
+	}
+
+	if report := NewReport(FullPolicy, changes); !report.IsBreaking || !report.Changes[0].IsBreaking {
+		t.Errorf("got a compatible report, expected adding an enum value to break under the full policy")
+	}
+
+	if _, err := ParsePolicy("sideways"); err == nil {
+		t.Errorf("got no error, expected unknown policies to be rejected")
+	}
+}
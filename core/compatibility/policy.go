@@ -0,0 +1,50 @@
+package compatibility
+
+import "fmt"
+
+type Policy int
+
+const (
+	BackwardPolicy Policy = iota
+	ForwardPolicy
+	FullPolicy
+)
+
+var policies = map[string]Policy{
+	"backward": BackwardPolicy,
+	"forward":  ForwardPolicy,
+	"full":     FullPolicy,
+}
+
+func (p Policy) String() string {
+	for name, policy := range policies {
+		if policy == p {
+			return name
+		}
+	}
+
+	return "unknown"
+}
+
+// IsBreaking tells whether the change breaks the guarantees of the policy,
+// backward protects new readers, forward protects old readers and full both
+func (p Policy) IsBreaking(change *Change) bool {
+	switch p {
+	case BackwardPolicy:
+		return change.IsBackwardBreaking
+	case ForwardPolicy:
+		return change.IsForwardBreaking
+	default:
+		return change.IsBackwardBreaking || change.IsForwardBreaking
+	}
+}
+
+func ParsePolicy(name string) (Policy, error) {
+	policy, exists := policies[name]
+
+	if !exists {
+		return 0, fmt.Errorf("unknown compatibility policy '%s', expected backward, forward or full", name)
+	}
+
+	return policy, nil
+}
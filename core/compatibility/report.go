@@ -0,0 +1,28 @@
+package compatibility
+
+type ReportEntry struct {
+	*Change
+	IsBreaking bool `json:"breaking"`
+}
+
+// Report is the outcome of evaluating every change against a single policy
+type Report struct {
+	Policy     string         `json:"policy"`
+	IsBreaking bool           `json:"breaking"`
+	Changes    []*ReportEntry `json:"changes"`
+}
+
+func NewReport(policy Policy, changes []*Change) *Report {
+	report := &Report{
+		Policy:  policy.String(),
+		Changes: make([]*ReportEntry, 0, len(changes)),
+	}
+
+	for _, change := range changes {
+		entry := &ReportEntry{Change: change, IsBreaking: policy.IsBreaking(change)}
+		report.IsBreaking = report.IsBreaking || entry.IsBreaking
+		report.Changes = append(report.Changes, entry)
+	}
+
+	return report
+}
@@ -0,0 +1,180 @@
+package diagnostics
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Haato3o/eskema/core/syntax"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	colorReset  = "\x1b[0m"
+	colorBold   = "\x1b[1m"
+	colorRed    = "\x1b[31m"
+	colorYellow = "\x1b[33m"
+	colorBlue   = "\x1b[34m"
+	colorCyan   = "\x1b[36m"
+	colorGreen  = "\x1b[32m"
+)
+
+var severityColors = map[syntax.Severity]string{
+	syntax.SeverityError:   colorRed,
+	syntax.SeverityWarning: colorYellow,
+	syntax.SeverityNote:    colorCyan,
+}
+
+// Renderer prints diagnostics along with the source line they point to, files
+// that weren't registered with AddSource are read from disk when needed
+type Renderer struct {
+	IsColored bool
+	sources   map[string][]string
+}
+
+func (r *Renderer) AddSource(fileName string, source []byte) {
+	r.sources[fileName] = strings.Split(string(source), "\n")
+}
+
+// Render prints the header of the diagnostic and, when its span points at a line
+// of a source, the line with the span underlined. Diagnostics without a position,
+// like the ones of importers and project files, only get their header
+func (r *Renderer) Render(diagnostic *syntax.Diagnostic) string {
+	var builder strings.Builder
+
+	gutter := " "
+
+	builder.WriteString(r.paint(colorBold+severityColors[diagnostic.Severity], fmt.Sprintf("%s[%s]", diagnostic.Severity, diagnostic.Code)))
+	builder.WriteString(r.paint(colorBold, ": "+diagnostic.Message))
+	builder.WriteString("\n")
+
+	if start := diagnostic.Span.Start; diagnostic.Span.IsValid() && start.Line > 0 {
+		gutter = strings.Repeat(" ", len(strconv.FormatInt(start.Line, 10)))
+
+		builder.WriteString(fmt.Sprintf("%s%s %s:%d:%d\n", gutter, r.paint(colorBlue, "-->"), start.Filename, start.Line, start.Column))
+
+		if line, exists := r.line(start.Filename, start.Line); exists {
+			bar := r.paint(colorBlue, "|")
+
+			builder.WriteString(fmt.Sprintf("%s %s\n", gutter, bar))
+			builder.WriteString(fmt.Sprintf("%s %s %s\n", r.paint(colorBlue, strconv.FormatInt(start.Line, 10)), bar, line))
+			builder.WriteString(fmt.Sprintf("%s %s %s%s\n", gutter, bar, caretPadding(line, start.Column), r.carets(diagnostic, line)))
+		}
+	} else if diagnostic.Span.IsValid() && start.Filename != "" {
+		builder.WriteString(fmt.Sprintf("%s%s %s\n", gutter, r.paint(colorBlue, "-->"), start.Filename))
+	}
+
+	for _, note := range diagnostic.Notes {
+		message := note.Message
+
+		if note.Span != nil {
+			message = fmt.Sprintf("%s at %s:%d:%d", message, note.Span.Start.Filename, note.Span.Start.Line, note.Span.Start.Column)
+		}
+
+		builder.WriteString(fmt.Sprintf("%s %s %s\n", gutter, r.paint(colorBlue, "="), r.paint(colorBold, "note: ")+message))
+	}
+
+	if fix := diagnostic.Fix; fix != nil {
+		builder.WriteString(fmt.Sprintf("%s %s %s\n", gutter, r.paint(colorBlue, "="), r.paint(colorBold+colorGreen, "help: ")+fix.Message))
+	}
+
+	return builder.String()
+}
+
+func (r *Renderer) RenderAll(writer io.Writer, diagnostics []*syntax.Diagnostic) {
+	for _, diagnostic := range diagnostics {
+		_, _ = fmt.Fprintln(writer, r.Render(diagnostic))
+	}
+}
+
+// carets underline the span up to the end of the line it starts on
+func (r *Renderer) carets(diagnostic *syntax.Diagnostic, line string) string {
+	length := diagnostic.Span.Length
+
+	if remaining := len(line) - int(diagnostic.Span.Start.Column) + 1; length > remaining {
+		length = remaining
+	}
+
+	if length < 1 {
+		length = 1
+	}
+
+	return r.paint(colorBold+severityColors[diagnostic.Severity], strings.Repeat("^", length))
+}
+
+func (r *Renderer) line(fileName string, number int64) (string, bool) {
+	lines, exists := r.sources[fileName]
+
+	if !exists {
+		source, err := os.ReadFile(fileName)
+
+		if err != nil {
+			return "", false
+		}
+
+		r.AddSource(fileName, source)
+		lines = r.sources[fileName]
+	}
+
+	if number < 1 || int(number) > len(lines) {
+		return "", false
+	}
+
+	return strings.TrimRight(lines[number-1], "\r"), true
+}
+
+func (r *Renderer) paint(color string, text string) string {
+	if !r.IsColored {
+		return text
+	}
+
+	return color + text + colorReset
+}
+
+// caretPadding lines the carets up with the column, tabs are kept so the
+// padding is as wide as the text above it
+func caretPadding(line string, column int64) string {
+	var builder strings.Builder
+
+	for i := 0; i < int(column)-1 && i < len(line); i++ {
+		if line[i] == '\t' {
+			builder.WriteByte('\t')
+		} else {
+			builder.WriteByte(' ')
+		}
+	}
+
+	return builder.String()
+}
+
+// WriteJson writes the diagnostics as a single JSON array
+func WriteJson(writer io.Writer, diagnostics []*syntax.Diagnostic) error {
+	if diagnostics == nil {
+		diagnostics = make([]*syntax.Diagnostic, 0)
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(diagnostics)
+}
+
+// IsTerminal tells whether colors should be used for the file, NO_COLOR turns
+// them off regardless
+func IsTerminal(file *os.File) bool {
+	if _, isDisabled := os.LookupEnv("NO_COLOR"); isDisabled {
+		return false
+	}
+
+	info, err := file.Stat()
+
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+func NewRenderer(isColored bool) *Renderer {
+	return &Renderer{
+		IsColored: isColored,
+		sources:   make(map[string][]string),
+	}
+}
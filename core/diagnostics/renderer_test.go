@@ -0,0 +1,133 @@
+package diagnostics
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/syntax"
+	"testing"
+)
+
+func diagnose(source string) []*syntax.Diagnostic {
+	eskemaParser := parser.New(syntax.NewLexer([]byte(source), "test.skm").Lex())
+	eskemaParser.Parse()
+
+	return eskemaParser.Diagnostics()
+}
+
+func TestRender(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Input    string
+		Expected string
+	}{
+		{
+			"should underline the unexpected token and suggest the missing one",
+			"schema A { a: String }\nschema B { b: Int32 };",
+			"error[E0002]: expected ';', got 'schema'\n" +
+				" --> test.skm:2:1\n" +
+				"  |\n" +
+				"2 | schema B { b: Int32 };\n" +
+				"  | ^^^^^^\n" +
+				"  = help: insert ';'\n",
+		},
+		{
+			"should point at invalid characters",
+			"schema A { a: Strin$g };",
+			"error[E0001]: invalid character '$' in 'Strin$g'\n" +
+				" --> test.skm:1:20\n" +
+				"  |\n" +
+				"1 | schema A { a: Strin$g };\n" +
+				"  |                    ^\n" +
+				"  = note: names can only contain letters, digits and underscores\n" +
+				"  = help: remove the character\n",
+		},
+		{
+			"should point back to the opening brace of an unclosed body",
+			"enum E {\n    X,\n    Y",
+			"error[E0002]: expected ',' or '}', got 'EOF'\n" +
+				" --> test.skm:3:6\n" +
+				"  |\n" +
+				"3 |     Y\n" +
+				"  |      ^\n" +
+				"  = note: body starts here at test.skm:1:8\n" +
+				"  = help: insert '}'\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			found := diagnose(testCase.Input)
+
+			if len(found) != 1 {
+				t.Fatalf("got %v, expected a single diagnostic", found)
+			}
+
+			renderer := NewRenderer(false)
+			renderer.AddSource("test.skm", []byte(testCase.Input))
+
+			if actual := renderer.Render(found[0]); actual != testCase.Expected {
+				t.Errorf("got\n%s\nexpected\n%s", actual, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestRenderWithoutPosition(t *testing.T) {
+	testCases := []struct {
+		Name       string
+		Diagnostic *syntax.Diagnostic
+		Expected   string
+	}{
+		{
+			"should only render the header of diagnostics without a span",
+			&syntax.Diagnostic{Severity: syntax.SeverityError, Code: syntax.CodeUnexpectedToken, Message: "no inputs to compile"},
+			"error[E0002]: no inputs to compile\n",
+		},
+		{
+			"should render the file of diagnostics without a line",
+			&syntax.Diagnostic{
+				Severity: syntax.SeverityWarning,
+				Code:     syntax.CodeUnknownType,
+				Message:  "imported as String",
+				Span:     syntax.Span{Start: &syntax.Metadata{Filename: "user.schema.json"}},
+				Notes:    []*syntax.Note{{Message: "unsupported keyword"}},
+			},
+			"warning[W0001]: imported as String\n" +
+				" --> user.schema.json\n" +
+				"  = note: unsupported keyword\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			if actual := NewRenderer(false).Render(testCase.Diagnostic); actual != testCase.Expected {
+				t.Errorf("got\n%s\nexpected\n%s", actual, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestWriteJson(t *testing.T) {
+	var buffer bytes.Buffer
+
+	if err := WriteJson(&buffer, diagnose("schema A { a: String }")); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded []map[string]interface{}
+
+	if err := json.Unmarshal(buffer.Bytes(), &decoded); err != nil {
+		t.Fatalf("got %v, expected valid JSON", err)
+	}
+
+	if len(decoded) != 1 || decoded[0]["severity"] != "error" || decoded[0]["code"] != syntax.CodeUnexpectedToken {
+		t.Errorf("got %v, expected a single unexpected token error", decoded)
+	}
+
+	span := decoded[0]["span"].(map[string]interface{})
+
+	if span["line"] != float64(1) || span["column"] != float64(23) {
+		t.Errorf("got %v, expected the span to start at 1:23", span)
+	}
+}
@@ -0,0 +1,238 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+const contextLines = 3
+
+type operationKind int
+
+const (
+	equalOperation operationKind = iota
+	deleteOperation
+	insertOperation
+)
+
+type operation struct {
+	Kind operationKind
+	Line string
+}
+
+// Unified returns the differences between two texts in the unified diff format,
+// it is empty when both texts are equal
+func Unified(fromName string, toName string, from string, to string) string {
+	if from == to {
+		return ""
+	}
+
+	operations := computeOperations(splitLines(from), splitLines(to))
+
+	var builder strings.Builder
+
+	builder.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", fromName, toName))
+
+	for start := 0; start < len(operations); {
+		if operations[start].Kind == equalOperation {
+			start++
+			continue
+		}
+
+		hunkStart := maxInt(start-contextLines, 0)
+		hunkEnd := start
+
+		// A hunk keeps growing while the next change is close enough to share context
+		for hunkEnd < len(operations) {
+			if operations[hunkEnd].Kind != equalOperation {
+				hunkEnd++
+				continue
+			}
+
+			nextChange := hunkEnd
+
+			for nextChange < len(operations) && operations[nextChange].Kind == equalOperation {
+				nextChange++
+			}
+
+			if nextChange == len(operations) || nextChange-hunkEnd > contextLines*2 {
+				hunkEnd = minInt(hunkEnd+contextLines, len(operations))
+				break
+			}
+
+			hunkEnd = nextChange
+		}
+
+		writeHunk(&builder, operations, hunkStart, hunkEnd)
+
+		start = hunkEnd
+	}
+
+	return builder.String()
+}
+
+func writeHunk(builder *strings.Builder, operations []operation, start int, end int) {
+	fromLine, toLine := 1, 1
+
+	for _, op := range operations[:start] {
+		if op.Kind != insertOperation {
+			fromLine++
+		}
+
+		if op.Kind != deleteOperation {
+			toLine++
+		}
+	}
+
+	fromCount, toCount := 0, 0
+
+	for _, op := range operations[start:end] {
+		if op.Kind != insertOperation {
+			fromCount++
+		}
+
+		if op.Kind != deleteOperation {
+			toCount++
+		}
+	}
+
+	builder.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(fromLine, fromCount), hunkRange(toLine, toCount)))
+
+	prefixes := map[operationKind]string{
+		equalOperation:  " ",
+		deleteOperation: "-",
+		insertOperation: "+",
+	}
+
+	for _, op := range operations[start:end] {
+		builder.WriteString(prefixes[op.Kind])
+		builder.WriteString(op.Line)
+
+		if !strings.HasSuffix(op.Line, "\n") {
+			builder.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+func hunkRange(line int, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", line-1)
+	}
+
+	if count == 1 {
+		return fmt.Sprintf("%d", line)
+	}
+
+	return fmt.Sprintf("%d,%d", line, count)
+}
+
+// computeOperations finds the shortest edit script between both sides with
+// Myers' algorithm, only the diagonals reached by each edit are kept around so
+// memory grows with the number of changes instead of the size of the texts
+func computeOperations(from []string, to []string) []operation {
+	n, m := len(from), len(to)
+	offset := n + m + 1
+	frontier := make([]int, 2*offset+1)
+	trace := make([][]int, 0)
+
+	for edits := 0; edits <= n+m; edits++ {
+		// Each step only reads the diagonals next to the ones it reaches
+		trace = append(trace, append([]int(nil), frontier[offset-edits-1:offset+edits+2]...))
+
+		for k := -edits; k <= edits; k += 2 {
+			x := frontier[offset+k+1]
+
+			if k != -edits && (k == edits || frontier[offset+k-1] >= frontier[offset+k+1]) {
+				x = frontier[offset+k-1] + 1
+			}
+
+			y := x - k
+
+			for x < n && y < m && from[x] == to[y] {
+				x++
+				y++
+			}
+
+			frontier[offset+k] = x
+
+			if x >= n && y >= m {
+				return backtrack(from, to, trace)
+			}
+		}
+	}
+
+	return nil
+}
+
+// backtrack follows the trace from the end of both sides back to their start
+func backtrack(from []string, to []string, trace [][]int) []operation {
+	operations := make([]operation, 0, len(from)+len(to))
+	x, y := len(from), len(to)
+
+	for edits := len(trace) - 1; edits >= 0; edits-- {
+		// The trace of each step starts at the diagonal -edits-1
+		frontier := func(k int) int {
+			return trace[edits][k+edits+1]
+		}
+
+		k := x - y
+		previousK := k - 1
+
+		if k == -edits || (k != edits && frontier(k-1) < frontier(k+1)) {
+			previousK = k + 1
+		}
+
+		previousX := frontier(previousK)
+		previousY := previousX - previousK
+
+		for x > previousX && y > previousY {
+			x--
+			y--
+			operations = append(operations, operation{equalOperation, from[x]})
+		}
+
+		if edits > 0 {
+			if x == previousX {
+				operations = append(operations, operation{insertOperation, to[previousY]})
+			} else {
+				operations = append(operations, operation{deleteOperation, from[previousX]})
+			}
+		}
+
+		x, y = previousX, previousY
+	}
+
+	for i, j := 0, len(operations)-1; i < j; i, j = i+1, j-1 {
+		operations[i], operations[j] = operations[j], operations[i]
+	}
+
+	return operations
+}
+
+// splitLines keeps the line breaks, a last line without one is then different
+// from the same line with it
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+func minInt(a int, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
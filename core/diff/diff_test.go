@@ -0,0 +1,91 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	lines := "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\nm\nn\no\np\n"
+
+	testCases := []struct {
+		Name     string
+		From     string
+		To       string
+		Expected string
+	}{
+		{
+			"should be empty when both sides are equal",
+			lines,
+			lines,
+			"",
+		},
+		{
+			"should merge changes that share their context into one hunk",
+			lines,
+			strings.Replace(strings.Replace(lines, "d\n", "D\n", 1), "j\n", "J\n", 1),
+			"@@ -1,13 +1,13 @@\n a\n b\n c\n-d\n+D\n e\n f\n g\n h\n i\n-j\n+J\n k\n l\n m\n",
+		},
+		{
+			"should split changes further apart than their context into hunks",
+			lines,
+			strings.Replace(strings.Replace(lines, "b\n", "B\n", 1), "m\n", "M\n", 1),
+			"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n@@ -10,7 +10,7 @@\n j\n k\n l\n-m\n+M\n n\n o\n p\n",
+		},
+		{
+			"should insert every line when the old side is empty",
+			"",
+			"a\nb\n",
+			"@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			"should delete every line when the new side is empty",
+			"a\nb\n",
+			"",
+			"@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		{
+			"should change the last line when only its line break is removed",
+			"a\n",
+			"a",
+			"@@ -1 +1 @@\n-a\n+a\n\\ No newline at end of file\n",
+		},
+		{
+			"should change the last line when only its line break is added",
+			"x\na",
+			"x\na\n",
+			"@@ -1,2 +1,2 @@\n x\n-a\n\\ No newline at end of file\n+a\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			expected := testCase.Expected
+
+			if expected != "" {
+				expected = "--- old\n+++ new\n" + expected
+			}
+
+			if actual := Unified("old", "new", testCase.From, testCase.To); actual != expected {
+				t.Errorf("got\n%s\nexpected\n%s", actual, expected)
+			}
+		})
+	}
+}
+
+func TestUnifiedLargeTexts(t *testing.T) {
+	var builder strings.Builder
+
+	for i := 0; i < 100000; i++ {
+		builder.WriteString(fmt.Sprintf("line %d\n", i))
+	}
+
+	from := builder.String()
+	to := strings.Replace(from, "line 50000\n", "changed\n", 1)
+	expected := "--- old\n+++ new\n@@ -49998,7 +49998,7 @@\n line 49997\n line 49998\n line 49999\n-line 50000\n+changed\n line 50001\n line 50002\n line 50003\n"
+
+	if actual := Unified("old", "new", from, to); actual != expected {
+		t.Errorf("got\n%s\nexpected\n%s", actual, expected)
+	}
+}
@@ -0,0 +1,41 @@
+package parser
+
+// Alias finds the alias declared with the name, it is nil when there's none
+func (t *EskemaTree) Alias(name string) *AliasDefinition {
+	for _, declaration := range t.Declarations {
+		if alias, isAlias := declaration.(*AliasDefinition); isAlias && alias.Name() == name {
+			return alias
+		}
+	}
+
+	return nil
+}
+
+// ResolveType replaces every alias in the type, generics included, with the
+// type it stands for. Languages without aliases emit the resolved type, aliases
+// that refer to themselves are left as they are, the parser reports them
+func (t *EskemaTree) ResolveType(typeExpr *TypeExpression) *TypeExpression {
+	return t.resolveType(typeExpr, make(map[*AliasDefinition]bool))
+}
+
+func (t *EskemaTree) resolveType(typeExpr *TypeExpression, visiting map[*AliasDefinition]bool) *TypeExpression {
+	if alias := t.Alias(typeExpr.Id.Name); alias != nil && !visiting[alias] {
+		visiting[alias] = true
+		defer delete(visiting, alias)
+
+		return t.resolveType(alias.Type, visiting)
+	}
+
+	resolved := &TypeExpression{
+		Id:       typeExpr.Id,
+		Generics: make([]*TypeExpression, 0, len(typeExpr.Generics)),
+		Size:     typeExpr.Size,
+		Span:     typeExpr.Span,
+	}
+
+	for _, generic := range typeExpr.Generics {
+		resolved.Generics = append(resolved.Generics, t.resolveType(generic, visiting))
+	}
+
+	return resolved
+}
@@ -0,0 +1,110 @@
+package parser
+
+// Annotations eskema knows how to check values with
+const (
+	MinAnnotation      = "min"
+	MaxAnnotation      = "max"
+	LengthAnnotation   = "length"
+	PatternAnnotation  = "pattern"
+	NonEmptyAnnotation = "nonEmpty"
+)
+
+var (
+	integerTypes = map[string]bool{
+		"UInt8": true, "UInt16": true, "UInt32": true, "UInt64": true,
+		"Int8": true, "Int16": true, "Int32": true, "Int64": true,
+	}
+	lengthTypes = map[string]bool{"String": true, "Array": true, "Map": true, "Set": true}
+)
+
+// IsInteger tells whether values of the primitive are whole numbers
+func IsInteger(typeName string) bool {
+	return integerTypes[typeName]
+}
+
+// IsNumber tells whether the primitive can be bound by @min and @max
+func IsNumber(typeName string) bool {
+	return integerTypes[typeName] || typeName == "Float" || typeName == "Double"
+}
+
+// HasLength tells whether the primitive can be bound by @length and @nonEmpty
+func HasLength(typeName string) bool {
+	return lengthTypes[typeName]
+}
+
+// Constraints are the checks a value has to pass, bounds are numbers as they
+// were written and are empty when there's no such bound. @nonEmpty is a minimum
+// length of 1
+type Constraints struct {
+	Min       string
+	Max       string
+	MinLength string
+	MaxLength string
+	// Pattern is a regular expression that has to match somewhere in the value
+	Pattern string
+}
+
+func (c Constraints) IsEmpty() bool {
+	return c == Constraints{}
+}
+
+// Constraints of the field, the ones of the aliases its type refers to apply
+// too unless the field overrides them
+func (t *EskemaTree) Constraints(field *FieldExpression) Constraints {
+	return t.constraints(field.Annotations, field.Type)
+}
+
+// AliasConstraints of the alias, including the ones of the aliases it refers to
+func (t *EskemaTree) AliasConstraints(alias *AliasDefinition) Constraints {
+	return t.constraints(alias.Annotations, alias.Type)
+}
+
+func (t *EskemaTree) constraints(annotations []*Annotation, typeExpr *TypeExpression) Constraints {
+	constraints := Constraints{}
+	isNonEmpty := constraints.add(annotations)
+
+	seen := make(map[*AliasDefinition]bool)
+
+	for alias := t.Alias(typeExpr.Id.Name); alias != nil && !seen[alias]; alias = t.Alias(alias.Type.Id.Name) {
+		seen[alias] = true
+		isNonEmpty = constraints.add(alias.Annotations) || isNonEmpty
+	}
+
+	// @nonEmpty only raises the minimum length, a longer one is kept
+	if isNonEmpty && (constraints.MinLength == "" || constraints.MinLength == "0") {
+		constraints.MinLength = "1"
+	}
+
+	return constraints
+}
+
+// add keeps the bounds that are already set and tells whether one of the
+// annotations is @nonEmpty, annotations the parser doesn't know are skipped
+func (c *Constraints) add(annotations []*Annotation) bool {
+	isNonEmpty := false
+	set := func(bound *string, argument *LiteralExpression) {
+		if *bound == "" {
+			*bound = argument.Value
+		}
+	}
+
+	for _, annotation := range annotations {
+		arguments := annotation.Arguments
+
+		switch {
+		case annotation.Id.Name == MinAnnotation && len(arguments) == 1:
+			set(&c.Min, arguments[0])
+		case annotation.Id.Name == MaxAnnotation && len(arguments) == 1:
+			set(&c.Max, arguments[0])
+		case annotation.Id.Name == LengthAnnotation && len(arguments) == 2:
+			set(&c.MinLength, arguments[0])
+			set(&c.MaxLength, arguments[1])
+		case annotation.Id.Name == PatternAnnotation && len(arguments) == 1:
+			set(&c.Pattern, arguments[0])
+		case annotation.Id.Name == NonEmptyAnnotation:
+			isNonEmpty = true
+		}
+	}
+
+	return isNonEmpty
+}
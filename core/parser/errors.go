@@ -1,5 +1,16 @@
 package parser
 
-var (
-	ErrUnexpectedToken = "%v SyntaxError: expected %v, got '%v'"
-)
+import "strings"
+
+// SyntaxErrors groups every error found while parsing a single source
+type SyntaxErrors []error
+
+func (e SyntaxErrors) Error() string {
+	messages := make([]string, 0, len(e))
+
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+
+	return strings.Join(messages, "\n")
+}
@@ -0,0 +1,73 @@
+package parser
+
+// ResolvedField is a field of a schema along with the schema that declares it,
+// which is the schema itself for the fields of its own body
+type ResolvedField struct {
+	Field *FieldExpression
+	Owner *SchemaDefinition
+}
+
+// IsInherited tells whether the field was declared by a parent of the schema
+func (f *ResolvedField) IsInherited(schema *SchemaDefinition) bool {
+	return f.Owner != schema
+}
+
+// Schema finds the schema declared with the name, it is nil when there's none
+func (t *EskemaTree) Schema(name string) *SchemaDefinition {
+	for _, declaration := range t.Declarations {
+		if schema, isSchema := declaration.(*SchemaDefinition); isSchema && schema.Name() == name {
+			return schema
+		}
+	}
+
+	return nil
+}
+
+// ResolveFields flattens the fields the schema inherits, the ones of the schema
+// it extends come first, then the ones of each include and finally its own.
+// A field inherited through more than one path is only listed once and parents
+// that can't be found or close a cycle are skipped, the parser reports them
+func (t *EskemaTree) ResolveFields(schema *SchemaDefinition) []*ResolvedField {
+	return t.resolveFields(schema, make(map[*SchemaDefinition]bool))
+}
+
+// Fields is ResolveFields without the owner of each field
+func (t *EskemaTree) Fields(schema *SchemaDefinition) []*FieldExpression {
+	resolved := t.ResolveFields(schema)
+	fields := make([]*FieldExpression, 0, len(resolved))
+
+	for _, field := range resolved {
+		fields = append(fields, field.Field)
+	}
+
+	return fields
+}
+
+func (t *EskemaTree) resolveFields(schema *SchemaDefinition, visiting map[*SchemaDefinition]bool) []*ResolvedField {
+	visiting[schema] = true
+	defer delete(visiting, schema)
+
+	fields := make([]*ResolvedField, 0, len(schema.Fields))
+	isListed := make(map[*FieldExpression]bool)
+
+	for _, parent := range schema.Parents() {
+		parentSchema := t.Schema(parent.Id.Name)
+
+		if parentSchema == nil || visiting[parentSchema] {
+			continue
+		}
+
+		for _, field := range t.resolveFields(parentSchema, visiting) {
+			if !isListed[field.Field] {
+				isListed[field.Field] = true
+				fields = append(fields, field)
+			}
+		}
+	}
+
+	for _, field := range schema.Fields {
+		fields = append(fields, &ResolvedField{Field: field, Owner: schema})
+	}
+
+	return fields
+}
@@ -1,25 +1,39 @@
 package parser
 
-type EskemaExprType int
+import "github.com/Haato3o/eskema/core/syntax"
 
-const (
-	SchemaExpr EskemaExprType = iota
-	EnumExpr
-)
+// Node is any part of the tree, nodes built by the parser know where they came
+// from while the ones built by importers have an invalid span
+type Node interface {
+	Location() syntax.Span
+	Accept(visitor Visitor) bool
+	children() []Node
+}
 
-type EskemaExpression struct {
-	Type EskemaExprType
-	Data interface{}
+// Declaration is a top level definition, the set of declarations is closed so
+// every visitor is forced to handle all of them
+type Declaration interface {
+	Node
+	Name() string
+	declarationNode()
 }
 
 type IdentifierExpression struct {
 	Name string
+	Span syntax.Span
 }
 
+// SchemaDefinition inherits every field of the schema it extends and of the
+// ones it includes, Fields only has the ones declared in its own body
 type SchemaDefinition struct {
 	Id       IdentifierExpression
 	Fields   []*FieldExpression
 	Generics []*TypeExpression
+	Extends  *TypeExpression
+	Includes []*TypeExpression
+	Comments syntax.Comments
+	Footer   syntax.Comments
+	Span     syntax.Span
 }
 
 func (s *SchemaDefinition) ContainsNullableFields() bool {
@@ -32,22 +46,329 @@ func (s *SchemaDefinition) ContainsNullableFields() bool {
 	return false
 }
 
+// Parents are the schema it extends followed by the ones it includes
+func (s *SchemaDefinition) Parents() []*TypeExpression {
+	if s.Extends == nil {
+		return s.Includes
+	}
+
+	return append([]*TypeExpression{s.Extends}, s.Includes...)
+}
+
+func (s *SchemaDefinition) Name() string {
+	return s.Id.Name
+}
+
+func (s *SchemaDefinition) Location() syntax.Span {
+	return s.Span
+}
+
+func (s *SchemaDefinition) Accept(visitor Visitor) bool {
+	return visitor.VisitSchema(s)
+}
+
+func (s *SchemaDefinition) children() []Node {
+	nodes := make([]Node, 0, len(s.Generics)+len(s.Includes)+len(s.Fields)+1)
+
+	for _, generic := range s.Generics {
+		nodes = append(nodes, generic)
+	}
+
+	if s.Extends != nil {
+		nodes = append(nodes, s.Extends)
+	}
+
+	for _, include := range s.Includes {
+		nodes = append(nodes, include)
+	}
+
+	for _, field := range s.Fields {
+		nodes = append(nodes, field)
+	}
+
+	return nodes
+}
+
+func (s *SchemaDefinition) declarationNode() {}
+
 type FieldExpression struct {
-	Id         IdentifierExpression
-	IsOptional bool
-	Type       *TypeExpression
+	Id          IdentifierExpression
+	IsOptional  bool
+	Type        *TypeExpression
+	Annotations []*Annotation
+	Comments    syntax.Comments
+	Span        syntax.Span
+}
+
+func (f *FieldExpression) Location() syntax.Span {
+	return f.Span
+}
+
+func (f *FieldExpression) Accept(visitor Visitor) bool {
+	return visitor.VisitField(f)
+}
+
+func (f *FieldExpression) children() []Node {
+	nodes := make([]Node, 0, len(f.Annotations)+1)
+	nodes = append(nodes, f.Type)
+
+	for _, annotation := range f.Annotations {
+		nodes = append(nodes, annotation)
+	}
+
+	return nodes
 }
 
 type TypeExpression struct {
 	Id       IdentifierExpression
 	Generics []*TypeExpression
+	// Size is the length of a fixed-size array, it is nil when the array can
+	// have any length
+	Size *LiteralExpression
+	Span syntax.Span
+}
+
+func (t *TypeExpression) Location() syntax.Span {
+	return t.Span
+}
+
+func (t *TypeExpression) Accept(visitor Visitor) bool {
+	return visitor.VisitType(t)
+}
+
+func (t *TypeExpression) children() []Node {
+	nodes := make([]Node, 0, len(t.Generics))
+
+	for _, generic := range t.Generics {
+		nodes = append(nodes, generic)
+	}
+
+	return nodes
 }
 
 type EnumDefinition struct {
-	Id     IdentifierExpression
-	Values []string
+	Id       IdentifierExpression
+	Values   []*EnumValue
+	Comments syntax.Comments
+	Footer   syntax.Comments
+	Span     syntax.Span
+}
+
+func (e *EnumDefinition) Name() string {
+	return e.Id.Name
+}
+
+func (e *EnumDefinition) Location() syntax.Span {
+	return e.Span
+}
+
+func (e *EnumDefinition) Accept(visitor Visitor) bool {
+	return visitor.VisitEnum(e)
+}
+
+func (e *EnumDefinition) children() []Node {
+	nodes := make([]Node, 0, len(e.Values))
+
+	for _, value := range e.Values {
+		nodes = append(nodes, value)
+	}
+
+	return nodes
+}
+
+func (e *EnumDefinition) declarationNode() {}
+
+// ValueKind is the kind of the explicit values of the members, it is false when
+// they are written by their names. The parser makes sure every member has a
+// value of the same kind when one of them has it
+func (e *EnumDefinition) ValueKind() (LiteralKind, bool) {
+	if len(e.Values) == 0 || e.Values[0].Value == nil {
+		return StringLiteral, false
+	}
+
+	return e.Values[0].Value.Kind, true
+}
+
+// EnumValue is written on the wire as its name unless it has an explicit
+// value, which is either a string or an integer for all values of the enum
+type EnumValue struct {
+	Id       IdentifierExpression
+	Value    *LiteralExpression
+	Comments syntax.Comments
+	Span     syntax.Span
+}
+
+func (v *EnumValue) Location() syntax.Span {
+	return v.Span
+}
+
+func (v *EnumValue) Accept(visitor Visitor) bool {
+	return visitor.VisitEnumValue(v)
+}
+
+func (v *EnumValue) children() []Node {
+	return nil
+}
+
+// UnionDefinition is one of its variants, which are schemas told apart by the
+// value of the discriminator field, the name of the variant
+type UnionDefinition struct {
+	Id            IdentifierExpression
+	Discriminator IdentifierExpression
+	Variants      []*UnionVariant
+	Comments      syntax.Comments
+	Footer        syntax.Comments
+	Span          syntax.Span
+}
+
+func (u *UnionDefinition) Name() string {
+	return u.Id.Name
+}
+
+func (u *UnionDefinition) Location() syntax.Span {
+	return u.Span
+}
+
+func (u *UnionDefinition) Accept(visitor Visitor) bool {
+	return visitor.VisitUnion(u)
+}
+
+func (u *UnionDefinition) children() []Node {
+	nodes := make([]Node, 0, len(u.Variants))
+
+	for _, variant := range u.Variants {
+		nodes = append(nodes, variant)
+	}
+
+	return nodes
+}
+
+func (u *UnionDefinition) declarationNode() {}
+
+type UnionVariant struct {
+	Type     *TypeExpression
+	Comments syntax.Comments
+	Span     syntax.Span
+}
+
+func (v *UnionVariant) Name() string {
+	return v.Type.Id.Name
+}
+
+func (v *UnionVariant) Location() syntax.Span {
+	return v.Span
+}
+
+func (v *UnionVariant) Accept(visitor Visitor) bool {
+	return visitor.VisitUnionVariant(v)
+}
+
+func (v *UnionVariant) children() []Node {
+	return []Node{v.Type}
+}
+
+// AliasDefinition names a type, the alias stands for the type along with the
+// constraints of its annotations
+type AliasDefinition struct {
+	Id          IdentifierExpression
+	Type        *TypeExpression
+	Annotations []*Annotation
+	Comments    syntax.Comments
+	Span        syntax.Span
+}
+
+func (a *AliasDefinition) Name() string {
+	return a.Id.Name
+}
+
+func (a *AliasDefinition) Location() syntax.Span {
+	return a.Span
+}
+
+func (a *AliasDefinition) Accept(visitor Visitor) bool {
+	return visitor.VisitAlias(a)
+}
+
+func (a *AliasDefinition) children() []Node {
+	nodes := make([]Node, 0, len(a.Annotations)+1)
+	nodes = append(nodes, a.Type)
+
+	for _, annotation := range a.Annotations {
+		nodes = append(nodes, annotation)
+	}
+
+	return nodes
+}
+
+func (a *AliasDefinition) declarationNode() {}
+
+// ConstDefinition names a value of a primitive type that schemas of different
+// services share
+type ConstDefinition struct {
+	Id       IdentifierExpression
+	Type     *TypeExpression
+	Value    *LiteralExpression
+	Comments syntax.Comments
+	Span     syntax.Span
+}
+
+func (c *ConstDefinition) Name() string {
+	return c.Id.Name
+}
+
+func (c *ConstDefinition) Location() syntax.Span {
+	return c.Span
+}
+
+func (c *ConstDefinition) Accept(visitor Visitor) bool {
+	return visitor.VisitConst(c)
+}
+
+func (c *ConstDefinition) children() []Node {
+	return []Node{c.Type}
+}
+
+func (c *ConstDefinition) declarationNode() {}
+
+// Annotation is written after the type of an alias or a field as @name or
+// @name(arguments)
+type Annotation struct {
+	Id        IdentifierExpression
+	Arguments []*LiteralExpression
+	Span      syntax.Span
+}
+
+func (a *Annotation) Location() syntax.Span {
+	return a.Span
+}
+
+func (a *Annotation) Accept(visitor Visitor) bool {
+	return visitor.VisitAnnotation(a)
+}
+
+func (a *Annotation) children() []Node {
+	return nil
+}
+
+type LiteralKind int
+
+const (
+	StringLiteral LiteralKind = iota
+	NumberLiteral
+	BooleanLiteral
+)
+
+// LiteralExpression is a string, a number or a boolean, Raw is how it was
+// written and Value is the string without its quotes and escapes or the number
+// and boolean as written
+type LiteralExpression struct {
+	Kind  LiteralKind
+	Raw   string
+	Value string
+	Span  syntax.Span
 }
 
 type EskemaTree struct {
-	Expr []*EskemaExpression
+	Declarations []Declaration
+	Comments     []string
 }
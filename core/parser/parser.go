@@ -1,244 +1,784 @@
 package parser
 
 import (
-	"errors"
 	"fmt"
 	"github.com/Haato3o/eskema/core/syntax"
 	"log"
+	"strconv"
 )
 
+// MaxSyntaxErrors is how many errors are reported before the parser gives up
+const MaxSyntaxErrors = 10
+
+// punctuation are the tokens a fix can insert when they're missing
+var punctuation = map[syntax.TokenType]string{
+	syntax.SemiColonToken:   ";",
+	syntax.ScopeStartToken:  "{",
+	syntax.ScopeEndToken:    "}",
+	syntax.CommaToken:       ",",
+	syntax.ColonToken:       ":",
+	syntax.GreaterThanToken: ">",
+}
+
 type EskemaParser struct {
-	stream *syntax.TokenStream
-	errors []error
+	stream      *syntax.TokenStream
+	diagnostics []*syntax.Diagnostic
+	errorCount  int
+
+	// isPanicking is set by the first unexpected token and silences every error
+	// that follows until the parser resynchronizes, so one mistake is one error
+	isPanicking bool
+
+	// scopeStart is the brace that opened the body being parsed, missing closing
+	// braces point back to it
+	scopeStart *syntax.Token
+}
+
+func (p *EskemaParser) notify(diagnostic *syntax.Diagnostic) {
+	if p.isPanicking || p.hasTooManyErrors() {
+		return
+	}
+
+	p.isPanicking = true
+	p.errorCount++
+	p.diagnostics = append(p.diagnostics, diagnostic)
 }
 
-func (p *EskemaParser) notifyError(err error) {
-	p.errors = append(p.errors, err)
+func (p *EskemaParser) hasTooManyErrors() bool {
+	return p.errorCount >= MaxSyntaxErrors
 }
 
 func (p *EskemaParser) Parse() *EskemaTree {
 	ast := &EskemaTree{
-		Expr: make([]*EskemaExpression, 0),
+		Declarations: make([]Declaration, 0),
 	}
 
-	for {
+	for !p.hasTooManyErrors() {
+		position := p.stream.Position()
 		token := p.currentMustBe(syntax.KeywordToken, syntax.EndOfFileToken)
 
-		if token.Type == syntax.KeywordToken {
-			expr := p.parseKeyword()
-			ast.Expr = append(ast.Expr, expr)
-		} else if token.Type == syntax.EndOfFileToken {
+		if token.Type == syntax.EndOfFileToken {
+			ast.Comments = p.stream.CommentsBetween(position, position+1).Leading
 			break
-		} else {
+		}
+
+		if token.Type == syntax.KeywordToken {
+			if declaration := p.parseKeyword(); declaration != nil {
+				ast.Declarations = append(ast.Declarations, declaration)
+			}
+		}
+
+		if p.isPanicking {
+			p.synchronize()
+		}
+
+		// Every declaration consumes at least its keyword, this only guards
+		// against a recovery that stopped right where it started
+		if p.stream.Position() == position {
 			p.stream.Next()
 		}
 	}
 
+	// Declarations that failed to parse would show up as missing parents and
+	// variants
+	if len(p.Errors()) == 0 {
+		p.validateTypes(ast)
+		p.validateAliases(ast)
+		p.validateParents(ast)
+		p.validateUnions(ast)
+		p.validateConstraints(ast)
+		p.validateEnums(ast)
+		p.validateConsts(ast)
+	}
+
 	return ast
 }
 
+// Diagnostics lists everything found in the source, the ones reported by the
+// lexer come first
+func (p *EskemaParser) Diagnostics() []*syntax.Diagnostic {
+	return append(append([]*syntax.Diagnostic{}, p.stream.Diagnostics()...), p.diagnostics...)
+}
+
+// Errors are the diagnostics that prevent the tree from being used
+func (p *EskemaParser) Errors() []error {
+	errs := make([]error, 0)
+
+	for _, diagnostic := range p.Diagnostics() {
+		if diagnostic.Severity == syntax.SeverityError {
+			errs = append(errs, diagnostic)
+		}
+	}
+
+	return errs
+}
+
 func (p *EskemaParser) VerifySyntaxErrors() bool {
 
-	for _, err := range p.errors {
+	for _, err := range p.Errors() {
 		log.Println(err)
 	}
 
-	return len(p.errors) > 0
+	return len(p.Errors()) > 0
 }
 
-func (p *EskemaParser) parseKeyword() *EskemaExpression {
+func (p *EskemaParser) parseKeyword() Declaration {
+	start := p.stream.Position()
 	token := p.stream.Next()
 
 	_, keywordType := syntax.IsKeyword(token.Value)
 
 	switch keywordType {
 	case syntax.EnumKeyword:
-		return p.parseEnum()
+		if enum := p.parseEnum(start); enum != nil {
+			return enum
+		}
 	case syntax.SchemaKeyword:
-		return p.parseSchema()
-	default:
-		return nil
+		if schema := p.parseSchema(start); schema != nil {
+			return schema
+		}
+	case syntax.UnionKeyword:
+		if union := p.parseUnion(start); union != nil {
+			return union
+		}
+	case syntax.AliasKeyword:
+		if alias := p.parseAlias(start); alias != nil {
+			return alias
+		}
+	case syntax.ConstKeyword:
+		if constant := p.parseConst(start); constant != nil {
+			return constant
+		}
 	}
+
+	return nil
 }
 
-func (p *EskemaParser) parseSchema() *EskemaExpression {
+func (p *EskemaParser) parseSchema(start int) *SchemaDefinition {
 	schemaDefinition := &SchemaDefinition{
 		Fields: make([]*FieldExpression, 0),
 	}
-	name := p.nextTokenMustBe(syntax.LiteralToken)
+	name := p.expect(syntax.LiteralToken)
 
-	schemaDefinition.Id.Name = name.Value
+	if p.isPanicking {
+		return nil
+	}
 
-	currentToken := p.stream.PeekCurrent()
+	schemaDefinition.Id = p.identifier(name)
 
-	if currentToken.Type == syntax.LesserThanToken {
+	if p.stream.PeekCurrent().Type == syntax.LesserThanToken {
 		p.stream.Next()
+
 		for {
-			nextToken := p.stream.PeekCurrent()
+			generic := p.expect(syntax.LiteralToken)
 
-			if nextToken.Type != syntax.LiteralToken {
-				break
+			if p.isPanicking {
+				return nil
 			}
 
-			genericExpr := p.parseType()
+			schemaDefinition.Generics = append(schemaDefinition.Generics, &TypeExpression{
+				Id:       p.identifier(generic),
+				Generics: make([]*TypeExpression, 0),
+				Span:     syntax.SpanOf(generic),
+			})
 
-			if genericExpr == nil {
+			if p.stream.PeekCurrent().Type != syntax.CommaToken {
 				break
 			}
 
-			schemaDefinition.Generics = append(schemaDefinition.Generics, genericExpr)
+			p.stream.Next()
+		}
+
+		if p.expect(syntax.GreaterThanToken); p.isPanicking {
+			return nil
 		}
 	}
 
-	currentToken = p.nextTokenMustBe(syntax.ScopeStartToken)
+	if p.isAtModifier(syntax.ExtendsModifier) {
+		p.stream.Next()
 
-	for {
-		currentToken = p.stream.PeekCurrent()
+		if schemaDefinition.Extends = p.parseType(); schemaDefinition.Extends == nil {
+			return nil
+		}
+	}
 
-		if currentToken.Type == syntax.ScopeEndToken {
-			break
+	if p.isAtModifier(syntax.IncludesModifier) {
+		p.stream.Next()
+
+		for {
+			include := p.parseType()
+
+			if include == nil {
+				return nil
+			}
+
+			schemaDefinition.Includes = append(schemaDefinition.Includes, include)
+
+			if p.stream.PeekCurrent().Type != syntax.CommaToken {
+				break
+			}
+
+			p.stream.Next()
 		}
+	}
 
+	if p.scopeStart = p.expect(syntax.ScopeStartToken); p.isPanicking {
+		return nil
+	}
+
+	schemaDefinition.Comments = p.stream.CommentsBetween(start, p.stream.Position())
+
+	for !p.isAtBodyEnd() {
+		fieldStart := p.stream.Position()
 		fieldExpr := p.parseField()
 
 		if fieldExpr == nil {
-			break
+			if !p.synchronizeInBody() {
+				break
+			}
+
+			continue
 		}
 
+		fieldExpr.Comments = p.stream.CommentsBetween(fieldStart, p.stream.Position())
+
 		schemaDefinition.Fields = append(schemaDefinition.Fields, fieldExpr)
+
+		if p.isPanicking && !p.synchronizeInBody() {
+			break
+		}
 	}
 
-	p.nextTokenMustBe(syntax.ScopeEndToken)
-	p.nextTokenMustBe(syntax.SemiColonToken)
+	footerStart := p.stream.Position()
 
-	return &EskemaExpression{
-		Type: SchemaExpr,
-		Data: schemaDefinition,
-	}
+	p.expect(syntax.ScopeEndToken)
+	p.expect(syntax.SemiColonToken)
+
+	schemaDefinition.Footer = p.stream.CommentsBetween(footerStart, p.stream.Position())
+	schemaDefinition.Span = p.spanFrom(start)
+
+	return schemaDefinition
 }
 
 func (p *EskemaParser) parseType() *TypeExpression {
+	start := p.stream.Position()
 	typeExpression := &TypeExpression{
 		Generics: make([]*TypeExpression, 0),
 	}
 
-	name := p.nextTokenMustBe(syntax.LiteralToken, syntax.PrimitiveTypeToken)
+	name := p.expect(syntax.LiteralToken, syntax.PrimitiveTypeToken)
 
-	typeExpression.Id.Name = name.Value
+	if p.isPanicking {
+		return nil
+	}
 
-	currentToken := p.stream.PeekCurrent()
+	typeExpression.Id = p.identifier(name)
+	typeExpression.Span = syntax.SpanOf(name)
 
-	if currentToken.Type == syntax.LesserThanToken {
-		p.stream.Next()
+	if p.stream.PeekCurrent().Type != syntax.LesserThanToken {
+		return typeExpression
+	}
 
-		for {
-			nextToken := p.stream.PeekCurrent()
+	p.stream.Next()
 
-			if nextToken.Type != syntax.LiteralToken &&
-				nextToken.Type != syntax.PrimitiveTypeToken {
-				break
+	for {
+		// A number can only come after the types, it is the size of the array
+		if len(typeExpression.Generics) > 0 && p.stream.PeekCurrent().Type == syntax.NumberToken {
+			if typeExpression.Size = p.parseLiteral(); typeExpression.Size == nil {
+				return nil
 			}
 
-			genericExpr := p.parseType()
+			break
+		}
 
-			if genericExpr == nil {
-				break
-			}
+		genericExpr := p.parseType()
+
+		if genericExpr == nil {
+			return nil
+		}
 
-			typeExpression.Generics = append(typeExpression.Generics, genericExpr)
+		typeExpression.Generics = append(typeExpression.Generics, genericExpr)
 
+		if p.stream.PeekCurrent().Type != syntax.CommaToken {
+			break
 		}
 
-		currentToken = p.stream.PeekCurrent()
+		p.stream.Next()
 	}
 
-	switch currentToken.Type {
-	case syntax.CommaToken,
-		syntax.GreaterThanToken:
-		p.stream.Next()
-		break
+	if p.expect(syntax.GreaterThanToken); p.isPanicking {
+		return nil
 	}
 
+	typeExpression.Span = p.spanFrom(start)
+
 	return typeExpression
 }
 
 func (p *EskemaParser) parseField() *FieldExpression {
+	start := p.stream.Position()
 	fieldExpression := &FieldExpression{}
 
-	name := p.nextTokenMustBe(syntax.LiteralToken)
+	name := p.expect(syntax.LiteralToken)
+
+	if p.isPanicking {
+		return nil
+	}
 
-	fieldExpression.Id.Name = name.Value
+	fieldExpression.Id = p.identifier(name)
 
-	p.nextTokenMustBe(syntax.ColonToken)
+	if p.expect(syntax.ColonToken); p.isPanicking {
+		return nil
+	}
 
 	fieldExpression.Type = p.parseType()
 
-	endToken := p.stream.PeekCurrent()
+	if fieldExpression.Type == nil {
+		return nil
+	}
 
-	if endToken.Type == syntax.QuestionMarkToken {
+	if p.stream.PeekCurrent().Type == syntax.QuestionMarkToken {
 		p.stream.Next()
 
 		fieldExpression.IsOptional = true
-
-		endToken = p.stream.PeekCurrent()
 	}
 
-	if endToken.Type == syntax.CommaToken {
-		p.stream.Next()
+	if fieldExpression.Annotations = p.parseAnnotations(); fieldExpression.Annotations == nil {
+		return nil
 	}
 
+	fieldExpression.Span = p.spanFrom(start)
+
+	p.parseSeparator()
+
 	return fieldExpression
 }
 
-func (p *EskemaParser) parseEnum() *EskemaExpression {
+func (p *EskemaParser) parseEnum(start int) *EnumDefinition {
 	enumDefinition := &EnumDefinition{
-		Values: make([]string, 0),
+		Values: make([]*EnumValue, 0),
 	}
-	name := p.nextTokenMustBe(syntax.LiteralToken)
+	name := p.expect(syntax.LiteralToken)
 
-	enumDefinition.Id.Name = name.Value
+	if p.isPanicking {
+		return nil
+	}
 
-	p.nextTokenMustBe(syntax.ScopeStartToken)
+	enumDefinition.Id = p.identifier(name)
 
-	for {
+	if p.scopeStart = p.expect(syntax.ScopeStartToken); p.isPanicking {
+		return nil
+	}
+
+	enumDefinition.Comments = p.stream.CommentsBetween(start, p.stream.Position())
+
+	for !p.isAtBodyEnd() {
+		valueStart := p.stream.Position()
 		enumValue := p.parseEnumValue()
 
 		if enumValue == nil {
+			if !p.synchronizeInBody() {
+				break
+			}
+
+			continue
+		}
+
+		enumValue.Comments = p.stream.CommentsBetween(valueStart, p.stream.Position())
+		enumDefinition.Values = append(enumDefinition.Values, enumValue)
+
+		if p.isPanicking && !p.synchronizeInBody() {
+			break
+		}
+	}
+
+	footerStart := p.stream.Position()
+
+	p.expect(syntax.ScopeEndToken)
+	p.expect(syntax.SemiColonToken)
+
+	enumDefinition.Footer = p.stream.CommentsBetween(footerStart, p.stream.Position())
+	enumDefinition.Span = p.spanFrom(start)
+
+	return enumDefinition
+}
+
+func (p *EskemaParser) parseEnumValue() *EnumValue {
+	start := p.stream.Position()
+	name := p.expect(syntax.LiteralToken)
+
+	if p.isPanicking {
+		return nil
+	}
+
+	enumValue := &EnumValue{
+		Id:   p.identifier(name),
+		Span: syntax.SpanOf(name),
+	}
+
+	if p.stream.PeekCurrent().Type == syntax.EqualsToken {
+		p.stream.Next()
+
+		if enumValue.Value = p.parseLiteral(); enumValue.Value == nil {
+			return nil
+		}
+
+		enumValue.Span = p.spanFrom(start)
+	}
+
+	p.parseSeparator()
+
+	return enumValue
+}
+
+func (p *EskemaParser) parseUnion(start int) *UnionDefinition {
+	unionDefinition := &UnionDefinition{
+		Variants: make([]*UnionVariant, 0),
+	}
+	name := p.expect(syntax.LiteralToken)
+
+	if p.isPanicking {
+		return nil
+	}
+
+	unionDefinition.Id = p.identifier(name)
+
+	if p.expect(syntax.ColonToken); p.isPanicking {
+		return nil
+	}
+
+	discriminator := p.expect(syntax.LiteralToken)
+
+	if p.isPanicking {
+		return nil
+	}
+
+	unionDefinition.Discriminator = p.identifier(discriminator)
+
+	if p.scopeStart = p.expect(syntax.ScopeStartToken); p.isPanicking {
+		return nil
+	}
+
+	unionDefinition.Comments = p.stream.CommentsBetween(start, p.stream.Position())
+
+	for !p.isAtBodyEnd() {
+		variantStart := p.stream.Position()
+		variant := p.parseUnionVariant()
+
+		if variant == nil {
+			if !p.synchronizeInBody() {
+				break
+			}
+
+			continue
+		}
+
+		variant.Comments = p.stream.CommentsBetween(variantStart, p.stream.Position())
+		unionDefinition.Variants = append(unionDefinition.Variants, variant)
+
+		if p.isPanicking && !p.synchronizeInBody() {
 			break
 		}
+	}
+
+	footerStart := p.stream.Position()
+
+	p.expect(syntax.ScopeEndToken)
+	p.expect(syntax.SemiColonToken)
+
+	unionDefinition.Footer = p.stream.CommentsBetween(footerStart, p.stream.Position())
+	unionDefinition.Span = p.spanFrom(start)
+
+	return unionDefinition
+}
+
+func (p *EskemaParser) parseUnionVariant() *UnionVariant {
+	variantType := p.parseType()
+
+	if variantType == nil {
+		return nil
+	}
+
+	p.parseSeparator()
+
+	return &UnionVariant{
+		Type: variantType,
+		Span: variantType.Span,
+	}
+}
+
+func (p *EskemaParser) parseAlias(start int) *AliasDefinition {
+	aliasDefinition := &AliasDefinition{
+		Annotations: make([]*Annotation, 0),
+	}
+	name := p.expect(syntax.LiteralToken)
+
+	if p.isPanicking {
+		return nil
+	}
+
+	aliasDefinition.Id = p.identifier(name)
+
+	if p.expect(syntax.EqualsToken); p.isPanicking {
+		return nil
+	}
+
+	if aliasDefinition.Type = p.parseType(); aliasDefinition.Type == nil {
+		return nil
+	}
+
+	if aliasDefinition.Annotations = p.parseAnnotations(); aliasDefinition.Annotations == nil {
+		return nil
+	}
+
+	if p.expect(syntax.SemiColonToken); p.isPanicking {
+		return nil
+	}
 
-		enumDefinition.Values = append(enumDefinition.Values, enumValue.Value)
+	aliasDefinition.Comments = p.stream.CommentsBetween(start, p.stream.Position())
+	aliasDefinition.Span = p.spanFrom(start)
+
+	return aliasDefinition
+}
+
+func (p *EskemaParser) parseConst(start int) *ConstDefinition {
+	constDefinition := &ConstDefinition{}
+	name := p.expect(syntax.LiteralToken)
+
+	if p.isPanicking {
+		return nil
+	}
+
+	constDefinition.Id = p.identifier(name)
+
+	if p.expect(syntax.ColonToken); p.isPanicking {
+		return nil
+	}
+
+	if constDefinition.Type = p.parseType(); constDefinition.Type == nil {
+		return nil
+	}
+
+	if p.expect(syntax.EqualsToken); p.isPanicking {
+		return nil
+	}
+
+	if constDefinition.Value = p.parseLiteral(); constDefinition.Value == nil {
+		return nil
+	}
+
+	if p.expect(syntax.SemiColonToken); p.isPanicking {
+		return nil
 	}
 
-	p.nextTokenMustBe(syntax.ScopeEndToken)
-	p.nextTokenMustBe(syntax.SemiColonToken)
+	constDefinition.Comments = p.stream.CommentsBetween(start, p.stream.Position())
+	constDefinition.Span = p.spanFrom(start)
+
+	return constDefinition
+}
+
+// parseAnnotations reads every annotation following a type, it returns nil
+// when one of them is broken
+func (p *EskemaParser) parseAnnotations() []*Annotation {
+	annotations := make([]*Annotation, 0)
+
+	for p.stream.PeekCurrent().Type == syntax.AtToken {
+		annotation := p.parseAnnotation()
 
-	return &EskemaExpression{
-		Type: EnumExpr,
-		Data: enumDefinition,
+		if annotation == nil {
+			return nil
+		}
+
+		annotations = append(annotations, annotation)
 	}
+
+	return annotations
 }
 
-func (p *EskemaParser) parseEnumValue() *syntax.Token {
-	next := p.stream.Peek()
-	current := p.stream.Next()
+func (p *EskemaParser) parseAnnotation() *Annotation {
+	start := p.stream.Position()
+	p.stream.Next()
 
-	if current.Type != syntax.LiteralToken {
-		p.stream.Prev()
+	name := p.expect(syntax.LiteralToken)
+
+	if p.isPanicking {
 		return nil
 	}
 
-	if next.Type == syntax.CommaToken {
+	annotation := &Annotation{
+		Id:        p.identifier(name),
+		Arguments: make([]*LiteralExpression, 0),
+	}
+
+	if p.stream.PeekCurrent().Type == syntax.ParenStartToken {
 		p.stream.Next()
+
+		for p.stream.PeekCurrent().Type != syntax.ParenEndToken {
+			argument := p.parseLiteral()
+
+			if argument == nil {
+				return nil
+			}
+
+			annotation.Arguments = append(annotation.Arguments, argument)
+
+			if p.stream.PeekCurrent().Type != syntax.CommaToken {
+				break
+			}
+
+			p.stream.Next()
+		}
+
+		if p.expect(syntax.ParenEndToken); p.isPanicking {
+			return nil
+		}
 	}
 
-	return current
+	annotation.Span = p.spanFrom(start)
+
+	return annotation
 }
 
-func (p *EskemaParser) nextTokenMustBe(expectedTypes ...syntax.TokenType) *syntax.Token {
-	token := p.stream.Next()
+func (p *EskemaParser) parseLiteral() *LiteralExpression {
+	token := p.expect(syntax.StringToken, syntax.NumberToken, syntax.BooleanToken)
 
-	return p.verifyTokenMatch(token, expectedTypes...)
+	if p.isPanicking {
+		return nil
+	}
+
+	literal := &LiteralExpression{
+		Kind:  NumberLiteral,
+		Raw:   token.Value,
+		Value: token.Value,
+		Span:  syntax.SpanOf(token),
+	}
+
+	if token.Type == syntax.BooleanToken {
+		literal.Kind = BooleanLiteral
+	}
+
+	if token.Type == syntax.StringToken {
+		literal.Kind = StringLiteral
+
+		value, err := strconv.Unquote(token.Value)
+
+		if err != nil {
+			p.notify(&syntax.Diagnostic{
+				Severity: syntax.SeverityError,
+				Code:     syntax.CodeInvalidString,
+				Message:  fmt.Sprintf("invalid escape in %s", token.Value),
+				Span:     literal.Span,
+			})
+
+			return nil
+		}
+
+		literal.Value = value
+	}
+
+	return literal
+}
+
+func (p *EskemaParser) isAtModifier(modifier string) bool {
+	token := p.stream.PeekCurrent()
+
+	return token.Type == syntax.LiteralToken && token.Value == modifier
+}
+
+func (p *EskemaParser) identifier(token *syntax.Token) IdentifierExpression {
+	return IdentifierExpression{
+		Name: token.Value,
+		Span: syntax.SpanOf(token),
+	}
+}
+
+// spanFrom covers the tokens from start up to the last one consumed
+func (p *EskemaParser) spanFrom(start int) syntax.Span {
+	return syntax.SpanBetween(p.stream.PeekAt(start), p.stream.PeekAt(p.stream.Position()-1))
+}
+
+// parseSeparator consumes the comma between members, the last member of a body
+// is the only one that can go without it. A missing comma right before another
+// member is reported without dropping either of them
+func (p *EskemaParser) parseSeparator() {
+	switch p.stream.PeekCurrent().Type {
+	case syntax.CommaToken:
+		p.stream.Next()
+	case syntax.ScopeEndToken:
+		return
+	default:
+		p.currentMustBe(syntax.CommaToken, syntax.ScopeEndToken)
+
+		if p.stream.PeekCurrent().Type == syntax.LiteralToken {
+			p.isPanicking = false
+		}
+	}
+}
+
+// isAtBodyEnd tells whether the members of a declaration are over, a keyword
+// inside of a body means its closing brace is missing
+func (p *EskemaParser) isAtBodyEnd() bool {
+	switch p.stream.PeekCurrent().Type {
+	case syntax.ScopeEndToken,
+		syntax.KeywordToken,
+		syntax.EndOfFileToken:
+		return true
+	default:
+		return p.hasTooManyErrors()
+	}
+}
+
+// synchronizeInBody skips the broken member up to the next one, it returns false
+// when the body itself can't be recovered and the declaration must be abandoned.
+// Semicolons are skipped too since they never belong inside of a body
+func (p *EskemaParser) synchronizeInBody() bool {
+	for {
+		switch p.stream.PeekCurrent().Type {
+		case syntax.CommaToken:
+			p.stream.Next()
+			p.isPanicking = false
+			return true
+		case syntax.ScopeEndToken:
+			p.isPanicking = false
+			return true
+		case syntax.KeywordToken,
+			syntax.EndOfFileToken:
+			return false
+		default:
+			p.stream.Next()
+		}
+	}
+}
+
+// synchronize skips tokens until the start of the next declaration, a semicolon
+// ends the broken declaration and is consumed along with it
+func (p *EskemaParser) synchronize() {
+	for {
+		switch p.stream.PeekCurrent().Type {
+		case syntax.SemiColonToken:
+			p.stream.Next()
+			p.isPanicking = false
+			return
+		case syntax.KeywordToken,
+			syntax.EndOfFileToken:
+			p.isPanicking = false
+			return
+		default:
+			p.stream.Next()
+		}
+	}
+}
+
+// expect consumes the current token when it matches, otherwise it is left for
+// the recovery to decide whether it starts something the parser can resume from
+func (p *EskemaParser) expect(expectedTypes ...syntax.TokenType) *syntax.Token {
+	token := p.currentMustBe(expectedTypes...)
+
+	if !p.isPanicking {
+		p.stream.Next()
+	}
+
+	return token
 }
 
 func (p *EskemaParser) currentMustBe(expectedTypes ...syntax.TokenType) *syntax.Token {
@@ -255,23 +795,57 @@ func (p *EskemaParser) verifyTokenMatch(currentToken *syntax.Token, expectedType
 		}
 	}
 
-	p.notifyError(
-		errors.New(
-			fmt.Sprintf(
-				ErrUnexpectedToken,
-				currentToken.Metadata,
-				syntax.ToTokenTypeNiceName(expectedTypes...),
-				currentToken.Value,
-			),
-		),
-	)
+	got := currentToken.Value
+
+	if currentToken.Type == syntax.EndOfFileToken {
+		got = "EOF"
+	}
+
+	diagnostic := &syntax.Diagnostic{
+		Severity: syntax.SeverityError,
+		Code:     syntax.CodeUnexpectedToken,
+		Message:  fmt.Sprintf("expected %s, got '%s'", syntax.ToTokenTypeNiceName(expectedTypes...), got),
+		Span:     syntax.SpanOf(currentToken),
+	}
+
+	missingType := expectedTypes[0]
+
+	// Running into a keyword or the end of the file means a body was never closed
+	if isExpected(syntax.ScopeEndToken, expectedTypes) && p.scopeStart != nil {
+		span := syntax.SpanOf(p.scopeStart)
+		diagnostic.Notes = append(diagnostic.Notes, &syntax.Note{Message: "body starts here", Span: &span})
+
+		if currentToken.Type == syntax.KeywordToken || currentToken.Type == syntax.EndOfFileToken {
+			missingType = syntax.ScopeEndToken
+		}
+	}
+
+	if missing, isPunctuation := punctuation[missingType]; isPunctuation && p.stream.Position() > 0 {
+		diagnostic.Fix = &syntax.Fix{
+			Message:     fmt.Sprintf("insert '%s'", missing),
+			Span:        syntax.SpanAfter(p.stream.PeekAt(p.stream.Position() - 1)),
+			Replacement: missing,
+		}
+	}
+
+	p.notify(diagnostic)
 
 	return currentToken
 }
 
+func isExpected(tokenType syntax.TokenType, expectedTypes []syntax.TokenType) bool {
+	for _, expectedType := range expectedTypes {
+		if expectedType == tokenType {
+			return true
+		}
+	}
+
+	return false
+}
+
 func New(stream *syntax.TokenStream) *EskemaParser {
 	return &EskemaParser{
-		stream: stream,
-		errors: make([]error, 0),
+		stream:      stream,
+		diagnostics: make([]*syntax.Diagnostic, 0),
 	}
 }
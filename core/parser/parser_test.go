@@ -0,0 +1,418 @@
+package parser
+
+import (
+	"github.com/Haato3o/eskema/core/syntax"
+	"os"
+	"strings"
+	"testing"
+)
+
+func parse(source string) (*EskemaTree, []error) {
+	eskemaParser := New(syntax.NewLexer([]byte(source), "test.skm").Lex())
+	tree := eskemaParser.Parse()
+
+	return tree, eskemaParser.Errors()
+}
+
+func TestParseRecovery(t *testing.T) {
+	testCases := []struct {
+		Name         string
+		Input        string
+		Declarations int
+		Expected     []string
+	}{
+		{
+			"should parse valid input without errors",
+			"schema A<T, U> { a: Map<T, Array<U>>?, b: Int32, };\nenum E { X, Y };",
+			2,
+			[]string{},
+		},
+		{
+			"should report a missing semicolon once",
+			"schema A { a: String }\nschema B { b: Int32 };",
+			2,
+			[]string{
+				"test.skm [2:1] error[E0002]: expected ';', got 'schema'",
+			},
+		},
+		{
+			"should report a missing semicolon at the end of the file",
+			"schema A { a: String }",
+			1,
+			[]string{
+				"test.skm [1:23] error[E0002]: expected ';', got 'EOF'",
+			},
+		},
+		{
+			"should resume at the next declaration when a brace is missing",
+			"schema A { a: String\nschema B { b: Int32 };",
+			2,
+			[]string{
+				"test.skm [2:1] error[E0002]: expected ',' or '}', got 'schema'",
+			},
+		},
+		{
+			"should terminate when the file ends inside of a body",
+			"schema A {",
+			1,
+			[]string{
+				"test.skm [1:11] error[E0002]: expected '}', got 'EOF'",
+			},
+		},
+		{
+			"should keep parsing fields after a missing colon",
+			"schema A { a String, b: Int32, c Int32 };",
+			1,
+			[]string{
+				"test.skm [1:14] error[E0002]: expected ':', got 'String'",
+				"test.skm [1:34] error[E0002]: expected ':', got 'Int32'",
+			},
+		},
+		{
+			"should keep both fields when a comma is missing",
+			"schema A { a: String b: Int32 };",
+			1,
+			[]string{
+				"test.skm [1:22] error[E0002]: expected ',' or '}', got 'b'",
+			},
+		},
+		{
+			"should report an unclosed generic once",
+			"schema A { a: Array<String, b: Int32 };",
+			1,
+			[]string{
+				"test.skm [1:30] error[E0002]: expected '>', got ':'",
+			},
+		},
+		{
+			"should report a missing generic argument",
+			"schema A { a: Map<String, >, b: Int32? };",
+			1,
+			[]string{
+				"test.skm [1:27] error[E0002]: expected 'Literal' or 'Primitive', got '>'",
+			},
+		},
+		{
+			"should skip a stray semicolon inside of a body",
+			"schema A { a: ; b: Int32 };\nenum E { X };",
+			2,
+			[]string{
+				"test.skm [1:15] error[E0002]: expected 'Literal' or 'Primitive', got ';'",
+			},
+		},
+		{
+			"should abandon a body that runs into the next declaration",
+			"schema A { a: String;\nschema B { b: Int32 };",
+			2,
+			[]string{
+				"test.skm [1:21] error[E0002]: expected ',' or '}', got ';'",
+			},
+		},
+		{
+			"should drop a declaration without a name and resume at the next one",
+			"schema { a: String };\nenum E { X, Y };",
+			1,
+			[]string{
+				"test.skm [1:8] error[E0002]: expected 'Literal', got '{'",
+			},
+		},
+		{
+			"should drop a schema with unclosed generics",
+			"schema A<T { a: T };\nschema B { b: Int32 };",
+			1,
+			[]string{
+				"test.skm [1:12] error[E0002]: expected '>', got '{'",
+			},
+		},
+		{
+			"should report a missing comma between enum values",
+			"enum E { X Y };",
+			1,
+			[]string{
+				"test.skm [1:12] error[E0002]: expected ',' or '}', got 'Y'",
+			},
+		},
+		{
+			"should skip stray tokens between declarations",
+			"} ; schema A { a: String }; }}}}",
+			1,
+			[]string{
+				"test.skm [1:1] error[E0002]: expected 'Keyword' or 'EOF', got '}'",
+				"test.skm [1:29] error[E0002]: expected 'Keyword' or 'EOF', got '}'",
+			},
+		},
+		{
+			"should parse unions",
+			"schema A { a: String };\nschema B { };\nunion U: type {\n    A,\n    B\n};",
+			3,
+			[]string{},
+		},
+		{
+			"should report a union without a discriminator",
+			"schema A { };\nunion U { A };",
+			1,
+			[]string{
+				"test.skm [2:9] error[E0002]: expected ':', got '{'",
+			},
+		},
+		{
+			"should report variants that aren't schemas the union can tell apart",
+			"enum E { X };\nschema A<T> { };\nschema B { type: String };\nschema C { };\nunion U: type { E, A, B, Missing, C, C };\nunion V: kind { C };",
+			6,
+			[]string{
+				"test.skm [5:17] error[E0003]: variant 'E' of union 'U' must be a schema declared in this file",
+				"test.skm [5:20] error[E0003]: variant 'A' of union 'U' can't be generic",
+				"test.skm [5:23] error[E0003]: 'B' has a field named 'type', which is the discriminator of union 'U'",
+				"test.skm [5:26] error[E0003]: variant 'Missing' of union 'U' must be a schema declared in this file",
+				"test.skm [5:38] error[E0003]: 'C' is listed more than once in union 'U'",
+				"test.skm [6:17] error[E0003]: 'C' is already a variant of union 'U'",
+			},
+		},
+		{
+			"should parse aliases with annotations",
+			"alias UserId = Int64;\nalias Email = String @pattern(\"^[^@]+@[^@]+$\") @length(3, 254);\nalias Emails = Array<Email> @nonEmpty;\nschema User { id: UserId, emails: Emails };",
+			4,
+			[]string{},
+		},
+		{
+			"should report aliases that refer to themselves",
+			"alias A = B;\nalias B = Array<A>;\nalias C = Map<String, C>;\nalias D = A;",
+			4,
+			[]string{
+				"test.skm [1:11] error[E0006]: alias 'A' refers to itself through 'B'",
+				"test.skm [2:11] error[E0006]: alias 'B' refers to itself through 'A'",
+				"test.skm [3:11] error[E0006]: alias 'C' refers to itself through 'C'",
+			},
+		},
+		{
+			"should report broken annotations",
+			"alias A = String @length(3 4);\nalias B = String @pattern(\"\\q\");\nalias C = Int32 @;",
+			0,
+			[]string{
+				"test.skm [1:28] error[E0002]: expected ')', got '4'",
+				"test.skm [2:27] error[E0005]: invalid escape in \"\\q\"",
+				"test.skm [3:18] error[E0002]: expected 'Literal', got ';'",
+			},
+		},
+		{
+			"should parse enums with explicit values",
+			"enum Code { OK = 200, NOT_FOUND = 404 };\nenum Status { ONLINE = \"online\", OFFLINE = \"offline\" };",
+			2,
+			[]string{},
+		},
+		{
+			"should report enum values that can't tell members apart",
+			"enum A { X = 1, Y = 01, X = 2 };\nenum B { X = 1, Y = \"y\", Z = 1.5 };\nenum C { X, Y = 2 };",
+			3,
+			[]string{
+				"test.skm [1:17] error[E0008]: 'Y' has the same value as 'X'",
+				"test.skm [1:25] error[E0008]: 'X' is declared more than once in enum 'A'",
+				"test.skm [2:17] error[E0008]: enum 'B' can't mix string and integer values",
+				"test.skm [2:26] error[E0008]: value of 'Z' must be an integer or a string",
+				"test.skm [3:13] error[E0008]: every value of enum 'C' needs an explicit value when one of them has it",
+			},
+		},
+		{
+			"should parse sets, tuples and fixed-size arrays",
+			"alias Hash = Array<UInt8, 16>;\nschema A { tags: Set<String> @nonEmpty, at: Tuple<Double, Double, Double>, hash: Hash };",
+			2,
+			[]string{},
+		},
+		{
+			"should report types with the wrong type arguments",
+			"schema G<T> { };\nschema A {\n  a: Array<String, String>,\n  b: Tuple<Int32>,\n  c: Map<String, Int32, 4>,\n  d: Array<Int8, 0>,\n  e: String<Int32>,\n  f: G,\n  g: Set<G<Int32, Int32>>\n};",
+			2,
+			[]string{
+				"test.skm [3:6] error[E0009]: 'Array' expects 1 type argument, got 2",
+				"test.skm [4:6] error[E0009]: 'Tuple' expects 2 or 3 type arguments, got 1",
+				"test.skm [5:25] error[E0009]: 'Map' can't have a size, only 'Array' can",
+				"test.skm [6:18] error[E0009]: size of 'Array' must be a positive integer, got 0",
+				"test.skm [7:6] error[E0009]: 'String' takes no type arguments",
+				"test.skm [8:6] error[E0009]: 'G' expects 1 type argument, got 0",
+				"test.skm [9:10] error[E0009]: 'G' expects 1 type argument, got 2",
+			},
+		},
+		{
+			"should report map keys and set elements that aren't comparable",
+			"alias Hash = Bytes;\nschema A {\n  a: Set<Bytes>,\n  b: Map<Hash, Int32>,\n  c: Set<Tuple<String, Array<Int8>>>,\n  d: Map<Set<String>, Int32>,\n  e: Map<Tuple<String, Int32>, Bytes>,\n  f: Map<Inner, Int32>,\n  g: Set<Point>\n};\nschema Inner { tags: Array<String> };\nschema Point { x: Int32, y: Int32 };",
+			4,
+			[]string{
+				"test.skm [3:10] error[E0009]: elements of a 'Set' can't be of type 'Bytes', it isn't comparable",
+				"test.skm [4:10] error[E0009]: keys of a 'Map' can't be of type 'Bytes', it isn't comparable",
+				"test.skm [5:10] error[E0009]: elements of a 'Set' can't be of type 'Array', it isn't comparable",
+				"test.skm [6:10] error[E0009]: keys of a 'Map' can't be of type 'Set', it isn't comparable",
+				"test.skm [8:10] error[E0009]: keys of a 'Map' can't be of type 'Inner', it isn't comparable",
+			},
+		},
+		{
+			"should parse constants",
+			"const MAX_PAGE_SIZE: Int32 = 100;\nconst TOPIC: String = \"orders\";\nconst ENABLED: Bool = false;\nconst RATIO: Double = 0.5;",
+			4,
+			[]string{},
+		},
+		{
+			"should report constants with values of another type",
+			"alias Port = UInt16 @min(1024);\nalias Code = String @length(2, 2) @pattern(\"^[A-Z]+$\");\nconst A: Int8 = 128;\nconst B: Bool = 1;\nconst C: String = true;\nconst D: Double = \"1\";\nconst E: Array<String> = \"x\";\nconst F: Port = 80;\nconst G: Code = \"BRL\";\nconst H: Code = \"br\";\nconst I: UInt8 = -1;",
+			11,
+			[]string{
+				"test.skm [3:17] error[E0010]: value of 'A' must be an integer that fits in 'Int8'",
+				"test.skm [4:17] error[E0010]: value of 'B' must be true or false",
+				"test.skm [5:19] error[E0010]: value of 'C' must be a string",
+				"test.skm [6:19] error[E0010]: value of 'D' must be a number",
+				"test.skm [7:26] error[E0010]: constant 'E' can't be of type 'Array', only of numbers, strings and booleans",
+				"test.skm [8:17] error[E0010]: value of 'F' must be at least 1024",
+				"test.skm [9:17] error[E0010]: value of 'G' must have a length of at most 2",
+				"test.skm [10:17] error[E0010]: value of 'H' must match its pattern",
+				"test.skm [11:18] error[E0010]: value of 'I' must be an integer that fits in 'UInt8'",
+			},
+		},
+		{
+			"should parse fields with constraints",
+			"schema User { age: Int32? @min(0) @max(150), name: String @length(1, 64) @pattern(\"^\\\\w+$\"), tags: Array<String> @nonEmpty };",
+			1,
+			[]string{},
+		},
+		{
+			"should report constraints that can't apply to their values",
+			"alias Name = String @length(1, 64);\nschema A {\n  a: Bool @min(1),\n  b: Int32 @min(1.5) @max(\"2\"),\n  c: Name @length(-1, 2) @nonEmpty(1),\n  d: String @pattern(\"(\") @unique,\n  e: Double @min(1) @min(2),\n  f: Int64 @min(10) @max(1),\n  g: String @length(65, 64)\n};",
+			2,
+			[]string{
+				"test.skm [3:11] error[E0007]: '@min' can't be used on 'Bool', only on numbers",
+				"test.skm [4:12] error[E0007]: '@min' of 'Int32' expects an integer",
+				"test.skm [4:22] error[E0007]: '@max' expects a number",
+				"test.skm [5:11] error[E0007]: '@length' expects a minimum and a maximum length",
+				"test.skm [5:26] error[E0007]: '@nonEmpty' takes no arguments",
+				"test.skm [6:13] error[E0007]: '@pattern' expects a regular expression: error parsing regexp: missing closing ): `(`",
+				"test.skm [6:27] error[E0007]: unknown annotation '@unique', expected @min, @max, @length, @pattern or @nonEmpty",
+				"test.skm [7:21] error[E0007]: '@min' is used more than once",
+				"test.skm [8:3] error[E0007]: minimum 10 is greater than maximum 1",
+				"test.skm [9:3] error[E0007]: minimum length 65 is greater than maximum length 64",
+			},
+		},
+		{
+			"should parse schemas that extend and include others",
+			"schema Auditable { id: Int64 };\nschema Timestamps { createdAt: DateTime };\nschema Order extends Auditable includes Timestamps, Owned { includes: Array<String> };\nschema Owned { owner: String };",
+			4,
+			[]string{},
+		},
+		{
+			"should report parents that can't be inherited",
+			"enum E { X };\nschema G<T> { };\nschema A extends B { };\nschema B extends A { };\nschema C extends E includes G, D, D { };\nschema D { };",
+			6,
+			[]string{
+				"test.skm [3:18] error[E0004]: 'A' inherits from itself through 'B'",
+				"test.skm [4:18] error[E0004]: 'B' inherits from itself through 'A'",
+				"test.skm [5:18] error[E0004]: 'E' must be a schema declared in this file to be inherited by 'C'",
+				"test.skm [5:29] error[E0004]: 'C' can't inherit from generic schema 'G'",
+				"test.skm [5:35] error[E0004]: 'D' is inherited more than once by 'C'",
+			},
+		},
+		{
+			"should report fields declared by more than one schema",
+			"schema A { id: Int64 };\nschema B extends A { };\nschema C extends A { };\nschema D extends B includes C { };\nschema E { id: String };\nschema F extends A includes E { id: Int64 };\nschema U extends A { };\nunion V: id { U };",
+			8,
+			[]string{
+				"test.skm [6:29] error[E0004]: 'F' inherits field 'id' from both 'A' and 'E'",
+				"test.skm [6:33] error[E0004]: field 'id' of 'F' is already inherited from 'A'",
+				"test.skm [8:15] error[E0003]: 'U' has a field named 'id', which is the discriminator of union 'V'",
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			tree, errs := parse(testCase.Input)
+
+			if len(tree.Declarations) != testCase.Declarations {
+				t.Errorf("got %d declarations, expected %d", len(tree.Declarations), testCase.Declarations)
+			}
+
+			actual := make([]string, 0, len(errs))
+
+			for _, err := range errs {
+				actual = append(actual, err.Error())
+			}
+
+			if strings.Join(actual, "\n") != strings.Join(testCase.Expected, "\n") {
+				t.Errorf("got\n%s\nexpected\n%s", strings.Join(actual, "\n"), strings.Join(testCase.Expected, "\n"))
+			}
+		})
+	}
+}
+
+func TestResolveFields(t *testing.T) {
+	tree, _ := parse("schema A { id: Int64 };\nschema B extends A { name: String };\nschema C { at: DateTime };\nschema D extends B includes C, A { total: Double };")
+	schema := tree.Schema("D")
+	expected := []string{"id A", "name B", "at C", "total D"}
+	actual := make([]string, 0, len(expected))
+
+	for _, field := range tree.ResolveFields(schema) {
+		actual = append(actual, field.Field.Id.Name+" "+field.Owner.Name())
+	}
+
+	if strings.Join(actual, ", ") != strings.Join(expected, ", ") {
+		t.Errorf("got %v, expected %v", actual, expected)
+	}
+}
+
+func TestResolveType(t *testing.T) {
+	tree, _ := parse("alias Id = Int64;\nalias Ids = Array<Id>;\nschema A { ids: Map<String, Ids> };")
+	resolved := tree.ResolveType(tree.Schema("A").Fields[0].Type)
+
+	if actual := typeString(resolved); actual != "Map<String, Array<Int64>>" {
+		t.Errorf("got %s, expected every alias to be replaced", actual)
+	}
+}
+
+func typeString(typeExpr *TypeExpression) string {
+	if len(typeExpr.Generics) == 0 {
+		return typeExpr.Id.Name
+	}
+
+	generics := make([]string, 0, len(typeExpr.Generics))
+
+	for _, generic := range typeExpr.Generics {
+		generics = append(generics, typeString(generic))
+	}
+
+	return typeExpr.Id.Name + "<" + strings.Join(generics, ", ") + ">"
+}
+
+func TestConstraints(t *testing.T) {
+	tree, _ := parse("alias Name = String @length(1, 64) @pattern(\"^\\\\w+$\");\nalias Nick = Name @length(2, 16);\nschema A { a: Nick @nonEmpty, b: Array<Name> @nonEmpty, c: Int32 @min(-1) };")
+	fields := tree.Schema("A").Fields
+	expected := []Constraints{
+		{MinLength: "2", MaxLength: "16", Pattern: "^\\w+$"},
+		{MinLength: "1"},
+		{Min: "-1"},
+	}
+
+	for i, field := range fields {
+		if actual := tree.Constraints(field); actual != expected[i] {
+			t.Errorf("got %+v for %s, expected %+v", actual, field.Id.Name, expected[i])
+		}
+	}
+}
+
+func TestParseCapsErrors(t *testing.T) {
+	source := "schema A {\n" + strings.Repeat("    a String,\n", 3*MaxSyntaxErrors) + "};"
+
+	if _, errs := parse(source); len(errs) != MaxSyntaxErrors {
+		t.Errorf("got %d errors, expected %d", len(errs), MaxSyntaxErrors)
+	}
+}
+
+// TestParseTerminates parses every prefix of the example and every variant of it
+// missing a single character, none of which should hang the parser
+func TestParseTerminates(t *testing.T) {
+	source, err := os.ReadFile("../../examples/example.skm")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range source {
+		parse(string(source[:i]))
+		parse(string(source[:i]) + string(source[i+1:]))
+	}
+}
@@ -0,0 +1,615 @@
+package parser
+
+import (
+	"fmt"
+	"github.com/Haato3o/eskema/core/syntax"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+)
+
+// typeArities are the least and the most type arguments generic primitives take
+var typeArities = map[string][2]int{
+	"Array": {1, 1},
+	"Set":   {1, 1},
+	"Map":   {2, 2},
+	"Tuple": {2, 3},
+}
+
+// uncomparableTypes can't be map keys or set elements, most languages hash them
+// by reference or, like Go, refuse to compile them at all
+var uncomparableTypes = map[string]bool{
+	"Bytes": true,
+	"Array": true,
+	"Map":   true,
+	"Set":   true,
+}
+
+// validateTypes checks that generic primitives and schemas get as many type
+// arguments as they take, that only arrays have a size and that map keys and set
+// elements can be compared
+func (p *EskemaParser) validateTypes(tree *EskemaTree) {
+	for _, declaration := range tree.Declarations {
+		switch node := declaration.(type) {
+		case *AliasDefinition:
+			p.validateType(tree, node.Type)
+		case *SchemaDefinition:
+			for _, field := range node.Fields {
+				p.validateType(tree, field.Type)
+			}
+		}
+	}
+}
+
+func (p *EskemaParser) validateType(tree *EskemaTree, typeExpr *TypeExpression) {
+	name := typeExpr.Id.Name
+	count := len(typeExpr.Generics)
+	arity, isGeneric := typeArities[name]
+	isPrimitive, _ := syntax.IsPrimitiveType(name)
+	schema := tree.Schema(name)
+
+	if schema != nil {
+		arity, isGeneric = [2]int{len(schema.Generics), len(schema.Generics)}, len(schema.Generics) > 0
+	}
+
+	switch {
+	case isGeneric && (count < arity[0] || count > arity[1]):
+		p.report(syntax.CodeInvalidType, typeExpr.Span, "'%s' expects %s, got %d", name, typeArguments(arity), count)
+	case !isGeneric && count > 0 && (isPrimitive || schema != nil):
+		p.report(syntax.CodeInvalidType, typeExpr.Span, "'%s' takes no type arguments", name)
+	case typeExpr.Size != nil && name != "Array":
+		p.report(syntax.CodeInvalidType, typeExpr.Size.Span, "'%s' can't have a size, only 'Array' can", name)
+	case typeExpr.Size != nil && !isPositiveInteger(typeExpr.Size):
+		p.report(syntax.CodeInvalidType, typeExpr.Size.Span, "size of 'Array' must be a positive integer, got %s", typeExpr.Size.Raw)
+	}
+
+	switch {
+	case name == "Set" && count == 1:
+		p.validateComparable(tree, typeExpr.Generics[0], "elements of a 'Set'")
+	case name == "Map" && count == 2:
+		p.validateComparable(tree, typeExpr.Generics[0], "keys of a 'Map'")
+	}
+
+	for _, generic := range typeExpr.Generics {
+		p.validateType(tree, generic)
+	}
+}
+
+func (p *EskemaParser) validateComparable(tree *EskemaTree, typeExpr *TypeExpression, usage string) {
+	if uncomparable := uncomparableType(tree, typeExpr, make(map[string]bool)); uncomparable != "" {
+		p.report(syntax.CodeInvalidType, typeExpr.Span, "%s can't be of type '%s', it isn't comparable", usage, uncomparable)
+	}
+}
+
+// uncomparableType returns the name of the type that makes the type uncomparable,
+// it looks through aliases, tuples and the fields of schemas and is empty when the
+// type is comparable
+func uncomparableType(tree *EskemaTree, typeExpr *TypeExpression, seen map[string]bool) string {
+	name := typeExpr.Id.Name
+
+	if uncomparableTypes[name] {
+		return name
+	}
+
+	if alias := tree.Alias(name); alias != nil && !seen[name] {
+		seen[name] = true
+
+		return uncomparableType(tree, alias.Type, seen)
+	}
+
+	// schemas that refer to themselves are comparable as far as their other
+	// fields are
+	if schema := tree.Schema(name); schema != nil && !seen[name] {
+		seen[name] = true
+
+		for _, field := range tree.Fields(schema) {
+			if uncomparableType(tree, field.Type, seen) != "" {
+				return name
+			}
+		}
+	}
+
+	if name == "Tuple" {
+		for _, generic := range typeExpr.Generics {
+			if uncomparable := uncomparableType(tree, generic, seen); uncomparable != "" {
+				return uncomparable
+			}
+		}
+	}
+
+	return ""
+}
+
+func typeArguments(arity [2]int) string {
+	switch {
+	case arity[0] != arity[1]:
+		return fmt.Sprintf("%d or %d type arguments", arity[0], arity[1])
+	case arity[0] == 1:
+		return "1 type argument"
+	default:
+		return fmt.Sprintf("%d type arguments", arity[0])
+	}
+}
+
+func isPositiveInteger(literal *LiteralExpression) bool {
+	integer, err := strconv.ParseInt(literal.Value, 10, 64)
+
+	return err == nil && integer > 0
+}
+
+// validateAliases checks that aliases can be replaced by the type they stand
+// for, which isn't possible when they refer to themselves
+func (p *EskemaParser) validateAliases(tree *EskemaTree) {
+	for _, declaration := range tree.Declarations {
+		alias, isAlias := declaration.(*AliasDefinition)
+
+		if !isAlias {
+			continue
+		}
+
+		if through := aliasCycle(tree, alias.Type, alias, make(map[*AliasDefinition]bool)); through != "" {
+			p.report(syntax.CodeInvalidAlias, alias.Type.Span, "alias '%s' refers to itself through '%s'", alias.Name(), through)
+		}
+	}
+}
+
+// aliasCycle returns the name the type reaches the alias through, it is empty
+// when the alias can't be reached
+func aliasCycle(tree *EskemaTree, typeExpr *TypeExpression, alias *AliasDefinition, seen map[*AliasDefinition]bool) string {
+	if referenced := tree.Alias(typeExpr.Id.Name); referenced != nil {
+		if referenced == alias {
+			return typeExpr.Id.Name
+		}
+
+		if !seen[referenced] {
+			seen[referenced] = true
+
+			if aliasCycle(tree, referenced.Type, alias, seen) != "" {
+				return typeExpr.Id.Name
+			}
+		}
+	}
+
+	for _, generic := range typeExpr.Generics {
+		if through := aliasCycle(tree, generic, alias, seen); through != "" {
+			return through
+		}
+	}
+
+	return ""
+}
+
+// validateParents checks that schemas only inherit from schemas of the same
+// file and that the fields they end up with have a single declaration each
+func (p *EskemaParser) validateParents(tree *EskemaTree) {
+	declarations := make(map[string]Declaration, len(tree.Declarations))
+
+	for _, declaration := range tree.Declarations {
+		declarations[declaration.Name()] = declaration
+	}
+
+	for _, declaration := range tree.Declarations {
+		schema, isSchema := declaration.(*SchemaDefinition)
+
+		if !isSchema {
+			continue
+		}
+
+		isValid := true
+		isInherited := make(map[string]bool)
+
+		for _, parent := range schema.Parents() {
+			name := parent.Id.Name
+			parentSchema, isParentSchema := declarations[name].(*SchemaDefinition)
+
+			switch {
+			case !isParentSchema:
+				p.invalidParent(parent, "'%s' must be a schema declared in this file to be inherited by '%s'", name, schema.Name())
+			case len(parentSchema.Generics) > 0 || len(parent.Generics) > 0:
+				p.invalidParent(parent, "'%s' can't inherit from generic schema '%s'", schema.Name(), name)
+			case isInherited[name]:
+				p.invalidParent(parent, "'%s' is inherited more than once by '%s'", name, schema.Name())
+			case inheritsFrom(tree, parentSchema, schema, make(map[*SchemaDefinition]bool)):
+				p.invalidParent(parent, "'%s' inherits from itself through '%s'", schema.Name(), name)
+			default:
+				isInherited[name] = true
+				continue
+			}
+
+			isValid = false
+		}
+
+		if isValid {
+			p.validateInheritedFields(tree, schema)
+		}
+	}
+}
+
+// validateInheritedFields reports fields declared by more than one schema, the
+// same field inherited through two parents is still a single declaration
+func (p *EskemaParser) validateInheritedFields(tree *EskemaTree, schema *SchemaDefinition) {
+	owners := make(map[string]*ResolvedField)
+
+	for _, parent := range schema.Parents() {
+		for _, field := range tree.ResolveFields(tree.Schema(parent.Id.Name)) {
+			name := field.Field.Id.Name
+
+			if owner, exists := owners[name]; exists && owner.Field != field.Field {
+				p.invalidParent(parent, "'%s' inherits field '%s' from both '%s' and '%s'", schema.Name(), name, owner.Owner.Name(), field.Owner.Name())
+			} else if !exists {
+				owners[name] = field
+			}
+		}
+	}
+
+	for _, field := range schema.Fields {
+		if owner, exists := owners[field.Id.Name]; exists {
+			p.invalidParentField(field, "field '%s' of '%s' is already inherited from '%s'", field.Id.Name, schema.Name(), owner.Owner.Name())
+		}
+	}
+}
+
+// inheritsFrom tells whether the schema reaches the ancestor through its parents
+func inheritsFrom(tree *EskemaTree, schema *SchemaDefinition, ancestor *SchemaDefinition, seen map[*SchemaDefinition]bool) bool {
+	if schema == ancestor {
+		return true
+	}
+
+	if seen[schema] {
+		return false
+	}
+
+	seen[schema] = true
+
+	for _, parent := range schema.Parents() {
+		if parentSchema := tree.Schema(parent.Id.Name); parentSchema != nil && inheritsFrom(tree, parentSchema, ancestor, seen) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateUnions checks that every variant is a schema the union can be told
+// apart by. A schema can only be a variant of a single union since variants
+// extend their union in languages without multiple inheritance
+func (p *EskemaParser) validateUnions(tree *EskemaTree) {
+	declarations := make(map[string]Declaration, len(tree.Declarations))
+
+	for _, declaration := range tree.Declarations {
+		declarations[declaration.Name()] = declaration
+	}
+
+	unionOf := make(map[string]*UnionDefinition)
+
+	for _, declaration := range tree.Declarations {
+		union, isUnion := declaration.(*UnionDefinition)
+
+		if !isUnion {
+			continue
+		}
+
+		isVariant := make(map[string]bool, len(union.Variants))
+
+		for _, variant := range union.Variants {
+			name := variant.Name()
+			schema, isSchema := declarations[name].(*SchemaDefinition)
+
+			switch {
+			case !isSchema:
+				p.invalidVariant(variant, "variant '%s' of union '%s' must be a schema declared in this file", name, union.Name())
+			case len(schema.Generics) > 0 || len(variant.Type.Generics) > 0:
+				p.invalidVariant(variant, "variant '%s' of union '%s' can't be generic", name, union.Name())
+			case isVariant[name]:
+				p.invalidVariant(variant, "'%s' is listed more than once in union '%s'", name, union.Name())
+			case unionOf[name] != nil && unionOf[name] != union:
+				p.invalidVariant(variant, "'%s' is already a variant of union '%s'", name, unionOf[name].Name())
+			case hasField(tree, schema, union.Discriminator.Name):
+				p.invalidVariant(variant, "'%s' has a field named '%s', which is the discriminator of union '%s'", name, union.Discriminator.Name, union.Name())
+			}
+
+			isVariant[name] = true
+
+			if unionOf[name] == nil {
+				unionOf[name] = union
+			}
+		}
+	}
+}
+
+// validateConstraints checks that the annotations of aliases and fields are
+// constraints eskema knows, with the right arguments, on values of a type they
+// can constrain
+func (p *EskemaParser) validateConstraints(tree *EskemaTree) {
+	for _, declaration := range tree.Declarations {
+		switch node := declaration.(type) {
+		case *AliasDefinition:
+			p.validateAnnotations(tree.ResolveType(node.Type), node.Annotations)
+			p.validateBounds(node.Id.Span, tree.AliasConstraints(node))
+		case *SchemaDefinition:
+			for _, field := range node.Fields {
+				p.validateAnnotations(tree.ResolveType(field.Type), field.Annotations)
+				p.validateBounds(field.Id.Span, tree.Constraints(field))
+			}
+		}
+	}
+}
+
+func (p *EskemaParser) validateAnnotations(typeExpr *TypeExpression, annotations []*Annotation) {
+	isAnnotated := make(map[string]bool, len(annotations))
+
+	for _, annotation := range annotations {
+		name := annotation.Id.Name
+		typeName := typeExpr.Id.Name
+
+		switch {
+		case isAnnotated[name]:
+			p.invalidConstraint(annotation, "'@%s' is used more than once", name)
+		case name == MinAnnotation || name == MaxAnnotation:
+			if !IsNumber(typeName) {
+				p.invalidConstraint(annotation, "'@%s' can't be used on '%s', only on numbers", name, typeName)
+			} else if !isNumberArgument(annotation, 1) {
+				p.invalidConstraint(annotation, "'@%s' expects a number", name)
+			} else if IsInteger(typeName) && !isInteger(annotation.Arguments[0].Value) {
+				p.invalidConstraint(annotation, "'@%s' of '%s' expects an integer", name, typeName)
+			}
+		case name == LengthAnnotation || name == NonEmptyAnnotation:
+			if !HasLength(typeName) {
+				p.invalidConstraint(annotation, "'@%s' can't be used on '%s', only on strings, arrays, sets and maps", name, typeName)
+			} else if name == NonEmptyAnnotation && len(annotation.Arguments) > 0 {
+				p.invalidConstraint(annotation, "'@%s' takes no arguments", name)
+			} else if name == LengthAnnotation && !isLengthArgument(annotation) {
+				p.invalidConstraint(annotation, "'@%s' expects a minimum and a maximum length", name)
+			}
+		case name == PatternAnnotation:
+			if typeName != "String" {
+				p.invalidConstraint(annotation, "'@%s' can't be used on '%s', only on strings", name, typeName)
+			} else if len(annotation.Arguments) != 1 || annotation.Arguments[0].Kind != StringLiteral {
+				p.invalidConstraint(annotation, "'@%s' expects a string", name)
+			} else if _, err := regexp.Compile(annotation.Arguments[0].Value); err != nil {
+				p.invalidConstraint(annotation, "'@%s' expects a regular expression: %v", name, err)
+			}
+		default:
+			p.invalidConstraint(annotation, "unknown annotation '@%s', expected @%s, @%s, @%s, @%s or @%s", name, MinAnnotation, MaxAnnotation, LengthAnnotation, PatternAnnotation, NonEmptyAnnotation)
+		}
+
+		isAnnotated[name] = true
+	}
+}
+
+// validateBounds checks that some value can pass the constraints, they may come
+// from different aliases
+func (p *EskemaParser) validateBounds(span syntax.Span, constraints Constraints) {
+	isAbove := func(lower string, upper string) bool {
+		min, minErr := strconv.ParseFloat(lower, 64)
+		max, maxErr := strconv.ParseFloat(upper, 64)
+
+		return minErr == nil && maxErr == nil && min > max
+	}
+
+	if isAbove(constraints.Min, constraints.Max) {
+		p.report(syntax.CodeInvalidConstraint, span, "minimum %s is greater than maximum %s", constraints.Min, constraints.Max)
+	}
+
+	if isAbove(constraints.MinLength, constraints.MaxLength) {
+		p.report(syntax.CodeInvalidConstraint, span, "minimum length %s is greater than maximum length %s", constraints.MinLength, constraints.MaxLength)
+	}
+}
+
+// validateEnums checks that the values of an enum can tell its members apart,
+// either every member has a value of the same kind or none has
+func (p *EskemaParser) validateEnums(tree *EskemaTree) {
+	for _, declaration := range tree.Declarations {
+		enum, isEnum := declaration.(*EnumDefinition)
+
+		if !isEnum || len(enum.Values) == 0 {
+			continue
+		}
+
+		first := enum.Values[0].Value
+		members := make(map[string]string, len(enum.Values))
+		isNamed := make(map[string]bool, len(enum.Values))
+
+		for _, value := range enum.Values {
+			name := value.Id.Name
+
+			switch {
+			case isNamed[name]:
+				p.invalidEnumValue(value, "'%s' is declared more than once in enum '%s'", name, enum.Name())
+			case (first == nil) != (value.Value == nil):
+				p.invalidEnumValue(value, "every value of enum '%s' needs an explicit value when one of them has it", enum.Name())
+			case value.Value == nil:
+			case value.Value.Kind == BooleanLiteral || value.Value.Kind == NumberLiteral && !isInteger(value.Value.Value):
+				p.invalidEnumValue(value, "value of '%s' must be an integer or a string", name)
+			case value.Value.Kind != first.Kind:
+				p.invalidEnumValue(value, "enum '%s' can't mix string and integer values", enum.Name())
+			case members[enumKey(value.Value)] != "":
+				p.invalidEnumValue(value, "'%s' has the same value as '%s'", name, members[enumKey(value.Value)])
+			default:
+				members[enumKey(value.Value)] = name
+			}
+
+			isNamed[name] = true
+		}
+	}
+}
+
+// integerSizes are the bits of each integer primitive, unsigned ones can't be
+// negative
+var integerSizes = map[string]struct {
+	bits       int
+	isUnsigned bool
+}{
+	"Int8": {8, false}, "Int16": {16, false}, "Int32": {32, false}, "Int64": {64, false},
+	"UInt8": {8, true}, "UInt16": {16, true}, "UInt32": {32, true}, "UInt64": {64, true},
+}
+
+// validateConsts checks that constants are of a type every language has
+// literals for and that their value is one of that type
+func (p *EskemaParser) validateConsts(tree *EskemaTree) {
+	for _, declaration := range tree.Declarations {
+		constant, isConst := declaration.(*ConstDefinition)
+
+		if !isConst {
+			continue
+		}
+
+		name := constant.Name()
+		typeName := tree.ResolveType(constant.Type).Id.Name
+		value := constant.Value
+
+		switch {
+		case !IsNumber(typeName) && typeName != "String" && typeName != "Bool":
+			p.invalidConst(constant, "constant '%s' can't be of type '%s', only of numbers, strings and booleans", name, typeName)
+		case typeName == "String" && value.Kind != StringLiteral:
+			p.invalidConst(constant, "value of '%s' must be a string", name)
+		case typeName == "Bool" && value.Kind != BooleanLiteral:
+			p.invalidConst(constant, "value of '%s' must be true or false", name)
+		case IsNumber(typeName) && value.Kind != NumberLiteral:
+			p.invalidConst(constant, "value of '%s' must be a number", name)
+		case IsInteger(typeName) && !fitsInteger(value.Value, typeName):
+			p.invalidConst(constant, "value of '%s' must be an integer that fits in '%s'", name, typeName)
+		default:
+			p.validateConstConstraints(constant, tree.constraints(nil, constant.Type))
+		}
+	}
+}
+
+// validateConstConstraints checks the value against the constraints of the
+// aliases the constant is declared with
+func (p *EskemaParser) validateConstConstraints(constant *ConstDefinition, constraints Constraints) {
+	name := constant.Name()
+	value := constant.Value.Value
+	isBelow := func(value string, bound string) bool {
+		number, numberErr := strconv.ParseFloat(value, 64)
+		limit, limitErr := strconv.ParseFloat(bound, 64)
+
+		return numberErr == nil && limitErr == nil && number < limit
+	}
+	length := strconv.Itoa(utf8.RuneCountInString(value))
+
+	switch {
+	case constant.Value.Kind == NumberLiteral && isBelow(value, constraints.Min):
+		p.invalidConst(constant, "value of '%s' must be at least %s", name, constraints.Min)
+	case constant.Value.Kind == NumberLiteral && isBelow(constraints.Max, value):
+		p.invalidConst(constant, "value of '%s' must be at most %s", name, constraints.Max)
+	case constant.Value.Kind == StringLiteral && isBelow(length, constraints.MinLength):
+		p.invalidConst(constant, "value of '%s' must have a length of at least %s", name, constraints.MinLength)
+	case constant.Value.Kind == StringLiteral && isBelow(constraints.MaxLength, length):
+		p.invalidConst(constant, "value of '%s' must have a length of at most %s", name, constraints.MaxLength)
+	case constant.Value.Kind == StringLiteral && constraints.Pattern != "" && !matches(constraints.Pattern, value):
+		p.invalidConst(constant, "value of '%s' must match its pattern", name)
+	}
+}
+
+// matches skips patterns that don't compile, they are reported on their
+// annotation
+func matches(pattern string, value string) bool {
+	expression, err := regexp.Compile(pattern)
+
+	return err != nil || expression.MatchString(value)
+}
+
+func fitsInteger(value string, typeName string) bool {
+	size := integerSizes[typeName]
+
+	if size.isUnsigned {
+		_, err := strconv.ParseUint(value, 10, size.bits)
+
+		return err == nil
+	}
+
+	_, err := strconv.ParseInt(value, 10, size.bits)
+
+	return err == nil
+}
+
+// enumKey compares integers by their value, 01 and 1 are the same value
+func enumKey(literal *LiteralExpression) string {
+	if integer, err := strconv.ParseInt(literal.Value, 10, 64); literal.Kind == NumberLiteral && err == nil {
+		return strconv.FormatInt(integer, 10)
+	}
+
+	return literal.Value
+}
+
+func isInteger(value string) bool {
+	_, err := strconv.ParseInt(value, 10, 64)
+
+	return err == nil
+}
+
+func isNumberArgument(annotation *Annotation, count int) bool {
+	if len(annotation.Arguments) != count {
+		return false
+	}
+
+	for _, argument := range annotation.Arguments {
+		if argument.Kind != NumberLiteral {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isLengthArgument checks for two lengths, which are integers that can't be
+// negative
+func isLengthArgument(annotation *Annotation) bool {
+	if !isNumberArgument(annotation, 2) {
+		return false
+	}
+
+	for _, argument := range annotation.Arguments {
+		if _, err := strconv.ParseUint(argument.Value, 10, 32); err != nil {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (p *EskemaParser) invalidConstraint(annotation *Annotation, format string, args ...any) {
+	p.report(syntax.CodeInvalidConstraint, annotation.Span, format, args...)
+}
+
+func (p *EskemaParser) invalidEnumValue(value *EnumValue, format string, args ...any) {
+	p.report(syntax.CodeInvalidEnumValue, value.Span, format, args...)
+}
+
+func (p *EskemaParser) invalidConst(constant *ConstDefinition, format string, args ...any) {
+	p.report(syntax.CodeInvalidConst, constant.Value.Span, format, args...)
+}
+
+func (p *EskemaParser) invalidVariant(variant *UnionVariant, format string, args ...any) {
+	p.report(syntax.CodeInvalidVariant, variant.Span, format, args...)
+}
+
+func (p *EskemaParser) invalidParent(parent *TypeExpression, format string, args ...any) {
+	p.report(syntax.CodeInvalidParent, parent.Span, format, args...)
+}
+
+func (p *EskemaParser) invalidParentField(field *FieldExpression, format string, args ...any) {
+	p.report(syntax.CodeInvalidParent, field.Span, format, args...)
+}
+
+func (p *EskemaParser) report(code string, span syntax.Span, format string, args ...any) {
+	p.errorCount++
+	p.diagnostics = append(p.diagnostics, &syntax.Diagnostic{
+		Severity: syntax.SeverityError,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+		Span:     span,
+	})
+}
+
+// hasField looks for the field among the inherited ones too
+func hasField(tree *EskemaTree, schema *SchemaDefinition, name string) bool {
+	for _, field := range tree.Fields(schema) {
+		if field.Id.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,59 @@
+package parser
+
+// Visitor is called back with the concrete type of every node it is given,
+// returning false stops Walk from going into the children of that node
+type Visitor interface {
+	VisitSchema(schema *SchemaDefinition) bool
+	VisitEnum(enum *EnumDefinition) bool
+	VisitField(field *FieldExpression) bool
+	VisitType(typeExpr *TypeExpression) bool
+	VisitEnumValue(value *EnumValue) bool
+	VisitUnion(union *UnionDefinition) bool
+	VisitUnionVariant(variant *UnionVariant) bool
+	VisitAlias(alias *AliasDefinition) bool
+	VisitAnnotation(annotation *Annotation) bool
+	VisitConst(constant *ConstDefinition) bool
+}
+
+// BaseVisitor visits every member of a declaration and does nothing with them,
+// it is meant to be embedded. It leaves the declarations out on purpose so a
+// new kind of declaration breaks the build of visitors that don't handle it
+type BaseVisitor struct{}
+
+func (BaseVisitor) VisitField(*FieldExpression) bool {
+	return true
+}
+
+func (BaseVisitor) VisitType(*TypeExpression) bool {
+	return true
+}
+
+func (BaseVisitor) VisitEnumValue(*EnumValue) bool {
+	return true
+}
+
+func (BaseVisitor) VisitUnionVariant(*UnionVariant) bool {
+	return true
+}
+
+func (BaseVisitor) VisitAnnotation(*Annotation) bool {
+	return true
+}
+
+// Walk visits the node and then its children depth first, in source order
+func Walk(visitor Visitor, node Node) {
+	if !node.Accept(visitor) {
+		return
+	}
+
+	for _, child := range node.children() {
+		Walk(visitor, child)
+	}
+}
+
+// WalkTree walks every declaration of the tree
+func WalkTree(visitor Visitor, tree *EskemaTree) {
+	for _, declaration := range tree.Declarations {
+		Walk(visitor, declaration)
+	}
+}
@@ -0,0 +1,107 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type recordingVisitor struct {
+	BaseVisitor
+	visited []string
+}
+
+func (r *recordingVisitor) record(kind string, node Node, name string) {
+	span := node.Location()
+	r.visited = append(r.visited, fmt.Sprintf("%s %s %d:%d+%d", kind, name, span.Start.Line, span.Start.Column, span.Length))
+}
+
+func (r *recordingVisitor) VisitSchema(schema *SchemaDefinition) bool {
+	r.record("schema", schema, schema.Name())
+
+	return true
+}
+
+func (r *recordingVisitor) VisitEnum(enum *EnumDefinition) bool {
+	r.record("enum", enum, enum.Name())
+
+	return false
+}
+
+func (r *recordingVisitor) VisitField(field *FieldExpression) bool {
+	r.record("field", field, field.Id.Name)
+
+	return true
+}
+
+func (r *recordingVisitor) VisitType(typeExpr *TypeExpression) bool {
+	r.record("type", typeExpr, typeExpr.Id.Name)
+
+	return true
+}
+
+func (r *recordingVisitor) VisitUnion(union *UnionDefinition) bool {
+	r.record("union", union, union.Name())
+
+	return true
+}
+
+func (r *recordingVisitor) VisitUnionVariant(variant *UnionVariant) bool {
+	r.record("variant", variant, variant.Name())
+
+	return true
+}
+
+func (r *recordingVisitor) VisitAlias(alias *AliasDefinition) bool {
+	r.record("alias", alias, alias.Name())
+
+	return true
+}
+
+func (r *recordingVisitor) VisitAnnotation(annotation *Annotation) bool {
+	r.record("annotation", annotation, annotation.Id.Name)
+
+	return true
+}
+
+func (r *recordingVisitor) VisitConst(constant *ConstDefinition) bool {
+	r.record("const", constant, constant.Name())
+
+	return true
+}
+
+func TestWalkTree(t *testing.T) {
+	tree, errs := parse("schema A<T> {\n    a: Map<String, T>?,\n    b: Int32\n};\nenum E { X, Y };\nschema B { };\nunion U: type { B };\nalias I = Int32 @min(0);\nconst MAX: I = 10;")
+
+	if len(errs) > 0 {
+		t.Fatalf("got %v, expected no errors", errs)
+	}
+
+	visitor := &recordingVisitor{}
+	WalkTree(visitor, tree)
+
+	expected := []string{
+		"schema A 1:1+53",
+		"type T 1:10+1",
+		"field a 2:5+18",
+		"type Map 2:8+14",
+		"type String 2:12+6",
+		"type T 2:20+1",
+		"field b 3:5+8",
+		"type Int32 3:8+5",
+		"enum E 5:1+16",
+		"schema B 6:1+13",
+		"union U 7:1+20",
+		"variant B 7:17+1",
+		"type B 7:17+1",
+		"alias I 8:1+24",
+		"type Int32 8:11+5",
+		"annotation min 8:17+7",
+		"const MAX 9:1+18",
+		"type I 9:12+1",
+	}
+
+	if actual := strings.Join(visitor.visited, "\n"); actual != strings.Join(expected, "\n") {
+		t.Errorf("got\n%s\nexpected\n%s", actual, strings.Join(expected, "\n"))
+	}
+}
@@ -0,0 +1,282 @@
+package printer
+
+import (
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/syntax"
+	"strings"
+)
+
+const Indent = "    "
+
+type EskemaPrinter struct {
+	parser.BaseVisitor
+	buffer strings.Builder
+}
+
+// Print turns a tree back into canonical eskema source
+func Print(tree *parser.EskemaTree) string {
+	printer := &EskemaPrinter{}
+
+	return printer.Print(tree)
+}
+
+// Format parses the source and prints it back canonically, source with syntax
+// errors is never formatted since parts of it would be lost
+func Format(source []byte, fileName string) (string, error) {
+	lexer := syntax.NewLexer(source, fileName)
+	eskemaParser := parser.New(lexer.Lex())
+	tree := eskemaParser.Parse()
+
+	if errs := eskemaParser.Errors(); len(errs) > 0 {
+		return "", parser.SyntaxErrors(errs)
+	}
+
+	return Print(tree), nil
+}
+
+func (p *EskemaPrinter) Print(tree *parser.EskemaTree) string {
+	for i, declaration := range tree.Declarations {
+		if i > 0 {
+			p.buffer.WriteString("\n")
+		}
+
+		declaration.Accept(p)
+	}
+
+	if len(tree.Comments) > 0 {
+		if len(tree.Declarations) > 0 {
+			p.buffer.WriteString("\n")
+		}
+
+		p.printLeadingComments(tree.Comments, "")
+	}
+
+	return p.buffer.String()
+}
+
+func (p *EskemaPrinter) VisitSchema(schema *parser.SchemaDefinition) bool {
+	p.printLeadingComments(schema.Comments.Leading, "")
+
+	p.buffer.WriteString("schema ")
+	p.buffer.WriteString(schema.Id.Name)
+
+	if len(schema.Generics) > 0 {
+		p.buffer.WriteString("<")
+		p.printTypeList(schema.Generics)
+		p.buffer.WriteString(">")
+	}
+
+	if schema.Extends != nil {
+		p.buffer.WriteString(" extends ")
+		p.printType(schema.Extends)
+	}
+
+	if len(schema.Includes) > 0 {
+		p.buffer.WriteString(" includes ")
+		p.printTypeList(schema.Includes)
+	}
+
+	p.printTrailingComment(schema.Comments.Trailing)
+	p.buffer.WriteString("{\n")
+
+	for i, field := range schema.Fields {
+		isLast := i+1 == len(schema.Fields)
+
+		p.printLeadingComments(field.Comments.Leading, Indent)
+		p.buffer.WriteString(Indent)
+		p.printField(field)
+
+		if !isLast {
+			p.buffer.WriteString(",")
+		}
+
+		p.printTrailingComment(field.Comments.Trailing)
+	}
+
+	p.printFooter(schema.Footer)
+
+	return false
+}
+
+func (p *EskemaPrinter) printField(field *parser.FieldExpression) {
+	p.buffer.WriteString(field.Id.Name)
+	p.buffer.WriteString(": ")
+	p.printType(field.Type)
+
+	if field.IsOptional {
+		p.buffer.WriteString("?")
+	}
+
+	p.printAnnotations(field.Annotations)
+}
+
+func (p *EskemaPrinter) printType(typeExpr *parser.TypeExpression) {
+	p.buffer.WriteString(typeExpr.Id.Name)
+
+	if len(typeExpr.Generics) > 0 {
+		p.buffer.WriteString("<")
+		p.printTypeList(typeExpr.Generics)
+
+		if typeExpr.Size != nil {
+			p.buffer.WriteString(", ")
+			p.buffer.WriteString(typeExpr.Size.Raw)
+		}
+
+		p.buffer.WriteString(">")
+	}
+}
+
+func (p *EskemaPrinter) printTypeList(types []*parser.TypeExpression) {
+	for i, typ := range types {
+		isLast := i+1 == len(types)
+
+		p.printType(typ)
+
+		if !isLast {
+			p.buffer.WriteString(", ")
+		}
+	}
+}
+
+func (p *EskemaPrinter) VisitEnum(enum *parser.EnumDefinition) bool {
+	p.printLeadingComments(enum.Comments.Leading, "")
+
+	p.buffer.WriteString("enum ")
+	p.buffer.WriteString(enum.Id.Name)
+
+	p.printTrailingComment(enum.Comments.Trailing)
+	p.buffer.WriteString("{\n")
+
+	for i, value := range enum.Values {
+		isLast := i+1 == len(enum.Values)
+
+		p.printLeadingComments(value.Comments.Leading, Indent)
+		p.buffer.WriteString(Indent)
+		p.buffer.WriteString(value.Id.Name)
+
+		if value.Value != nil {
+			p.buffer.WriteString(" = ")
+			p.buffer.WriteString(value.Value.Raw)
+		}
+
+		if !isLast {
+			p.buffer.WriteString(",")
+		}
+
+		p.printTrailingComment(value.Comments.Trailing)
+	}
+
+	p.printFooter(enum.Footer)
+
+	return false
+}
+
+func (p *EskemaPrinter) VisitUnion(union *parser.UnionDefinition) bool {
+	p.printLeadingComments(union.Comments.Leading, "")
+
+	p.buffer.WriteString("union ")
+	p.buffer.WriteString(union.Id.Name)
+	p.buffer.WriteString(": ")
+	p.buffer.WriteString(union.Discriminator.Name)
+
+	p.printTrailingComment(union.Comments.Trailing)
+	p.buffer.WriteString("{\n")
+
+	for i, variant := range union.Variants {
+		isLast := i+1 == len(union.Variants)
+
+		p.printLeadingComments(variant.Comments.Leading, Indent)
+		p.buffer.WriteString(Indent)
+		p.printType(variant.Type)
+
+		if !isLast {
+			p.buffer.WriteString(",")
+		}
+
+		p.printTrailingComment(variant.Comments.Trailing)
+	}
+
+	p.printFooter(union.Footer)
+
+	return false
+}
+
+func (p *EskemaPrinter) VisitAlias(alias *parser.AliasDefinition) bool {
+	p.printLeadingComments(alias.Comments.Leading, "")
+
+	p.buffer.WriteString("alias ")
+	p.buffer.WriteString(alias.Id.Name)
+	p.buffer.WriteString(" = ")
+	p.printType(alias.Type)
+	p.printAnnotations(alias.Annotations)
+	p.buffer.WriteString(";")
+
+	p.printTrailingComment(alias.Comments.Trailing)
+
+	return false
+}
+
+func (p *EskemaPrinter) VisitConst(constant *parser.ConstDefinition) bool {
+	p.printLeadingComments(constant.Comments.Leading, "")
+
+	p.buffer.WriteString("const ")
+	p.buffer.WriteString(constant.Id.Name)
+	p.buffer.WriteString(": ")
+	p.printType(constant.Type)
+	p.buffer.WriteString(" = ")
+	p.buffer.WriteString(constant.Value.Raw)
+	p.buffer.WriteString(";")
+
+	p.printTrailingComment(constant.Comments.Trailing)
+
+	return false
+}
+
+func (p *EskemaPrinter) printAnnotations(annotations []*parser.Annotation) {
+	for _, annotation := range annotations {
+		p.buffer.WriteString(" @")
+		p.buffer.WriteString(annotation.Id.Name)
+
+		if len(annotation.Arguments) == 0 {
+			continue
+		}
+
+		p.buffer.WriteString("(")
+
+		for i, argument := range annotation.Arguments {
+			if i > 0 {
+				p.buffer.WriteString(", ")
+			}
+
+			p.buffer.WriteString(argument.Raw)
+		}
+
+		p.buffer.WriteString(")")
+	}
+}
+
+// printFooter closes a declaration, comments left after its last member stay
+// inside of the body
+func (p *EskemaPrinter) printFooter(footer syntax.Comments) {
+	p.printLeadingComments(footer.Leading, Indent)
+	p.buffer.WriteString("};")
+	p.printTrailingComment(footer.Trailing)
+}
+
+func (p *EskemaPrinter) printLeadingComments(comments []string, indent string) {
+	for _, comment := range comments {
+		p.buffer.WriteString(indent)
+		p.buffer.WriteString(comment)
+		p.buffer.WriteString("\n")
+	}
+}
+
+// printTrailingComment ends the current line, with the comment when there is one
+func (p *EskemaPrinter) printTrailingComment(comment string) {
+	if comment != "" {
+		p.buffer.WriteString(" ")
+		p.buffer.WriteString(comment)
+	}
+
+	p.buffer.WriteString("\n")
+}
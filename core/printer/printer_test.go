@@ -0,0 +1,125 @@
+package printer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFormatExamples(t *testing.T) {
+	fileNames, _ := filepath.Glob("../../examples/*.skm")
+
+	if len(fileNames) == 0 {
+		t.Fatal("got no examples, expected at least one")
+	}
+
+	for _, fileName := range fileNames {
+		source, err := os.ReadFile(fileName)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Run("should keep "+filepath.Base(fileName)+" canonical", func(t *testing.T) {
+			formatted, err := Format(source, fileName)
+
+			if err != nil {
+				t.Fatalf("got %v, expected no error", err)
+			}
+
+			if formatted != string(source) {
+				t.Errorf("got\n%s\nexpected\n%s", formatted, source)
+			}
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		Input    string
+		Expected string
+	}{
+		{
+			"should place braces on their own line and drop trailing commas",
+			"schema A { a: String, b: Map<String,Int32>?, };",
+			"schema A\n{\n    a: String,\n    b: Map<String, Int32>?\n};\n",
+		},
+		{
+			"should separate declarations by a single blank line",
+			"enum B {X,Y};\n\n\n\nschema A {b: B};",
+			"enum B\n{\n    X,\n    Y\n};\n\nschema A\n{\n    b: B\n};\n",
+		},
+		{
+			"should preserve leading, trailing and dangling comments",
+			"// doc\nschema A // header\n{\n    // field\n    a: String, // note\n    // dangling\n}; // end\n// tail\n",
+			"// doc\nschema A // header\n{\n    // field\n    a: String // note\n    // dangling\n}; // end\n\n// tail\n",
+		},
+		{
+			"should preserve comments on enum values",
+			"enum B {\n    // first\n    X, // x\n    Y\n};",
+			"enum B\n{\n    // first\n    X, // x\n    Y\n};\n",
+		},
+		{
+			"should print unions with their discriminator",
+			"schema A {}; schema B {};\n// payment\nunion U : type { A, // a\n B };",
+			"schema A\n{\n};\n\nschema B\n{\n};\n\n// payment\nunion U: type\n{\n    A, // a\n    B\n};\n",
+		},
+		{
+			"should print aliases with their annotations",
+			"// id\nalias Id=Int64 @min( 1 ) @max(10);\nalias Email = String @pattern(\"^\\\"\") // quoted\n;",
+			"// id\nalias Id = Int64 @min(1) @max(10);\n\nalias Email = String @pattern(\"^\\\"\"); // quoted\n",
+		},
+		{
+			"should print the values of enums",
+			"enum Status { ONLINE=\"online\", // on\n OFFLINE =\"off\" };",
+			"enum Status\n{\n    ONLINE = \"online\", // on\n    OFFLINE = \"off\"\n};\n",
+		},
+		{
+			"should print constants with their values",
+			"// paging\nconst MAX_PAGE_SIZE:Int32=100; const ENABLED : Bool = true; // on\nconst TOPIC: String = \"orders\\n\";",
+			"// paging\nconst MAX_PAGE_SIZE: Int32 = 100;\n\nconst ENABLED: Bool = true; // on\n\nconst TOPIC: String = \"orders\\n\";\n",
+		},
+		{
+			"should print the size of arrays",
+			"alias Hash=Array<UInt8,16>;\nschema A { at: Tuple<Double,Double>, tags: Set<String> };",
+			"alias Hash = Array<UInt8, 16>;\n\nschema A\n{\n    at: Tuple<Double, Double>,\n    tags: Set<String>\n};\n",
+		},
+		{
+			"should print the constraints of fields",
+			"schema User { age: Int32? @min(0)@max( 150 ), name: String @length(1,64) // name\n};",
+			"schema User\n{\n    age: Int32? @min(0) @max(150),\n    name: String @length(1, 64) // name\n};\n",
+		},
+		{
+			"should print the parents of schemas",
+			"schema A {}; schema B {}; schema C {};\nschema D   extends A includes B,C { d: Int32 };",
+			"schema A\n{\n};\n\nschema B\n{\n};\n\nschema C\n{\n};\n\nschema D extends A includes B, C\n{\n    d: Int32\n};\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			actual, err := Format([]byte(testCase.Input), "test.skm")
+
+			if err != nil {
+				t.Fatalf("got %v, expected no error", err)
+			}
+
+			if actual != testCase.Expected {
+				t.Errorf("got\n%q\nexpected\n%q", actual, testCase.Expected)
+			}
+
+			again, _ := Format([]byte(actual), "test.skm")
+
+			if again != actual {
+				t.Errorf("formatting is not idempotent, got\n%q\nexpected\n%q", again, actual)
+			}
+		})
+	}
+}
+
+func TestFormatSyntaxError(t *testing.T) {
+	if _, err := Format([]byte("schema A { a: String }"), "test.skm"); err == nil {
+		t.Errorf("got no error, expected a syntax error")
+	}
+}
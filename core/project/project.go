@@ -0,0 +1,190 @@
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Haato3o/eskema/core/utils"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// FileNames are the names a project file is looked up by, in order
+var FileNames = []string{"eskema.yaml", "eskema.yml", "eskema.json"}
+
+var ErrNotFound = errors.New("no eskema.yaml or eskema.json found, pass one with --project")
+
+// Project lists the schemas to compile and every target to generate them for,
+// paths are relative to the directory of the project file
+type Project struct {
+	Inputs  []string  `json:"inputs"`
+	Targets []*Target `json:"targets"`
+	// Directory is where the project file is
+	Directory string `json:"-"`
+}
+
+type Target struct {
+	Language string `json:"language"`
+	Output   string `json:"output"`
+	// Package is the package or namespace of the generated code
+	Package string `json:"package"`
+	// Naming is the style field names are converted to
+	Naming  string  `json:"naming"`
+	Options Options `json:"options"`
+	// Templates is the directory rendered by the template language
+	Templates string `json:"templates"`
+}
+
+// Options are passed to the emitter as they are, JSON values that aren't
+// strings are kept as their JSON text so both file formats read the same
+type Options map[string]string
+
+func (o *Options) UnmarshalJSON(data []byte) error {
+	var values map[string]json.RawMessage
+
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	*o = make(Options, len(values))
+
+	for key, raw := range values {
+		if value, err := strconv.Unquote(string(raw)); err == nil {
+			(*o)[key] = value
+		} else {
+			(*o)[key] = string(raw)
+		}
+	}
+
+	return nil
+}
+
+// Find looks for a project file in the directory
+func Find(directory string) (string, error) {
+	for _, name := range FileNames {
+		fileName := filepath.Join(directory, name)
+
+		if _, err := os.Stat(fileName); err == nil {
+			return fileName, nil
+		}
+	}
+
+	return "", ErrNotFound
+}
+
+// Load reads a project file, files ending in .json are read as JSON and every
+// other one as YAML
+func Load(fileName string) (*Project, error) {
+	source, err := os.ReadFile(fileName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	project, err := Parse(source, fileName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	project.Directory = filepath.Dir(fileName)
+
+	return project, nil
+}
+
+func Parse(source []byte, fileName string) (*Project, error) {
+	if filepath.Ext(fileName) != ".json" {
+		document, err := readYaml(source, fileName)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if source, err = json.Marshal(document); err != nil {
+			return nil, err
+		}
+	}
+
+	project := &Project{}
+	decoder := json.NewDecoder(bytes.NewReader(source))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(project); err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+
+	if err := project.Verify(); err != nil {
+		return nil, fmt.Errorf("%s: %w", fileName, err)
+	}
+
+	project.clean()
+
+	return project, nil
+}
+
+func (p *Project) Verify() error {
+	if len(p.Inputs) == 0 {
+		return errors.New("no inputs to compile")
+	}
+
+	if len(p.Targets) == 0 {
+		return errors.New("no targets to generate")
+	}
+
+	for i, target := range p.Targets {
+		if target == nil || target.Language == "" {
+			return fmt.Errorf("target %d has no language", i+1)
+		}
+
+		if target.Output == "" {
+			return fmt.Errorf("target %d (%s) has no output", i+1, target.Language)
+		}
+	}
+
+	return nil
+}
+
+// InputFileNames lists the files matching the inputs, relative to the directory
+// of the project and separated by slashes, see utils.Glob for the syntax of inputs
+func (p *Project) InputFileNames() ([]string, error) {
+	fileNames := make([]string, 0)
+	isMatched := make(map[string]bool)
+
+	for _, input := range p.Inputs {
+		matches, err := utils.Glob(os.DirFS(p.Directory), input)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, match := range matches {
+			if !isMatched[match] {
+				isMatched[match] = true
+				fileNames = append(fileNames, match)
+			}
+		}
+	}
+
+	sort.Strings(fileNames)
+
+	return fileNames, nil
+}
+
+// clean turns the paths into the slash separated form fs.FS expects
+func (p *Project) clean() {
+	for i, input := range p.Inputs {
+		p.Inputs[i] = path.Clean(filepath.ToSlash(input))
+	}
+
+	for _, target := range p.Targets {
+		target.Output = path.Clean(filepath.ToSlash(target.Output))
+
+		if target.Templates != "" {
+			target.Templates = path.Clean(filepath.ToSlash(target.Templates))
+		}
+	}
+}
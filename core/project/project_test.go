@@ -0,0 +1,234 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var expectedProject = &Project{
+	Inputs: []string{"schemas/*.skm", "shared/user.skm"},
+	Targets: []*Target{
+		{
+			Language: "kotlin",
+			Output:   "generated/kotlin",
+			Package:  "com.example.api",
+			Naming:   "camel",
+			Options:  Options{"serializable": "true"},
+		},
+		{
+			Language: "zod",
+			Output:   "web/src/schemas",
+		},
+	},
+}
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		FileName string
+		Input    string
+	}{
+		{
+			"should read block YAML",
+			"eskema.yaml",
+			`# shared contracts
+inputs:
+  - schemas/*.skm
+  - ./shared/user.skm
+targets:
+  - language: kotlin
+    output: generated/kotlin/
+    package: "com.example.api"
+    naming: camel # fields are camelCase in Kotlin
+    options:
+      serializable: true
+  - language: 'zod'
+    output: web/src/schemas
+`,
+		},
+		{
+			"should read flow sequences and unindented sequences",
+			"eskema.yml",
+			`inputs: [schemas/*.skm, "shared/user.skm",]
+targets:
+- language: kotlin
+  output: generated/kotlin
+  package: com.example.api
+  naming: camel
+  options:
+    serializable: "true"
+- language: zod
+  output: web/src/schemas
+`,
+		},
+		{
+			"should read JSON",
+			"eskema.json",
+			`{
+  "inputs": ["schemas/*.skm", "shared/user.skm"],
+  "targets": [
+    {
+      "language": "kotlin",
+      "output": "generated/kotlin",
+      "package": "com.example.api",
+      "naming": "camel",
+      "options": { "serializable": true }
+    },
+    { "language": "zod", "output": "web/src/schemas" }
+  ]
+}`,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			actual, err := Parse([]byte(testCase.Input), testCase.FileName)
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(actual, expectedProject) {
+				t.Errorf("got %+v, expected %+v", actual, expectedProject)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := []struct {
+		Name     string
+		FileName string
+		Input    string
+		Expected string
+	}{
+		{
+			"should reject unknown keys",
+			"eskema.yaml",
+			"inputs: [a.skm]\ntargets:\n  - language: kotlin\n    ouput: out\n",
+			`unknown field "ouput"`,
+		},
+		{
+			"should reject targets without an output",
+			"eskema.yaml",
+			"inputs: [a.skm]\ntargets:\n  - language: kotlin\n",
+			"target 1 (kotlin) has no output",
+		},
+		{
+			"should reject projects without inputs",
+			"eskema.json",
+			`{"targets": [{"language": "kotlin", "output": "out"}]}`,
+			"no inputs to compile",
+		},
+		{
+			"should point at bad indentation",
+			"eskema.yaml",
+			"inputs:\n  - a.skm\n    - b.skm\n",
+			"eskema.yaml:3: unexpected indentation",
+		},
+		{
+			"should point at unsupported YAML",
+			"eskema.yaml",
+			"inputs: &inputs [a.skm]\n",
+			"eskema.yaml:1: '&' is not supported in project files",
+		},
+		{
+			"should point at multi-line scalars",
+			"eskema.yaml",
+			"inputs:\n  - schemas/\n    a.skm\n",
+			"eskema.yaml:3: multi-line scalars are not supported in project files",
+		},
+		{
+			"should point at nested flow collections",
+			"eskema.yaml",
+			"inputs: [a.skm, [b.skm]]\n",
+			"eskema.yaml:1: flow sequences can only have scalars in project files",
+		},
+		{
+			"should point at complex keys",
+			"eskema.yaml",
+			"? inputs\n: [a.skm]\n",
+			"eskema.yaml:1: complex keys are not supported in project files",
+		},
+		{
+			"should point at several documents",
+			"eskema.yaml",
+			"inputs: [a.skm]\n---\ninputs: [b.skm]\n",
+			"eskema.yaml:2: only a single document is supported in project files",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			_, err := Parse([]byte(testCase.Input), testCase.FileName)
+
+			if err == nil || !strings.Contains(err.Error(), testCase.Expected) {
+				t.Errorf("got %v, expected an error containing %s", err, testCase.Expected)
+			}
+		})
+	}
+}
+
+func TestInputFileNames(t *testing.T) {
+	directory := t.TempDir()
+
+	for _, fileName := range []string{"schemas/a.skm", "schemas/orders/b.skm", "schemas/orders/v1/c.skm", "schemas/orders/v1/c.txt", "other/d.skm"} {
+		fullName := filepath.Join(directory, filepath.FromSlash(fileName))
+
+		if err := os.MkdirAll(filepath.Dir(fullName), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(fullName, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	testCases := []struct {
+		Name     string
+		Inputs   []string
+		Expected []string
+	}{
+		{
+			"should match a single directory with '*'",
+			[]string{"schemas/*.skm"},
+			[]string{"schemas/a.skm"},
+		},
+		{
+			"should match any number of directories with '**'",
+			[]string{"schemas/**/*.skm"},
+			[]string{"schemas/a.skm", "schemas/orders/b.skm", "schemas/orders/v1/c.skm"},
+		},
+		{
+			"should match '**' in the middle of the input",
+			[]string{"**/v1/*.skm", "missing/**/*.skm"},
+			[]string{"schemas/orders/v1/c.skm"},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Name, func(t *testing.T) {
+			project := &Project{Inputs: testCase.Inputs, Directory: directory}
+			actual, err := project.InputFileNames()
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(actual, testCase.Expected) {
+				t.Errorf("got %v, expected %v", actual, testCase.Expected)
+			}
+		})
+	}
+
+	t.Run("should reject '**' that isn't a whole path segment", func(t *testing.T) {
+		project := &Project{Inputs: []string{"schemas/**.skm"}, Directory: directory}
+
+		if _, err := project.InputFileNames(); err == nil || !strings.Contains(err.Error(), "'**' must be a whole path segment") {
+			t.Errorf("got %v, expected the input to be rejected", err)
+		}
+	})
+}
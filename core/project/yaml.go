@@ -0,0 +1,387 @@
+package project
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// yamlLine is a line of a YAML document without its indentation and comment
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// yamlReader reads the subset of YAML project files need, which is:
+//
+//   - block mappings of plain or quoted keys and block sequences, nested by
+//     indenting them with spaces
+//   - single line plain, single quoted and double quoted scalars
+//   - flow sequences of scalars, like [a.skm, "b.skm"], and the empty mapping {}
+//   - comments and a leading '---'
+//
+// Anything else, like anchors, tags, block scalars, flow mappings, complex keys,
+// multi-line scalars or several documents, is reported with the line it is on
+// instead of being read differently than a YAML parser would. Every scalar is
+// read as a string, the project decides what they mean
+type yamlReader struct {
+	fileName string
+	lines    []*yamlLine
+	current  int
+}
+
+func readYaml(source []byte, fileName string) (interface{}, error) {
+	reader := &yamlReader{fileName: fileName}
+
+	if err := reader.split(string(source)); err != nil {
+		return nil, err
+	}
+
+	if len(reader.lines) == 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	value, err := reader.readBlock(reader.lines[0].indent)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if line := reader.peek(); line != nil {
+		return nil, reader.errorAt(line, "unexpected indentation")
+	}
+
+	return value, nil
+}
+
+func (r *yamlReader) split(source string) error {
+	for i, text := range strings.Split(source, "\n") {
+		text = strings.TrimRight(text, "\r")
+		trimmed := strings.TrimLeft(text, " ")
+		line := &yamlLine{number: i + 1, indent: len(text) - len(trimmed)}
+
+		if strings.HasPrefix(trimmed, "\t") {
+			return r.errorAt(line, "tabs can't be used for indentation")
+		}
+
+		if trimmed == "---" && len(r.lines) == 0 {
+			continue
+		}
+
+		if trimmed == "---" || trimmed == "..." || strings.HasPrefix(trimmed, "--- ") {
+			return r.errorAt(line, "only a single document is supported in project files")
+		}
+
+		if strings.HasPrefix(trimmed, "%") {
+			return r.errorAt(line, "directives are not supported in project files")
+		}
+
+		line.text = strings.TrimRight(stripComment(trimmed), " \t")
+
+		if line.text != "" {
+			r.lines = append(r.lines, line)
+		}
+	}
+
+	return nil
+}
+
+func (r *yamlReader) peek() *yamlLine {
+	if r.current >= len(r.lines) {
+		return nil
+	}
+
+	return r.lines[r.current]
+}
+
+func (r *yamlReader) readBlock(indent int) (interface{}, error) {
+	if isSequenceItem(r.peek().text) {
+		return r.readSequence(indent)
+	}
+
+	return r.readMapping(indent)
+}
+
+func (r *yamlReader) readSequence(indent int) (interface{}, error) {
+	items := make([]interface{}, 0)
+
+	for line := r.peek(); line != nil && line.indent == indent && isSequenceItem(line.text); line = r.peek() {
+		item := strings.TrimLeft(strings.TrimPrefix(line.text, "-"), " ")
+
+		if item == "" {
+			r.current++
+			next := r.peek()
+
+			if next == nil || next.indent <= indent {
+				items = append(items, nil)
+				continue
+			}
+
+			value, err := r.readBlock(next.indent)
+
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, value)
+			continue
+		}
+
+		if _, _, isEntry := splitEntry(item); isEntry {
+			// the item is a mapping that starts on the same line as the dash,
+			// its other keys are aligned with the first one
+			line.indent += len(line.text) - len(item)
+			line.text = item
+
+			value, err := r.readMapping(line.indent)
+
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, value)
+			continue
+		}
+
+		value, err := r.readScalar(line, item)
+
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, value)
+		r.current++
+
+		if err := r.expectLineEnd(line); err != nil {
+			return nil, err
+		}
+	}
+
+	return items, nil
+}
+
+func (r *yamlReader) readMapping(indent int) (interface{}, error) {
+	entries := make(map[string]interface{})
+
+	for line := r.peek(); line != nil && line.indent == indent && !isSequenceItem(line.text); line = r.peek() {
+		if line.text == "?" || strings.HasPrefix(line.text, "? ") {
+			return nil, r.errorAt(line, "complex keys are not supported in project files")
+		}
+
+		key, value, isEntry := splitEntry(line.text)
+
+		if !isEntry {
+			return nil, r.errorAt(line, "expected 'key: value'")
+		}
+
+		key, err := unquote(key)
+
+		if err != nil {
+			return nil, r.errorAt(line, err.Error())
+		}
+
+		if _, exists := entries[key]; exists {
+			return nil, r.errorAt(line, fmt.Sprintf("duplicated key '%s'", key))
+		}
+
+		r.current++
+
+		if value != "" {
+			if entries[key], err = r.readScalar(line, value); err != nil {
+				return nil, err
+			}
+
+			if err := r.expectLineEnd(line); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		next := r.peek()
+		isNested := next != nil && (next.indent > indent || (next.indent == indent && isSequenceItem(next.text)))
+
+		if !isNested {
+			entries[key] = nil
+			continue
+		}
+
+		if entries[key], err = r.readBlock(next.indent); err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
+
+func (r *yamlReader) readScalar(line *yamlLine, text string) (interface{}, error) {
+	switch text[0] {
+	case '[':
+		if !strings.HasSuffix(text, "]") {
+			return nil, r.errorAt(line, "unterminated flow sequence")
+		}
+
+		items := make([]interface{}, 0)
+		content := strings.TrimSpace(text[1 : len(text)-1])
+
+		if content == "" {
+			return items, nil
+		}
+
+		for i, item := range splitFlowItems(content) {
+			item = strings.TrimSpace(item)
+
+			if item == "" && i > 0 && strings.HasSuffix(content, ",") {
+				// a trailing comma doesn't add an item
+				continue
+			}
+
+			if item == "" || strings.IndexByte("[]{}", item[0]) >= 0 {
+				return nil, r.errorAt(line, "flow sequences can only have scalars in project files")
+			}
+
+			value, err := unquote(item)
+
+			if err != nil {
+				return nil, r.errorAt(line, err.Error())
+			}
+
+			items = append(items, value)
+		}
+
+		return items, nil
+	case '{':
+		if text == "{}" {
+			return map[string]interface{}{}, nil
+		}
+
+		return nil, r.errorAt(line, "flow mappings are not supported, use a block mapping instead")
+	case '|', '>', '&', '*', '!', '@', '`':
+		return nil, r.errorAt(line, fmt.Sprintf("'%c' is not supported in project files", text[0]))
+	}
+
+	value, err := unquote(text)
+
+	if err != nil {
+		return nil, r.errorAt(line, err.Error())
+	}
+
+	return value, nil
+}
+
+// expectLineEnd reports scalars that continue on the next lines, which are read
+// as a single line value by YAML parsers
+func (r *yamlReader) expectLineEnd(line *yamlLine) error {
+	if next := r.peek(); next != nil && next.indent > line.indent && !isSequenceItem(next.text) {
+		if _, _, isEntry := splitEntry(next.text); !isEntry {
+			return r.errorAt(next, "multi-line scalars are not supported in project files")
+		}
+	}
+
+	return nil
+}
+
+func (r *yamlReader) errorAt(line *yamlLine, message string) error {
+	return fmt.Errorf("%s:%d: %s", r.fileName, line.number, message)
+}
+
+func isSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitEntry splits a 'key: value' line at the first colon that is followed by
+// a space or ends the line and isn't quoted
+func splitEntry(text string) (string, string, bool) {
+	quote := byte(0)
+
+	for i := 0; i < len(text); i++ {
+		switch {
+		case quote != 0:
+			if text[i] == quote {
+				quote = 0
+			}
+		case isQuoteStart(text, i):
+			quote = text[i]
+		case text[i] == ':' && (i+1 == len(text) || text[i+1] == ' '):
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true
+		}
+	}
+
+	return "", "", false
+}
+
+// splitFlowItems splits the content of a flow sequence at the commas that
+// aren't quoted
+func splitFlowItems(content string) []string {
+	items := make([]string, 0)
+	quote := byte(0)
+	start := 0
+
+	for i := 0; i < len(content); i++ {
+		switch {
+		case quote != 0:
+			if content[i] == quote {
+				quote = 0
+			}
+		case isQuoteStart(content, i):
+			quote = content[i]
+		case content[i] == ',':
+			items = append(items, content[start:i])
+			start = i + 1
+		}
+	}
+
+	return append(items, content[start:])
+}
+
+// stripComment removes a '#' comment that starts the line or follows a space
+func stripComment(text string) string {
+	quote := byte(0)
+
+	for i := 0; i < len(text); i++ {
+		switch {
+		case quote != 0:
+			if text[i] == quote {
+				quote = 0
+			}
+		case isQuoteStart(text, i):
+			quote = text[i]
+		case text[i] == '#' && (i == 0 || text[i-1] == ' '):
+			return text[:i]
+		}
+	}
+
+	return text
+}
+
+// isQuoteStart tells whether a quote opens a string, quotes inside of a plain
+// scalar like it's are part of its text
+func isQuoteStart(text string, i int) bool {
+	if text[i] != '"' && text[i] != '\'' {
+		return false
+	}
+
+	return i == 0 || strings.IndexByte(" [,", text[i-1]) >= 0
+}
+
+func unquote(text string) (string, error) {
+	switch {
+	case strings.HasPrefix(text, "\""):
+		value, err := strconv.Unquote(text)
+
+		if err != nil {
+			return "", fmt.Errorf("invalid string %s", text)
+		}
+
+		return value, nil
+	case strings.HasPrefix(text, "'"):
+		if len(text) < 2 || !strings.HasSuffix(text, "'") {
+			return "", fmt.Errorf("unterminated string %s", text)
+		}
+
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'"), nil
+	default:
+		return text, nil
+	}
+}
@@ -0,0 +1,125 @@
+package syntax
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityNote
+)
+
+var severityNames = map[Severity]string{
+	SeverityError:   "error",
+	SeverityWarning: "warning",
+	SeverityNote:    "note",
+}
+
+func (s Severity) String() string {
+	return severityNames[s]
+}
+
+func (s Severity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
+
+// Codes identify each kind of diagnostic so they can be looked up and filtered
+// without matching on messages, errors start with E and warnings with W
+const (
+	CodeInvalidCharacter  = "E0001"
+	CodeUnexpectedToken   = "E0002"
+	CodeInvalidVariant    = "E0003"
+	CodeInvalidParent     = "E0004"
+	CodeInvalidString     = "E0005"
+	CodeInvalidAlias      = "E0006"
+	CodeInvalidConstraint = "E0007"
+	CodeInvalidEnumValue  = "E0008"
+	CodeInvalidType       = "E0009"
+	CodeInvalidConst      = "E0010"
+	CodeUnknownType       = "W0001"
+)
+
+// Span is a region of a source file, it starts at the position of a token and
+// covers Length bytes from there, possibly across lines
+type Span struct {
+	Start  *Metadata
+	Length int
+}
+
+// IsValid tells whether the span points somewhere, nodes that weren't parsed
+// from a source have no position
+func (s Span) IsValid() bool {
+	return s.Start != nil
+}
+
+func (s Span) MarshalJSON() ([]byte, error) {
+	if !s.IsValid() {
+		return []byte("null"), nil
+	}
+
+	return json.Marshal(struct {
+		File   string `json:"file"`
+		Offset int64  `json:"offset"`
+		Line   int64  `json:"line"`
+		Column int64  `json:"column"`
+		Length int    `json:"length"`
+	}{s.Start.Filename, s.Start.Offset, s.Start.Line, s.Start.Column, s.Length})
+}
+
+// SpanOf covers the whole value of the token, the end of file has no length
+func SpanOf(token *Token) Span {
+	if token.Type == EndOfFileToken {
+		return Span{Start: token.Metadata}
+	}
+
+	return Span{Start: token.Metadata, Length: len(token.Value)}
+}
+
+// SpanBetween covers everything from the start of first to the end of last
+func SpanBetween(first *Token, last *Token) Span {
+	end := SpanOf(last)
+
+	return Span{
+		Start:  first.Metadata,
+		Length: int(last.Metadata.Offset-first.Metadata.Offset) + end.Length,
+	}
+}
+
+// SpanAfter is the empty span right after the token, where something that is
+// missing would have to be inserted
+func SpanAfter(token *Token) Span {
+	end := *token.Metadata
+	end.Offset += int64(len(token.Value))
+	end.Column += int64(len(token.Value))
+
+	return Span{Start: &end}
+}
+
+type Note struct {
+	Message string `json:"message"`
+	Span    *Span  `json:"span,omitempty"`
+}
+
+// Fix is a suggested edit that replaces the span with the replacement text
+type Fix struct {
+	Message     string `json:"message"`
+	Span        Span   `json:"span"`
+	Replacement string `json:"replacement"`
+}
+
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	Message  string   `json:"message"`
+	Span     Span     `json:"span"`
+	Notes    []*Note  `json:"notes,omitempty"`
+	Fix      *Fix     `json:"fix,omitempty"`
+}
+
+func (d *Diagnostic) Error() string {
+	return fmt.Sprintf("%v %s[%s]: %s", d.Span.Start, d.Severity, d.Code, d.Message)
+}
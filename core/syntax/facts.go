@@ -1,8 +1,13 @@
 package syntax
 
+import "sort"
+
 var keywords = map[string]Keyword{
 	"schema": SchemaKeyword,
 	"enum":   EnumKeyword,
+	"union":  UnionKeyword,
+	"alias":  AliasKeyword,
+	"const":  ConstKeyword,
 }
 
 var primitives = map[string]Primitive{
@@ -24,6 +29,13 @@ var primitives = map[string]Primitive{
 	"Array":     Array,
 	"Map":       Map,
 	"Bool":      Bool,
+	"UUID":      UUID,
+	"Decimal":   Decimal,
+	"Bytes":     Bytes,
+	"Duration":  Duration,
+	"Url":       Url,
+	"Set":       Set,
+	"Tuple":     Tuple,
 }
 
 var tokens = map[byte]TokenType{
@@ -38,6 +50,10 @@ var tokens = map[byte]TokenType{
 	':': ColonToken,
 	';': SemiColonToken,
 	'?': QuestionMarkToken,
+	'=': EqualsToken,
+	'@': AtToken,
+	'(': ParenStartToken,
+	')': ParenEndToken,
 
 	'{': ScopeStartToken,
 	'}': ScopeEndToken,
@@ -45,6 +61,26 @@ var tokens = map[byte]TokenType{
 	'\n': NewLineToken,
 }
 
+func Keywords() []string {
+	return sortedKeys(keywords)
+}
+
+func Primitives() []string {
+	return sortedKeys(primitives)
+}
+
+func sortedKeys[T any](values map[string]T) []string {
+	keys := make([]string, 0, len(values))
+
+	for key := range values {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
 func IsSpecialToken(value byte) (bool, TokenType) {
 	token, exists := tokens[value]
 
@@ -10,10 +10,19 @@ var equivalents = map[TokenType]string{
 	ColonToken:        ":",
 	SemiColonToken:    ";",
 	QuestionMarkToken: "?",
+	EqualsToken:       "=",
+	AtToken:           "@",
+	ParenStartToken:   "(",
+	ParenEndToken:     ")",
 	ScopeStartToken:   "{",
 	ScopeEndToken:     "}",
 
-	LiteralToken: "Literal",
+	KeywordToken:       "Keyword",
+	LiteralToken:       "Literal",
+	PrimitiveTypeToken: "Primitive",
+	StringToken:        "String",
+	NumberToken:        "Number",
+	BooleanToken:       "Boolean",
 
 	EndOfFileToken: "EOF",
 }
@@ -5,4 +5,20 @@ type Keyword int
 const (
 	SchemaKeyword Keyword = iota
 	EnumKeyword
+	UnionKeyword
+	AliasKeyword
+	ConstKeyword
+)
+
+// Modifiers are only keywords in the header of a schema, anywhere else they are
+// plain literals so existing fields named after them keep working
+const (
+	ExtendsModifier  = "extends"
+	IncludesModifier = "includes"
+)
+
+// Booleans are lexed as their own tokens, they are only values of constants
+const (
+	TrueValue  = "true"
+	FalseValue = "false"
 )
@@ -2,16 +2,19 @@ package syntax
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"os"
+	"strings"
 )
 
 type EskemaLexer struct {
-	fileName string
-	stream   io.ReadSeeker
-	current  int64
-	column   int64
-	line     int64
+	fileName    string
+	stream      io.ReadSeeker
+	current     int64
+	column      int64
+	line        int64
+	diagnostics []*Diagnostic
 }
 
 func (l *EskemaLexer) discard() {
@@ -42,6 +45,8 @@ func (l *EskemaLexer) peek() (byte, error) {
 
 func (l *EskemaLexer) Lex() *TokenStream {
 	tokens := make([]*Token, 0)
+	comments := make(map[*Token]*Comments)
+	leading := make([]string, 0)
 
 	for {
 		token := l.next()
@@ -50,12 +55,80 @@ func (l *EskemaLexer) Lex() *TokenStream {
 			continue
 		}
 
+		if token.Type == CommentToken {
+			previous := len(tokens) - 1
+
+			if previous >= 0 && tokens[previous].Metadata.Line == token.Metadata.Line {
+				l.commentsOf(comments, tokens[previous]).Trailing = token.Value
+			} else {
+				leading = append(leading, token.Value)
+			}
+
+			continue
+		}
+
+		if len(leading) > 0 {
+			l.commentsOf(comments, token).Leading = leading
+			leading = make([]string, 0)
+		}
+
 		tokens = append(tokens, token)
 
 		if token.Type == EndOfFileToken {
-			return newTokenStream(tokens)
+			stream := newTokenStream(tokens)
+			stream.comments = comments
+			stream.diagnostics = l.diagnostics
+
+			return stream
+		}
+	}
+}
+
+func (l *EskemaLexer) commentsOf(comments map[*Token]*Comments, token *Token) *Comments {
+	if _, exists := comments[token]; !exists {
+		comments[token] = &Comments{}
+	}
+
+	return comments[token]
+}
+
+// lookAhead reads the character offset positions after the current one without
+// consuming anything, even when that is past the end of the input
+func (l *EskemaLexer) lookAhead(offset int) (byte, error) {
+	position, _ := l.stream.Seek(0, io.SeekCurrent)
+	buffer := make([]byte, offset+1)
+	read, _ := io.ReadFull(l.stream, buffer)
+	_, _ = l.stream.Seek(position, io.SeekStart)
+
+	if read <= offset {
+		return 0, io.EOF
+	}
+
+	return buffer[offset], nil
+}
+
+func (l *EskemaLexer) lexComment(metadata *Metadata) *Token {
+	var builder strings.Builder
+
+	for {
+		currentCharacter, err := l.lookAhead(0)
+
+		if err != nil || currentCharacter == '\n' {
+			break
+		}
+
+		l.discard()
+
+		if currentCharacter != '\r' {
+			builder.WriteByte(currentCharacter)
 		}
 	}
+
+	return &Token{
+		Metadata: metadata,
+		Value:    strings.TrimRight(builder.String(), " \t"),
+		Type:     CommentToken,
+	}
 }
 
 func (l *EskemaLexer) next() *Token {
@@ -101,11 +174,20 @@ func (l *EskemaLexer) next() *Token {
 		}
 	}
 
+	if next, _ := l.lookAhead(1); currentCharacter == '/' && next == '/' {
+		return l.lexComment(metadata)
+	}
+
+	if currentCharacter == '"' {
+		return l.lexString(metadata)
+	}
+
 	start := l.current
 	for {
-		// TODO: Handle EOF
-
+		// Reading past the end doesn't move the stream, only the counters
 		if err != nil {
+			l.current--
+			l.column--
 			break
 		}
 
@@ -114,6 +196,11 @@ func (l *EskemaLexer) next() *Token {
 			break
 		}
 
+		if next, _ := l.lookAhead(0); l.current > start && currentCharacter == '/' && next == '/' {
+			l.prev()
+			break
+		}
+
 		currentCharacter, err = l.consume()
 	}
 
@@ -123,6 +210,24 @@ func (l *EskemaLexer) next() *Token {
 	_, _ = l.stream.Read(buffer)
 	literal := string(buffer)
 
+	if isNumber(literal) {
+		return &Token{
+			Metadata: metadata,
+			Value:    literal,
+			Type:     NumberToken,
+		}
+	}
+
+	l.verifyLiteral(literal, metadata)
+
+	if literal == TrueValue || literal == FalseValue {
+		return &Token{
+			Metadata: metadata,
+			Value:    literal,
+			Type:     BooleanToken,
+		}
+	}
+
 	if isKeyword, _ := IsKeyword(literal); isKeyword {
 		return &Token{
 			Metadata: metadata,
@@ -146,20 +251,125 @@ func (l *EskemaLexer) next() *Token {
 	}
 }
 
-func NewLexerFromFile(fileName string) *EskemaLexer {
-	rawData, _ := os.ReadFile(fileName)
+// lexString reads a string up to its closing quote, the value keeps the quotes
+// and escapes as written so it can be printed back. Strings can't span lines
+func (l *EskemaLexer) lexString(metadata *Metadata) *Token {
+	var builder strings.Builder
+
+	quote, _ := l.consume()
+	builder.WriteByte(quote)
+
+	isEscaped := false
+
+	for {
+		currentCharacter, err := l.lookAhead(0)
+
+		if err != nil || currentCharacter == '\n' {
+			l.diagnostics = append(l.diagnostics, &Diagnostic{
+				Severity: SeverityError,
+				Code:     CodeInvalidString,
+				Message:  "string is never closed",
+				Span:     Span{Start: metadata, Length: builder.Len()},
+				Fix: &Fix{
+					Message:     "insert '\"'",
+					Span:        Span{Start: &Metadata{Filename: metadata.Filename, Offset: l.current, Line: l.line, Column: l.column}},
+					Replacement: "\"",
+				},
+			})
+
+			builder.WriteByte('"')
+			break
+		}
+
+		l.discard()
+		builder.WriteByte(currentCharacter)
+
+		if currentCharacter == '"' && !isEscaped {
+			break
+		}
+
+		isEscaped = currentCharacter == '\\' && !isEscaped
+	}
+
+	return &Token{
+		Metadata: metadata,
+		Value:    builder.String(),
+		Type:     StringToken,
+	}
+}
+
+// isNumber accepts integers and decimals, optionally negative
+func isNumber(literal string) bool {
+	literal = strings.TrimPrefix(literal, "-")
+	integer, fraction, hasFraction := strings.Cut(literal, ".")
+
+	return isDigits(integer) && (!hasFraction || isDigits(fraction))
+}
+
+func isDigits(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return false
+		}
+	}
+
+	return value != ""
+}
+
+// verifyLiteral reports the first character that can't be part of a name, the
+// literal is still emitted so the parser can keep going
+func (l *EskemaLexer) verifyLiteral(literal string, metadata *Metadata) {
+	for i := 0; i < len(literal); i++ {
+		if isIdentifierCharacter(literal[i]) {
+			continue
+		}
+
+		position := *metadata
+		position.Offset += int64(i)
+		position.Column += int64(i)
+		span := Span{Start: &position, Length: 1}
+
+		l.diagnostics = append(l.diagnostics, &Diagnostic{
+			Severity: SeverityError,
+			Code:     CodeInvalidCharacter,
+			Message:  fmt.Sprintf("invalid character '%c' in '%s'", literal[i], literal),
+			Span:     span,
+			Notes: []*Note{
+				{Message: "names can only contain letters, digits and underscores"},
+			},
+			Fix: &Fix{Message: "remove the character", Span: span},
+		})
+
+		return
+	}
+}
+
+func isIdentifierCharacter(character byte) bool {
+	return character == '_' ||
+		(character >= 'a' && character <= 'z') ||
+		(character >= 'A' && character <= 'Z') ||
+		(character >= '0' && character <= '9')
+}
+
+func NewLexerFromFile(fileName string) (*EskemaLexer, error) {
+	rawData, err := os.ReadFile(fileName)
+
+	if err != nil {
+		return nil, err
+	}
 
-	return NewLexer(rawData, fileName)
+	return NewLexer(rawData, fileName), nil
 }
 
 func NewLexer(input []byte, fileName string) *EskemaLexer {
 	buffer := bytes.NewReader(input)
 
 	return &EskemaLexer{
-		fileName: fileName,
-		stream:   buffer,
-		current:  0,
-		column:   1,
-		line:     1,
+		fileName:    fileName,
+		stream:      buffer,
+		current:     0,
+		column:      1,
+		line:        1,
+		diagnostics: make([]*Diagnostic, 0),
 	}
 }
@@ -0,0 +1,62 @@
+package syntax
+
+import (
+	"testing"
+)
+
+func TestLexAnnotations(t *testing.T) {
+	stream := NewLexer([]byte(`alias Email = String @pattern("^\"[a-z]+$", -1.5);`), "test.skm").Lex()
+	expected := []struct {
+		Type  TokenType
+		Value string
+	}{
+		{KeywordToken, "alias"},
+		{LiteralToken, "Email"},
+		{EqualsToken, "="},
+		{PrimitiveTypeToken, "String"},
+		{AtToken, "@"},
+		{LiteralToken, "pattern"},
+		{ParenStartToken, "("},
+		{StringToken, `"^\"[a-z]+$"`},
+		{CommaToken, ","},
+		{NumberToken, "-1.5"},
+		{ParenEndToken, ")"},
+		{SemiColonToken, ";"},
+		{EndOfFileToken, "\x00"},
+	}
+
+	for i, token := range expected {
+		actual := stream.PeekAt(i)
+
+		if actual.Type != token.Type || actual.Value != token.Value {
+			t.Errorf("token %d: got %s '%s', expected %s '%s'", i, actual.Type, actual.Value, token.Type, token.Value)
+		}
+	}
+
+	if diagnostics := stream.Diagnostics(); len(diagnostics) != 0 {
+		t.Errorf("got %v, expected no diagnostics", diagnostics)
+	}
+}
+
+func TestLexConst(t *testing.T) {
+	stream := NewLexer([]byte("const ENABLED: Bool = true;"), "test.skm").Lex()
+	expected := []TokenType{KeywordToken, LiteralToken, ColonToken, PrimitiveTypeToken, EqualsToken, BooleanToken, SemiColonToken, EndOfFileToken}
+
+	for i, tokenType := range expected {
+		if actual := stream.PeekAt(i); actual.Type != tokenType {
+			t.Errorf("token %d: got %s '%s', expected %s", i, actual.Type, actual.Value, tokenType)
+		}
+	}
+}
+
+func TestLexUnclosedString(t *testing.T) {
+	stream := NewLexer([]byte("\"open\nschema"), "test.skm").Lex()
+
+	if diagnostics := stream.Diagnostics(); len(diagnostics) != 1 || diagnostics[0].Code != CodeInvalidString {
+		t.Errorf("got %v, expected the string to be reported as never closed", diagnostics)
+	}
+
+	if token := stream.PeekAt(1); token.Type != KeywordToken {
+		t.Errorf("got %s, expected the next line to be lexed", token.Type)
+	}
+}
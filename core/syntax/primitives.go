@@ -21,4 +21,11 @@ const (
 	Array
 	Map
 	Bool
+	UUID
+	Decimal
+	Bytes
+	Duration
+	Url
+	Set
+	Tuple
 )
@@ -1,8 +1,10 @@
 package syntax
 
 type TokenStream struct {
-	current int
-	tokens  []*Token
+	current     int
+	tokens      []*Token
+	comments    map[*Token]*Comments
+	diagnostics []*Diagnostic
 }
 
 func (s *TokenStream) Prev() *Token {
@@ -11,9 +13,9 @@ func (s *TokenStream) Prev() *Token {
 }
 
 func (s *TokenStream) Next() *Token {
-	current := s.current
+	token := s.PeekAt(s.current)
 	s.current++
-	return s.tokens[current]
+	return token
 }
 
 func (s *TokenStream) PeekCurrent() *Token {
@@ -32,6 +34,47 @@ func (s *TokenStream) PeekAt(index int) *Token {
 	return s.tokens[index]
 }
 
+func (s *TokenStream) Tokens() []*Token {
+	return s.tokens
+}
+
+// Diagnostics are the problems found by the lexer while producing the tokens
+func (s *TokenStream) Diagnostics() []*Diagnostic {
+	return s.diagnostics
+}
+
+func (s *TokenStream) Position() int {
+	return s.current
+}
+
+// CommentsBetween gathers the comments of every token from start up to end,
+// excluding end, trailing comments after the first one found are kept as leading
+func (s *TokenStream) CommentsBetween(start int, end int) Comments {
+	comments := Comments{}
+
+	for i := start; i < end && i < len(s.tokens); i++ {
+		tokenComments, exists := s.comments[s.tokens[i]]
+
+		if !exists {
+			continue
+		}
+
+		comments.Leading = append(comments.Leading, tokenComments.Leading...)
+
+		if tokenComments.Trailing == "" {
+			continue
+		}
+
+		if comments.Trailing == "" {
+			comments.Trailing = tokenComments.Trailing
+		} else {
+			comments.Leading = append(comments.Leading, tokenComments.Trailing)
+		}
+	}
+
+	return comments
+}
+
 func newTokenStream(tokens []*Token) *TokenStream {
 	return &TokenStream{
 		current: 0,
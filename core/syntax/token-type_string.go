@@ -12,22 +12,30 @@ func _() {
 	_ = x[KeywordToken-1]
 	_ = x[LiteralToken-2]
 	_ = x[PrimitiveTypeToken-3]
-	_ = x[WhitespaceToken-4]
-	_ = x[LesserThanToken-5]
-	_ = x[GreaterThanToken-6]
-	_ = x[CommaToken-7]
-	_ = x[ColonToken-8]
-	_ = x[SemiColonToken-9]
-	_ = x[QuestionMarkToken-10]
-	_ = x[ScopeStartToken-11]
-	_ = x[ScopeEndToken-12]
-	_ = x[NewLineToken-13]
-	_ = x[EndOfFileToken-14]
+	_ = x[StringToken-4]
+	_ = x[NumberToken-5]
+	_ = x[BooleanToken-6]
+	_ = x[WhitespaceToken-7]
+	_ = x[LesserThanToken-8]
+	_ = x[GreaterThanToken-9]
+	_ = x[CommaToken-10]
+	_ = x[ColonToken-11]
+	_ = x[SemiColonToken-12]
+	_ = x[QuestionMarkToken-13]
+	_ = x[EqualsToken-14]
+	_ = x[AtToken-15]
+	_ = x[ParenStartToken-16]
+	_ = x[ParenEndToken-17]
+	_ = x[ScopeStartToken-18]
+	_ = x[ScopeEndToken-19]
+	_ = x[NewLineToken-20]
+	_ = x[CommentToken-21]
+	_ = x[EndOfFileToken-22]
 }
 
-const _TokenType_name = "InvalidTokenKeywordTokenLiteralTokenPrimitiveTypeTokenWhitespaceTokenLesserThanTokenGreaterThanTokenCommaTokenColonTokenSemiColonTokenQuestionMarkTokenScopeStartScopeEndNewLineTokenEndOfFileToken"
+const _TokenType_name = "InvalidTokenKeywordTokenLiteralTokenPrimitiveTypeTokenStringTokenNumberTokenBooleanTokenWhitespaceTokenLesserThanTokenGreaterThanTokenCommaTokenColonTokenSemiColonTokenQuestionMarkTokenEqualsTokenAtTokenParenStartTokenParenEndTokenScopeStartTokenScopeEndTokenNewLineTokenCommentTokenEndOfFileToken"
 
-var _TokenType_index = [...]uint8{0, 12, 24, 36, 54, 69, 84, 100, 110, 120, 134, 151, 161, 169, 181, 195}
+var _TokenType_index = [...]uint16{0, 12, 24, 36, 54, 65, 76, 88, 103, 118, 134, 144, 154, 168, 185, 196, 203, 218, 231, 246, 259, 271, 283, 297}
 
 func (i TokenType) String() string {
 	if i < 0 || i >= TokenType(len(_TokenType_index)-1) {
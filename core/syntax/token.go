@@ -12,6 +12,9 @@ const (
 	KeywordToken
 	LiteralToken
 	PrimitiveTypeToken
+	StringToken
+	NumberToken
+	BooleanToken
 
 	WhitespaceToken
 	LesserThanToken
@@ -20,11 +23,16 @@ const (
 	ColonToken
 	SemiColonToken
 	QuestionMarkToken
+	EqualsToken
+	AtToken
+	ParenStartToken
+	ParenEndToken
 
 	ScopeStartToken
 	ScopeEndToken
 
 	NewLineToken
+	CommentToken
 	EndOfFileToken
 )
 
@@ -44,3 +52,14 @@ type Token struct {
 	Value    string
 	Type     TokenType
 }
+
+// Comments are the comments attached to a token, leading ones are on the lines
+// before it and a trailing one starts on the same line right after it
+type Comments struct {
+	Leading  []string
+	Trailing string
+}
+
+func (c *Comments) IsEmpty() bool {
+	return len(c.Leading) == 0 && c.Trailing == ""
+}
@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// Glob is fs.Glob with support for '**' as a whole path segment, which matches any
+// number of directories instead of being read as '*'
+func Glob(fileSystem fs.FS, pattern string) ([]string, error) {
+	if !strings.Contains(pattern, "**") {
+		return fs.Glob(fileSystem, pattern)
+	}
+
+	segments := strings.Split(pattern, "/")
+	root := make([]string, 0)
+
+	for i, segment := range segments {
+		if strings.Contains(segment, "**") && segment != "**" {
+			return nil, fmt.Errorf("invalid input '%s', '**' must be a whole path segment", pattern)
+		}
+
+		if _, err := path.Match(segment, ""); err != nil {
+			return nil, fmt.Errorf("invalid input '%s': %w", pattern, err)
+		}
+
+		if len(root) == i && !hasMeta(segment) {
+			root = append(root, segment)
+		}
+	}
+
+	matches := make([]string, 0)
+	walkRoot := path.Join(append([]string{"."}, root...)...)
+
+	err := fs.WalkDir(fileSystem, walkRoot, func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipDir
+			}
+
+			return err
+		}
+
+		if !entry.IsDir() && matchSegments(segments, strings.Split(name, "/")) {
+			matches = append(matches, name)
+		}
+
+		return nil
+	})
+
+	return matches, err
+}
+
+// matchSegments matches a path against the segments of a pattern, '**' matches
+// zero or more segments
+func matchSegments(pattern []string, name []string) bool {
+	if len(pattern) == 0 {
+		return len(name) == 0
+	}
+
+	if pattern[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchSegments(pattern[1:], name[i:]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	if len(name) == 0 {
+		return false
+	}
+
+	isMatch, _ := path.Match(pattern[0], name[0])
+
+	return isMatch && matchSegments(pattern[1:], name[1:])
+}
+
+func hasMeta(segment string) bool {
+	return strings.ContainsAny(segment, `*?[\`)
+}
@@ -23,20 +23,26 @@ const (
 func VisualizeTree(tree *parser.EskemaTree) {
 	result := ""
 
-	for i, expr := range tree.Expr {
-		result += buildExpression(expr, getOrder(i, len(tree.Expr)))
+	for i, declaration := range tree.Declarations {
+		result += buildDeclaration(declaration, getOrder(i, len(tree.Declarations)))
 	}
 
 	println(result)
 }
 
-func buildExpression(expr *parser.EskemaExpression, order TreeOrder) string {
-
-	switch expr.Type {
-	case parser.EnumExpr:
-		return buildEnum(expr.Data.(*parser.EnumDefinition), order)
-	case parser.SchemaExpr:
-		return buildSchema(expr.Data.(*parser.SchemaDefinition), order)
+func buildDeclaration(declaration parser.Declaration, order TreeOrder) string {
+
+	switch declaration := declaration.(type) {
+	case *parser.EnumDefinition:
+		return buildEnum(declaration, order)
+	case *parser.SchemaDefinition:
+		return buildSchema(declaration, order)
+	case *parser.UnionDefinition:
+		return buildUnion(declaration, order)
+	case *parser.AliasDefinition:
+		return buildAlias(declaration, order)
+	case *parser.ConstDefinition:
+		return buildConst(declaration, order)
 	default:
 		return ""
 	}
@@ -51,6 +57,14 @@ func buildSchema(schema *parser.SchemaDefinition, order TreeOrder) string {
 		baseString += buildType(generic, level, getOrder(i, len(schema.Generics)+len(schema.Fields)))
 	}
 
+	if schema.Extends != nil {
+		baseString += buildParent("extends", schema.Extends, level, getOrder(0, 1+len(schema.Includes)+len(schema.Fields)))
+	}
+
+	for i, include := range schema.Includes {
+		baseString += buildParent("includes", include, level, getOrder(i, len(schema.Includes)+len(schema.Fields)))
+	}
+
 	for i, field := range schema.Fields {
 		baseString += buildField(field, level, getOrder(i, len(schema.Fields)))
 	}
@@ -64,6 +78,10 @@ func buildType(typeExpr *parser.TypeExpression, level string, order TreeOrder) s
 
 	baseString := fmt.Sprintf("%s type: '%s'\n", currentLevel, typeExpr.Id.Name)
 
+	if typeExpr.Size != nil {
+		baseString = fmt.Sprintf("%s type: '%s' of size %s\n", currentLevel, typeExpr.Id.Name, typeExpr.Size.Raw)
+	}
+
 	for i, generic := range typeExpr.Generics {
 		baseString += buildType(generic, childLevel, getOrder(i, len(typeExpr.Generics)))
 	}
@@ -71,6 +89,10 @@ func buildType(typeExpr *parser.TypeExpression, level string, order TreeOrder) s
 	return baseString
 }
 
+func buildParent(modifier string, parent *parser.TypeExpression, level string, order TreeOrder) string {
+	return fmt.Sprintf("%s%s %s: '%s'\n", level, getParentConnector(order), modifier, parent.Id.Name)
+}
+
 func buildField(field *parser.FieldExpression, level string, order TreeOrder) string {
 	childLevel := fmt.Sprintf("%s%s   ", level, getTreeRootConnector(order))
 	currentLevel := fmt.Sprintf("%s%s", level, getParentConnector(order))
@@ -83,7 +105,11 @@ func buildField(field *parser.FieldExpression, level string, order TreeOrder) st
 
 	baseString := fmt.Sprintf("%s field: %s %s\n", currentLevel, field.Id.Name, optional)
 
-	baseString += buildType(field.Type, childLevel, Last)
+	baseString += buildType(field.Type, childLevel, getOrder(0, 1+len(field.Annotations)))
+
+	for i, annotation := range field.Annotations {
+		baseString += buildValue("@"+annotation.Id.Name, childLevel, i+1 == len(field.Annotations))
+	}
 
 	return baseString
 }
@@ -97,12 +123,53 @@ func buildEnum(enum *parser.EnumDefinition, order TreeOrder) string {
 
 		isLast := i == (len(enum.Values) - 1)
 
-		baseString += buildValue(value, level, isLast)
+		name := value.Id.Name
+
+		if value.Value != nil {
+			name += " = " + value.Value.Raw
+		}
+
+		baseString += buildValue(name, level, isLast)
 	}
 
 	return baseString
 }
 
+func buildUnion(union *parser.UnionDefinition, order TreeOrder) string {
+	level := fmt.Sprintf("%s   ", getTreeRootConnector(order))
+
+	baseString := fmt.Sprintf("%s union: %s [%s]\n", getParentConnector(order), union.Id.Name, union.Discriminator.Name)
+
+	for i, variant := range union.Variants {
+		baseString += buildType(variant.Type, level, getOrder(i, len(union.Variants)))
+	}
+
+	return baseString
+}
+
+func buildAlias(alias *parser.AliasDefinition, order TreeOrder) string {
+	level := fmt.Sprintf("%s   ", getTreeRootConnector(order))
+
+	baseString := fmt.Sprintf("%s alias: %s\n", getParentConnector(order), alias.Id.Name)
+	baseString += buildType(alias.Type, level, getOrder(0, 1+len(alias.Annotations)))
+
+	for i, annotation := range alias.Annotations {
+		baseString += buildValue("@"+annotation.Id.Name, level, i+1 == len(alias.Annotations))
+	}
+
+	return baseString
+}
+
+func buildConst(constant *parser.ConstDefinition, order TreeOrder) string {
+	level := fmt.Sprintf("%s   ", getTreeRootConnector(order))
+
+	baseString := fmt.Sprintf("%s const: %s\n", getParentConnector(order), constant.Id.Name)
+	baseString += buildType(constant.Type, level, getOrder(0, 2))
+	baseString += buildValue(constant.Value.Raw, level, true)
+
+	return baseString
+}
+
 func buildValue(value string, level string, isLast bool) string {
 	connector := TreeCharacter
 
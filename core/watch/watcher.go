@@ -0,0 +1,137 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"time"
+)
+
+const (
+	DefaultInterval = 200 * time.Millisecond
+	DefaultDebounce = 300 * time.Millisecond
+)
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// Watcher polls files for changes, which works the same on every platform and
+// file system. The files are listed again on every poll so new ones are seen
+type Watcher struct {
+	Interval time.Duration
+	// Debounce is how long files have to stay unchanged before the changes are
+	// reported, editors often write a file more than once when saving
+	Debounce time.Duration
+	list     func() ([]string, error)
+	states   map[string]fileState
+}
+
+// Changes polls the files once and returns the ones that were created,
+// modified or removed since the last poll, the first poll returns all of them
+func (w *Watcher) Changes() ([]string, error) {
+	fileNames, err := w.list()
+
+	if err != nil {
+		return nil, err
+	}
+
+	changed := make([]string, 0)
+	isListed := make(map[string]bool, len(fileNames))
+
+	for _, fileName := range fileNames {
+		isListed[fileName] = true
+		info, err := os.Stat(fileName)
+
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		state := fileState{size: info.Size(), modTime: info.ModTime()}
+
+		if previous, exists := w.states[fileName]; !exists || previous != state {
+			w.states[fileName] = state
+			changed = append(changed, fileName)
+		}
+	}
+
+	for fileName := range w.states {
+		if _, err := os.Stat(fileName); !isListed[fileName] || errors.Is(err, fs.ErrNotExist) {
+			delete(w.states, fileName)
+			changed = append(changed, fileName)
+		}
+	}
+
+	sort.Strings(changed)
+
+	return changed, nil
+}
+
+// Forget makes the next poll report the files as if they were just created
+func (w *Watcher) Forget(fileNames ...string) {
+	for _, fileName := range fileNames {
+		delete(w.states, fileName)
+	}
+}
+
+// Run polls until the context is done, calling onChange with every file that
+// changed once they stop changing for the debounce duration
+func (w *Watcher) Run(ctx context.Context, onChange func(changed []string)) error {
+	ticker := time.NewTicker(w.Interval)
+	defer ticker.Stop()
+
+	pending := make(map[string]bool)
+	lastChange := time.Now()
+
+	for {
+		changed, err := w.Changes()
+
+		if err != nil {
+			return err
+		}
+
+		for _, fileName := range changed {
+			pending[fileName] = true
+			lastChange = time.Now()
+		}
+
+		if len(pending) > 0 && time.Since(lastChange) >= w.Debounce {
+			onChange(sortedKeys(pending))
+			pending = make(map[string]bool)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+
+	for key := range set {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// New creates a watcher for the files returned by list, missing files are
+// ignored until they are created
+func New(list func() ([]string, error)) *Watcher {
+	return &Watcher{
+		Interval: DefaultInterval,
+		Debounce: DefaultDebounce,
+		list:     list,
+		states:   make(map[string]fileState),
+	}
+}
@@ -0,0 +1,101 @@
+package watch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestChanges(t *testing.T) {
+	directory := t.TempDir()
+	first := filepath.Join(directory, "first.skm")
+	second := filepath.Join(directory, "second.skm")
+	fileNames := []string{first, second}
+
+	writeFile(t, first, "schema A { a: String };")
+
+	watcher := New(func() ([]string, error) {
+		return fileNames, nil
+	})
+
+	steps := []struct {
+		Name     string
+		Change   func()
+		Expected []string
+	}{
+		{"should report existing files on the first poll", func() {}, []string{first}},
+		{"should report nothing when nothing changed", func() {}, []string{}},
+		{"should report created files", func() { writeFile(t, second, "enum E { X };") }, []string{second}},
+		{
+			"should report modified files",
+			func() {
+				writeFile(t, first, "schema A { a: Int32 };")
+				later := time.Now().Add(time.Minute)
+				_ = os.Chtimes(first, later, later)
+			},
+			[]string{first},
+		},
+		{"should report removed files", func() { _ = os.Remove(second) }, []string{second}},
+		{"should report files that are no longer listed", func() { fileNames = []string{second} }, []string{first}},
+	}
+
+	for _, step := range steps {
+		step.Change()
+
+		changed, err := watcher.Changes()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(changed, step.Expected) {
+			t.Errorf("%s: got %v, expected %v", step.Name, changed, step.Expected)
+		}
+	}
+}
+
+func TestRunDebounces(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), "schema.skm")
+	writeFile(t, fileName, "schema A { a: String };")
+
+	watcher := New(func() ([]string, error) {
+		return []string{fileName}, nil
+	})
+	watcher.Interval = 5 * time.Millisecond
+	watcher.Debounce = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	calls := 0
+	_ = watcher.Run(ctx, func([]string) { calls++ })
+
+	if calls != 0 {
+		t.Errorf("got %d calls, expected changes to wait for the debounce", calls)
+	}
+
+	watcher = New(func() ([]string, error) {
+		return []string{fileName}, nil
+	})
+	watcher.Interval = 5 * time.Millisecond
+	watcher.Debounce = 10 * time.Millisecond
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	var reported []string
+	_ = watcher.Run(ctx, func(changed []string) { reported = append(reported, changed...) })
+
+	if !reflect.DeepEqual(reported, []string{fileName}) {
+		t.Errorf("got %v, expected a single report of %s", reported, fileName)
+	}
+}
+
+func writeFile(t *testing.T, fileName string, content string) {
+	if err := os.WriteFile(fileName, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Haato3o/eskema/cli"
+	"github.com/Haato3o/eskema/core/compatibility"
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/syntax"
+	"log"
+	"os"
+)
+
+// runDiff exits with 1 when a change breaks the policy so it can gate CI, and
+// with 2 when the schemas couldn't be compared at all
+func runDiff(arguments []string) {
+	args := cli.ParseDiffArguments(arguments)
+
+	if err := args.VerifyRequired(); err != nil {
+		log.Println(err)
+		os.Exit(2)
+	}
+
+	policy, err := compatibility.ParsePolicy(args.Policy)
+
+	if err != nil {
+		log.Println(err)
+		os.Exit(2)
+	}
+
+	oldTree, err := parseEskemaFile(args.OldFileName)
+
+	if err != nil {
+		reportError(args.DiagnosticsFormat, err)
+		os.Exit(2)
+	}
+
+	newTree, err := parseEskemaFile(args.NewFileName)
+
+	if err != nil {
+		reportError(args.DiagnosticsFormat, err)
+		os.Exit(2)
+	}
+
+	report := compatibility.NewReport(policy, compatibility.Compare(oldTree, newTree))
+
+	switch args.Format {
+	case "json":
+		output, _ := json.MarshalIndent(report, "", "  ")
+		fmt.Println(string(output))
+	case "text":
+		printReport(report)
+	default:
+		log.Printf("unknown report format '%s', expected text or json\n", args.Format)
+		os.Exit(2)
+	}
+
+	if report.IsBreaking {
+		os.Exit(1)
+	}
+}
+
+func printReport(report *compatibility.Report) {
+	breakingChanges := 0
+
+	for _, entry := range report.Changes {
+		status := "compatible"
+
+		if entry.IsBreaking {
+			status = "BREAKING"
+			breakingChanges++
+		}
+
+		fmt.Printf("%-10s  %s\n", status, entry)
+	}
+
+	fmt.Printf("%d change(s), %d breaking under the %s policy\n", len(report.Changes), breakingChanges, report.Policy)
+}
+
+func parseEskemaFile(fileName string) (*parser.EskemaTree, error) {
+	source, err := os.ReadFile(fileName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	eskemaParser := parser.New(syntax.NewLexer(source, fileName).Lex())
+	tree := eskemaParser.Parse()
+
+	if errs := eskemaParser.Errors(); len(errs) > 0 {
+		return nil, parser.SyntaxErrors(errs)
+	}
+
+	return tree, nil
+}
@@ -1,7 +1,239 @@
 package emitter
 
-import "github.com/Haato3o/eskema/core/parser"
+import (
+	"fmt"
+	"github.com/Haato3o/eskema/core/parser"
+)
 
 type LanguageCodeEmitter interface {
 	Emit(tree *parser.EskemaTree) string
 }
+
+// VariantUnions maps the name of every schema that is a variant to its union,
+// the parser makes sure a schema is a variant of a single union
+func VariantUnions(tree *parser.EskemaTree) map[string]*parser.UnionDefinition {
+	unions := make(map[string]*parser.UnionDefinition)
+
+	for _, declaration := range tree.Declarations {
+		if union, isUnion := declaration.(*parser.UnionDefinition); isUnion {
+			for _, variant := range union.Variants {
+				unions[variant.Name()] = union
+			}
+		}
+	}
+
+	return unions
+}
+
+// ExtendedSchemas lists the schemas another schema extends, languages with
+// inheritance have to leave them open
+func ExtendedSchemas(tree *parser.EskemaTree) map[string]bool {
+	extended := make(map[string]bool)
+
+	for _, declaration := range tree.Declarations {
+		if schema, isSchema := declaration.(*parser.SchemaDefinition); isSchema && schema.Extends != nil {
+			extended[schema.Extends.Id.Name] = true
+		}
+	}
+
+	return extended
+}
+
+// ConstantsName is the type that holds the constants in languages where they
+// can't be declared on their own
+const ConstantsName = "Constants"
+
+// Consts lists the constants of the tree in the order they are declared,
+// languages emit them together before the other declarations
+func Consts(tree *parser.EskemaTree) []*parser.ConstDefinition {
+	consts := make([]*parser.ConstDefinition, 0)
+
+	for _, declaration := range tree.Declarations {
+		if constant, isConst := declaration.(*parser.ConstDefinition); isConst {
+			consts = append(consts, constant)
+		}
+	}
+
+	return consts
+}
+
+type CheckKind int
+
+// Kinds of checks, a value fails the check when it is below or above the bound
+// or doesn't match the pattern
+const (
+	CheckMin CheckKind = iota
+	CheckMax
+	CheckMinLength
+	CheckMaxLength
+	CheckPattern
+)
+
+// Check is a constraint of a field the way validators write it, the bound is a
+// number as it was written or the pattern
+type Check struct {
+	Kind    CheckKind
+	Bound   string
+	Message string
+}
+
+// Checks lists what validators have to check the field for, messages name the
+// field the way it is declared in the schema
+func Checks(tree *parser.EskemaTree, field *parser.FieldExpression) []*Check {
+	constraints := tree.Constraints(field)
+	name := field.Id.Name
+	checks := make([]*Check, 0)
+
+	add := func(kind CheckKind, bound string, message string) {
+		// every length is at least 0, there's nothing to check
+		if bound != "" && !(kind == CheckMinLength && bound == "0") {
+			checks = append(checks, &Check{Kind: kind, Bound: bound, Message: message})
+		}
+	}
+
+	add(CheckMin, constraints.Min, fmt.Sprintf("%s must be at least %s", name, constraints.Min))
+	add(CheckMax, constraints.Max, fmt.Sprintf("%s must be at most %s", name, constraints.Max))
+	add(CheckMinLength, constraints.MinLength, fmt.Sprintf("%s must have a length of at least %s", name, constraints.MinLength))
+	add(CheckMaxLength, constraints.MaxLength, fmt.Sprintf("%s must have a length of at most %s", name, constraints.MaxLength))
+	add(CheckPattern, constraints.Pattern, fmt.Sprintf("%s must match its pattern", name))
+
+	return checks
+}
+
+// HasChecks tells whether any field of the schema, inherited ones included, has
+// to be checked
+func HasChecks(tree *parser.EskemaTree, schema *parser.SchemaDefinition) bool {
+	for _, field := range tree.Fields(schema) {
+		if !tree.Constraints(field).IsEmpty() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// KeyGenerics finds the generics of each schema that end up in the first type
+// argument of the containers, like the keys of a map, which most languages only
+// allow for comparable types. Generics passed on to such a generic of another
+// schema are keys too, so it runs until no generic is added
+func KeyGenerics(tree *parser.EskemaTree, containers ...string) map[string]map[string]bool {
+	keys := make(map[string]map[string]bool)
+	isContainer := make(map[string]bool, len(containers))
+
+	for _, container := range containers {
+		isContainer[container] = true
+	}
+
+	for isChanged := true; isChanged; {
+		isChanged = false
+
+		for _, declaration := range tree.Declarations {
+			schema, isSchema := declaration.(*parser.SchemaDefinition)
+
+			if !isSchema || len(schema.Generics) == 0 {
+				continue
+			}
+
+			if keys[schema.Id.Name] == nil {
+				keys[schema.Id.Name] = make(map[string]bool)
+			}
+
+			for _, field := range tree.Fields(schema) {
+				for _, name := range keysOf(tree, field.Type, isContainer, keys) {
+					if isGenericOf(schema, name) && !keys[schema.Id.Name][name] {
+						keys[schema.Id.Name][name] = true
+						isChanged = true
+					}
+				}
+			}
+		}
+	}
+
+	return keys
+}
+
+// keysOf lists the names the type uses as keys, directly or through the generics
+// of another schema
+func keysOf(tree *parser.EskemaTree, typeExpr *parser.TypeExpression, isContainer map[string]bool, keys map[string]map[string]bool) []string {
+	names := make([]string, 0)
+
+	if isContainer[typeExpr.Id.Name] && len(typeExpr.Generics) > 0 {
+		names = append(names, namesOf(typeExpr.Generics[0])...)
+	}
+
+	if schema := tree.Schema(typeExpr.Id.Name); schema != nil {
+		for i, generic := range schema.Generics {
+			if i < len(typeExpr.Generics) && keys[schema.Id.Name][generic.Id.Name] {
+				names = append(names, namesOf(typeExpr.Generics[i])...)
+			}
+		}
+	}
+
+	for _, generic := range typeExpr.Generics {
+		names = append(names, keysOf(tree, generic, isContainer, keys)...)
+	}
+
+	return names
+}
+
+// namesOf lists every name in the type, a key is only comparable when all of
+// them are
+func namesOf(typeExpr *parser.TypeExpression) []string {
+	names := []string{typeExpr.Id.Name}
+
+	for _, generic := range typeExpr.Generics {
+		names = append(names, namesOf(generic)...)
+	}
+
+	return names
+}
+
+func isGenericOf(schema *parser.SchemaDefinition, name string) bool {
+	for _, generic := range schema.Generics {
+		if generic.Id.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UsedTypes lists the name of every type the tree refers to, type arguments
+// included, languages need it to import the packages of some primitives
+func UsedTypes(tree *parser.EskemaTree) map[string]bool {
+	visitor := &typeCollector{types: make(map[string]bool)}
+	parser.WalkTree(visitor, tree)
+
+	return visitor.types
+}
+
+type typeCollector struct {
+	parser.BaseVisitor
+	types map[string]bool
+}
+
+func (c *typeCollector) VisitSchema(*parser.SchemaDefinition) bool {
+	return true
+}
+
+func (c *typeCollector) VisitEnum(*parser.EnumDefinition) bool {
+	return false
+}
+
+func (c *typeCollector) VisitUnion(*parser.UnionDefinition) bool {
+	return true
+}
+
+func (c *typeCollector) VisitAlias(*parser.AliasDefinition) bool {
+	return true
+}
+
+func (c *typeCollector) VisitConst(*parser.ConstDefinition) bool {
+	return true
+}
+
+func (c *typeCollector) VisitType(typeExpr *parser.TypeExpression) bool {
+	c.types[typeExpr.Id.Name] = true
+
+	return true
+}
@@ -25,32 +25,139 @@ var cSharpPrimitives = map[string]string{
 	"Array":     "List",
 	"Map":       "Dictionary",
 	"Bool":      "bool",
+	"UUID":      "Guid",
+	"Decimal":   "decimal",
+	"Bytes":     "byte[]",
+	"Duration":  "TimeSpan",
+	"Url":       "Uri",
+	"Set":       "HashSet",
+	"Tuple":     "ValueTuple",
 }
 
 type CSharpEmitter struct {
-	buffer strings.Builder
+	parser.BaseVisitor
+	options emitter.Options
+	buffer  strings.Builder
+	tree    *parser.EskemaTree
+	unions  map[string]*parser.UnionDefinition
 }
 
 func (c *CSharpEmitter) Emit(tree *parser.EskemaTree) string {
-	c.buffer.WriteString("namespace Example;\n\n")
+	c.tree = tree
+	c.unions = emitter.VariantUnions(tree)
+
+	hasPatterns := false
+	hasSerialization := len(c.unions) > 0
+	hasStringEnums := false
+
+	for _, declaration := range tree.Declarations {
+		switch node := declaration.(type) {
+		case *parser.SchemaDefinition:
+			for _, field := range node.Fields {
+				hasPatterns = hasPatterns || tree.Constraints(field).Pattern != ""
+			}
+		case *parser.EnumDefinition:
+			kind, hasValues := node.ValueKind()
+			hasStringEnums = hasStringEnums || (hasValues && kind == parser.StringLiteral)
+		}
+	}
+
+	hasSerialization = hasSerialization || hasStringEnums
 
-	for _, expr := range tree.Expr {
-		c.emitExpression(expr)
+	if hasStringEnums {
+		c.buffer.WriteString("using System;\n")
+		c.buffer.WriteString("using System.Runtime.Serialization;\n")
+		c.buffer.WriteString("using System.Text.Json;\n")
+	}
+
+	if hasSerialization {
+		c.buffer.WriteString("using System.Text.Json.Serialization;\n")
+	}
+
+	if hasPatterns {
+		c.buffer.WriteString("using System.Text.RegularExpressions;\n")
+	}
+
+	if hasSerialization || hasPatterns {
+		c.buffer.WriteString("\n")
+	}
+
+	c.buffer.WriteString("namespace ")
+	c.buffer.WriteString(c.options.PackageOr("Example"))
+	c.buffer.WriteString(";\n\n")
+
+	if consts := emitter.Consts(tree); len(consts) > 0 {
+		c.emitConsts(consts)
+		c.buffer.WriteString("\n")
+	}
+
+	for _, declaration := range tree.Declarations {
+		// C# has no aliases outside of a single file, their types are inlined,
+		// and constants were already emitted together
+		switch declaration.(type) {
+		case *parser.AliasDefinition, *parser.ConstDefinition:
+			continue
+		}
+
+		declaration.Accept(c)
 		c.buffer.WriteString("\n")
 	}
 
 	return c.buffer.String()
 }
 
-func (c *CSharpEmitter) emitExpression(expr *parser.EskemaExpression) {
-	switch expr.Type {
-	case parser.SchemaExpr:
-		c.emitSchema(expr.Data.(*parser.SchemaDefinition))
-		break
-	case parser.EnumExpr:
-		c.emitEnum(expr.Data.(*parser.EnumDefinition))
-		break
+func (c *CSharpEmitter) VisitSchema(schema *parser.SchemaDefinition) bool {
+	c.emitSchema(schema)
+
+	return false
+}
+
+func (c *CSharpEmitter) VisitEnum(enum *parser.EnumDefinition) bool {
+	c.emitEnum(enum)
+
+	return false
+}
+
+func (c *CSharpEmitter) VisitUnion(union *parser.UnionDefinition) bool {
+	c.emitUnion(union)
+
+	return false
+}
+
+func (c *CSharpEmitter) VisitAlias(*parser.AliasDefinition) bool {
+	return false
+}
+
+func (c *CSharpEmitter) VisitConst(*parser.ConstDefinition) bool {
+	return false
+}
+
+func (c *CSharpEmitter) emitConsts(consts []*parser.ConstDefinition) {
+	c.buffer.WriteString("public static class ")
+	c.buffer.WriteString(emitter.ConstantsName)
+	c.buffer.WriteString("\n{\n")
+
+	for _, constant := range consts {
+		typeExpr := c.tree.ResolveType(constant.Type)
+		value := constant.Value.Value
+
+		switch {
+		case constant.Value.Kind == parser.StringLiteral:
+			value = cSharpQuote(value)
+		case typeExpr.Id.Name == "Float":
+			value += "f"
+		}
+
+		c.buffer.WriteString(Indent + "public const ")
+		c.emitType(typeExpr)
+		c.buffer.WriteString(" ")
+		c.buffer.WriteString(constant.Id.Name)
+		c.buffer.WriteString(" = ")
+		c.buffer.WriteString(value)
+		c.buffer.WriteString(";\n")
 	}
+
+	c.buffer.WriteString("}\n")
 }
 
 func (c *CSharpEmitter) emitSchema(schema *parser.SchemaDefinition) {
@@ -75,9 +182,11 @@ func (c *CSharpEmitter) emitSchema(schema *parser.SchemaDefinition) {
 
 	c.buffer.WriteString("(\n")
 
-	for i, field := range schema.Fields {
+	fields := c.tree.Fields(schema)
+
+	for i, field := range fields {
 
-		isLast := i+1 == len(schema.Fields)
+		isLast := i+1 == len(fields)
 
 		c.buffer.WriteString(Indent)
 
@@ -90,23 +199,128 @@ func (c *CSharpEmitter) emitSchema(schema *parser.SchemaDefinition) {
 		c.buffer.WriteString("\n")
 	}
 
-	c.buffer.WriteString(");\n")
+	c.buffer.WriteString(")")
+
+	// Records only have a single base, variants inherit from their union and
+	// get the fields of their parent like the included ones
+	union, isVariant := c.unions[schema.Id.Name]
+
+	if isVariant {
+		c.buffer.WriteString(" : ")
+		c.buffer.WriteString(union.Id.Name)
+	} else if schema.Extends != nil {
+		c.emitBase(schema.Extends)
+	}
+
+	if !emitter.HasChecks(c.tree, schema) {
+		c.buffer.WriteString(";\n")
+		return
+	}
+
+	// The base has a Validate of its own when it has constraints, which this
+	// one hides since it checks the inherited fields too
+	modifier := ""
+
+	if parent := schema.Extends; !isVariant && parent != nil {
+		if parentSchema := c.tree.Schema(parent.Id.Name); parentSchema != nil && emitter.HasChecks(c.tree, parentSchema) {
+			modifier = "new "
+		}
+	}
+
+	c.buffer.WriteString("\n{\n")
+	c.emitValidate(fields, modifier)
+	c.buffer.WriteString("}\n")
+}
+
+func (c *CSharpEmitter) emitValidate(fields []*parser.FieldExpression, modifier string) {
+	c.buffer.WriteString(Indent + "public ")
+	c.buffer.WriteString(modifier)
+	c.buffer.WriteString("void Validate()\n")
+	c.buffer.WriteString(Indent + "{\n")
+
+	for _, field := range fields {
+		name := c.options.FieldName(field.Id.Name)
+		guard := ""
+
+		if field.IsOptional {
+			guard = name + " is not null && "
+		}
+
+		length := name + ".Count"
+
+		// Fixed-size arrays are emitted as arrays, which have a Length like strings
+		if resolved := c.tree.ResolveType(field.Type); resolved.Id.Name == "String" || resolved.Size != nil {
+			length = name + ".Length"
+		}
+
+		for _, check := range emitter.Checks(c.tree, field) {
+			c.buffer.WriteString(Indent + Indent + "if (")
+			c.buffer.WriteString(guard)
+
+			switch check.Kind {
+			case emitter.CheckMin:
+				c.buffer.WriteString(name + " < " + check.Bound)
+			case emitter.CheckMax:
+				c.buffer.WriteString(name + " > " + check.Bound)
+			case emitter.CheckMinLength:
+				c.buffer.WriteString(length + " < " + check.Bound)
+			case emitter.CheckMaxLength:
+				c.buffer.WriteString(length + " > " + check.Bound)
+			case emitter.CheckPattern:
+				c.buffer.WriteString("!Regex.IsMatch(" + name + ", @\"" + strings.ReplaceAll(check.Bound, "\"", "\"\"") + "\")")
+			}
+
+			c.buffer.WriteString(") throw new ArgumentException(\"")
+			c.buffer.WriteString(check.Message)
+			c.buffer.WriteString("\", nameof(")
+			c.buffer.WriteString(name)
+			c.buffer.WriteString("));\n")
+		}
+	}
+
+	c.buffer.WriteString(Indent + "}\n")
+}
+
+// emitBase passes the fields the record inherits to the constructor of its base
+func (c *CSharpEmitter) emitBase(parent *parser.TypeExpression) {
+	c.buffer.WriteString(" : ")
+	c.buffer.WriteString(parent.Id.Name)
+	c.buffer.WriteString("(")
+
+	if parentSchema := c.tree.Schema(parent.Id.Name); parentSchema != nil {
+		for i, field := range c.tree.Fields(parentSchema) {
+			if i > 0 {
+				c.buffer.WriteString(", ")
+			}
+
+			c.buffer.WriteString(c.options.FieldName(field.Id.Name))
+		}
+	}
+
+	c.buffer.WriteString(")")
 }
 
 func (c *CSharpEmitter) emitField(field *parser.FieldExpression) {
-	c.emitType(field.Type)
+	c.emitType(c.tree.ResolveType(field.Type))
 
 	if field.IsOptional {
 		c.buffer.WriteString("?")
 	}
 
 	c.buffer.WriteString(" ")
-	c.buffer.WriteString(field.Id.Name)
+	c.buffer.WriteString(c.options.FieldName(field.Id.Name))
 }
 
 func (c *CSharpEmitter) emitType(typeExpr *parser.TypeExpression) {
 	primitive, isPrimitive := cSharpPrimitives[typeExpr.Id.Name]
 
+	if typeExpr.Size != nil {
+		c.emitType(typeExpr.Generics[0])
+		c.buffer.WriteString("[]")
+
+		return
+	}
+
 	if isPrimitive {
 		c.buffer.WriteString(primitive)
 	} else {
@@ -131,15 +345,39 @@ func (c *CSharpEmitter) emitType(typeExpr *parser.TypeExpression) {
 	}
 }
 
+// emitEnum gives the members their explicit values, enums can't hold strings
+// so string values are written and read by a converter generated with the enum,
+// System.Text.Json only reads member names from attributes since .NET 9
 func (c *CSharpEmitter) emitEnum(enum *parser.EnumDefinition) {
-	c.buffer.WriteString("enum ")
+	kind, hasValues := enum.ValueKind()
+	isStringBacked := hasValues && kind == parser.StringLiteral
+
+	if isStringBacked {
+		c.buffer.WriteString("[JsonConverter(typeof(")
+		c.buffer.WriteString(enum.Id.Name)
+		c.buffer.WriteString("JsonConverter))]\n")
+	}
+
+	c.buffer.WriteString("public enum ")
 	c.buffer.WriteString(enum.Id.Name)
 	c.buffer.WriteString(" {\n")
 
 	for i, value := range enum.Values {
 
 		c.buffer.WriteString(Indent)
-		c.emitLiteralValue(value)
+
+		if isStringBacked {
+			c.buffer.WriteString("[EnumMember(Value = ")
+			c.buffer.WriteString(cSharpQuote(value.Value.Value))
+			c.buffer.WriteString(")] ")
+		}
+
+		c.emitLiteralValue(value.Id.Name)
+
+		if hasValues && !isStringBacked {
+			c.buffer.WriteString(" = ")
+			c.buffer.WriteString(value.Value.Value)
+		}
 
 		isLast := i+1 == len(enum.Values)
 
@@ -151,12 +389,71 @@ func (c *CSharpEmitter) emitEnum(enum *parser.EnumDefinition) {
 	}
 
 	c.buffer.WriteString("};\n")
+
+	if isStringBacked {
+		c.buffer.WriteString("\n")
+		c.emitEnumConverter(enum)
+	}
+}
+
+// emitEnumConverter maps the members of a string backed enum to their values
+// and back
+func (c *CSharpEmitter) emitEnumConverter(enum *parser.EnumDefinition) {
+	name := enum.Id.Name
+	unknown := "throw new JsonException($\"unknown " + name + " value '{value}'\")"
+
+	c.buffer.WriteString("public sealed class " + name + "JsonConverter : JsonConverter<" + name + ">\n{\n")
+	c.buffer.WriteString(Indent + "public override " + name + " Read(ref Utf8JsonReader reader, Type typeToConvert, JsonSerializerOptions options) =>\n")
+	c.buffer.WriteString(Indent + Indent + "reader.GetString() switch\n")
+	c.buffer.WriteString(Indent + Indent + "{\n")
+
+	for _, value := range enum.Values {
+		c.buffer.WriteString(Indent + Indent + Indent + cSharpQuote(value.Value.Value) + " => " + name + "." + value.Id.Name + ",\n")
+	}
+
+	c.buffer.WriteString(Indent + Indent + Indent + "var value => " + unknown + ",\n")
+	c.buffer.WriteString(Indent + Indent + "};\n\n")
+	c.buffer.WriteString(Indent + "public override void Write(Utf8JsonWriter writer, " + name + " value, JsonSerializerOptions options) =>\n")
+	c.buffer.WriteString(Indent + Indent + "writer.WriteStringValue(value switch\n")
+	c.buffer.WriteString(Indent + Indent + "{\n")
+
+	for _, value := range enum.Values {
+		c.buffer.WriteString(Indent + Indent + Indent + name + "." + value.Id.Name + " => " + cSharpQuote(value.Value.Value) + ",\n")
+	}
+
+	c.buffer.WriteString(Indent + Indent + Indent + "_ => " + unknown + ",\n")
+	c.buffer.WriteString(Indent + Indent + "});\n")
+	c.buffer.WriteString("}\n")
+}
+
+func cSharpQuote(value string) string {
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(value) + "\""
+}
+
+// emitUnion declares the union as the abstract record its variants inherit
+// from, System.Text.Json picks the variant by the discriminator
+func (c *CSharpEmitter) emitUnion(union *parser.UnionDefinition) {
+	c.buffer.WriteString("[JsonPolymorphic(TypeDiscriminatorPropertyName = \"")
+	c.buffer.WriteString(union.Discriminator.Name)
+	c.buffer.WriteString("\")]\n")
+
+	for _, variant := range union.Variants {
+		c.buffer.WriteString("[JsonDerivedType(typeof(")
+		c.buffer.WriteString(variant.Name())
+		c.buffer.WriteString("), \"")
+		c.buffer.WriteString(variant.Name())
+		c.buffer.WriteString("\")]\n")
+	}
+
+	c.buffer.WriteString("public abstract record ")
+	c.buffer.WriteString(union.Id.Name)
+	c.buffer.WriteString(";\n")
 }
 
 func (c *CSharpEmitter) emitLiteralValue(enum string) {
 	c.buffer.WriteString(enum)
 }
 
-func NewCSharpEmitter() emitter.LanguageCodeEmitter {
-	return &CSharpEmitter{}
+func NewCSharpEmitter(options emitter.Options) emitter.LanguageCodeEmitter {
+	return &CSharpEmitter{options: options}
 }
@@ -0,0 +1,34 @@
+package languages
+
+import (
+	"github.com/Haato3o/eskema/emitter"
+	"strings"
+	"testing"
+)
+
+func TestCSharpEmitterStringEnums(t *testing.T) {
+	tree := parse(t, `enum Status
+{
+    InProgress = "in-progress",
+    Done = "done"
+};
+`)
+	code := NewCSharpEmitter(emitter.Options{}).Emit(tree)
+
+	for _, expected := range []string{
+		"[JsonConverter(typeof(StatusJsonConverter))]\npublic enum Status {",
+		`[EnumMember(Value = "in-progress")] InProgress,`,
+		"public sealed class StatusJsonConverter : JsonConverter<Status>",
+		`"in-progress" => Status.InProgress,`,
+		`Status.InProgress => "in-progress",`,
+	} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("got\n%s\nexpected it to contain %s", code, expected)
+		}
+	}
+
+	// the attribute only exists since .NET 9
+	if strings.Contains(code, "JsonStringEnumMemberName") {
+		t.Errorf("got\n%s\nexpected it to build on .NET 8", code)
+	}
+}
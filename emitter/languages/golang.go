@@ -1,8 +1,11 @@
 package languages
 
 import (
+	"github.com/Haato3o/eskema/core/codestyle"
 	"github.com/Haato3o/eskema/core/parser"
 	"github.com/Haato3o/eskema/emitter"
+	"sort"
+	"strconv"
 	"strings"
 )
 
@@ -17,42 +20,177 @@ var goLangPrimitives = map[string]string{
 	"Int16":     "int16",
 	"Int32":     "int32",
 	"Int64":     "int64",
-	"Float":     "float",
-	"Double":    "double",
+	"Float":     "float32",
+	"Double":    "float64",
 	"TimeStamp": "time.Time",
 	"Date":      "time.Time",
 	"DateTime":  "time.Time",
 	"Array":     "[]",
 	"Map":       "map",
 	"Bool":      "bool",
+	"UUID":      "string",
+	"Decimal":   "json.Number",
+	"Bytes":     "[]byte",
+	"Duration":  "time.Duration",
+	"Url":       "string",
+	"Set":       "map",
+	"Tuple":     "struct",
+}
+
+// goLangPackages are the import paths of the packages primitives are qualified with
+var goLangPackages = map[string]string{
+	"time": "time",
+	"json": "encoding/json",
 }
 
 type GoLangEmitter struct {
-	buffer strings.Builder
+	parser.BaseVisitor
+	options emitter.Options
+	buffer  strings.Builder
+	tree    *parser.EskemaTree
+	// keys are the generics of each schema used as map keys or set elements,
+	// which have to be comparable
+	keys map[string]map[string]bool
 }
 
 func (g *GoLangEmitter) Emit(tree *parser.EskemaTree) string {
-	g.buffer.WriteString("package example\n\n")
+	g.tree = tree
+	g.keys = emitter.KeyGenerics(tree, "Map", "Set")
+
+	g.buffer.WriteString("package ")
+	g.buffer.WriteString(g.options.PackageOr("example"))
+	g.buffer.WriteString("\n\n")
+
+	if imports := g.imports(); len(imports) > 0 {
+		g.buffer.WriteString("import (\n")
+
+		for _, path := range imports {
+			g.buffer.WriteString(Indent + "\"")
+			g.buffer.WriteString(path)
+			g.buffer.WriteString("\"\n")
+		}
+
+		g.buffer.WriteString(")\n\n")
+	}
+
+	if consts := emitter.Consts(tree); len(consts) > 0 {
+		g.emitConsts(consts)
+		g.buffer.WriteString("\n")
+	}
+
+	for _, declaration := range tree.Declarations {
+		// constants were already emitted together
+		if _, isConst := declaration.(*parser.ConstDefinition); isConst {
+			continue
+		}
 
-	for _, expr := range tree.Expr {
-		g.emitExpression(expr)
+		declaration.Accept(g)
 		g.buffer.WriteString("\n")
 	}
 
 	return g.buffer.String()
 }
 
-func (g *GoLangEmitter) emitExpression(expr *parser.EskemaExpression) {
-	switch expr.Type {
-	case parser.SchemaExpr:
-		g.emitSchema(expr.Data.(*parser.SchemaDefinition))
-		break
-	case parser.EnumExpr:
-		g.emitEnum(expr.Data.(*parser.EnumDefinition))
-		break
-	default:
-		break
+func (g *GoLangEmitter) VisitConst(*parser.ConstDefinition) bool {
+	return false
+}
+
+func (g *GoLangEmitter) emitConsts(consts []*parser.ConstDefinition) {
+	g.buffer.WriteString("const (\n")
+
+	for _, constant := range consts {
+		g.buffer.WriteString(Indent)
+		g.buffer.WriteString(constant.Id.Name)
+		g.buffer.WriteString(" ")
+		g.emitType(constant.Type)
+		g.buffer.WriteString(" = ")
+		g.buffer.WriteString(goLiteral(constant.Value))
+		g.buffer.WriteString("\n")
 	}
+
+	g.buffer.WriteString(")\n")
+}
+
+// imports lists the packages used by the unions, validators and primitives of
+// the tree
+func (g *GoLangEmitter) imports() []string {
+	imported := make(map[string]bool)
+
+	if len(emitter.VariantUnions(g.tree)) > 0 {
+		imported["encoding/json"] = true
+		imported["fmt"] = true
+	}
+
+	for name := range emitter.UsedTypes(g.tree) {
+		if pkg, _, isQualified := strings.Cut(goLangPrimitives[name], "."); isQualified {
+			imported[goLangPackages[pkg]] = true
+		}
+	}
+
+	for _, declaration := range g.tree.Declarations {
+		schema, isSchema := declaration.(*parser.SchemaDefinition)
+
+		if !isSchema {
+			continue
+		}
+
+		for _, field := range g.tree.Fields(schema) {
+			for _, check := range emitter.Checks(g.tree, field) {
+				imported["errors"] = true
+
+				switch {
+				case check.Kind == emitter.CheckPattern:
+					imported["regexp"] = true
+				case check.Kind != emitter.CheckMin && check.Kind != emitter.CheckMax && g.tree.ResolveType(field.Type).Id.Name == "String":
+					imported["unicode/utf8"] = true
+				}
+			}
+		}
+	}
+
+	imports := make([]string, 0, len(imported))
+
+	for path := range imported {
+		imports = append(imports, path)
+	}
+
+	sort.Strings(imports)
+
+	return imports
+}
+
+func (g *GoLangEmitter) VisitSchema(schema *parser.SchemaDefinition) bool {
+	g.emitSchema(schema)
+
+	if emitter.HasChecks(g.tree, schema) {
+		g.emitValidate(schema)
+	}
+
+	return false
+}
+
+func (g *GoLangEmitter) VisitEnum(enum *parser.EnumDefinition) bool {
+	g.emitEnum(enum)
+
+	return false
+}
+
+func (g *GoLangEmitter) VisitUnion(union *parser.UnionDefinition) bool {
+	g.emitUnion(union)
+
+	return false
+}
+
+// VisitAlias declares a named type, values of the type it stands for have to be
+// converted explicitly
+func (g *GoLangEmitter) VisitAlias(alias *parser.AliasDefinition) bool {
+	g.buffer.WriteString("type ")
+	g.buffer.WriteString(alias.Id.Name)
+	g.buffer.WriteString(" ")
+	g.emitType(alias.Type)
+	g.buffer.WriteString("\n")
+
+	return false
 }
 
 func (g *GoLangEmitter) emitSchema(schema *parser.SchemaDefinition) {
@@ -66,7 +204,12 @@ func (g *GoLangEmitter) emitSchema(schema *parser.SchemaDefinition) {
 			isLast := i+1 == len(schema.Generics)
 
 			g.emitType(generic)
-			g.buffer.WriteString(" any")
+
+			if g.keys[schema.Id.Name][generic.Id.Name] {
+				g.buffer.WriteString(" comparable")
+			} else {
+				g.buffer.WriteString(" any")
+			}
 
 			if !isLast {
 				g.buffer.WriteString(", ")
@@ -80,6 +223,14 @@ func (g *GoLangEmitter) emitSchema(schema *parser.SchemaDefinition) {
 
 	g.buffer.WriteString(" {\n")
 
+	// Parents are embedded, encoding/json flattens the fields of embedded
+	// structs the same way the schema inherits them
+	for _, parent := range schema.Parents() {
+		g.buffer.WriteString(Indent)
+		g.buffer.WriteString(parent.Id.Name)
+		g.buffer.WriteString("\n")
+	}
+
 	for _, field := range schema.Fields {
 		g.buffer.WriteString(Indent)
 
@@ -91,8 +242,117 @@ func (g *GoLangEmitter) emitSchema(schema *parser.SchemaDefinition) {
 	g.buffer.WriteString("}\n")
 }
 
+// emitValidate checks every constrained field, inherited ones are reached
+// through the embedded parents
+func (g *GoLangEmitter) emitValidate(schema *parser.SchemaDefinition) {
+	receiver := strings.ToLower(schema.Id.Name[:1])
+	fields := g.tree.Fields(schema)
+
+	for _, field := range fields {
+		if pattern := g.tree.Constraints(field).Pattern; pattern != "" {
+			g.buffer.WriteString("\nvar ")
+			g.buffer.WriteString(g.patternName(schema, field))
+			g.buffer.WriteString(" = regexp.MustCompile(")
+			g.buffer.WriteString(goQuote(pattern))
+			g.buffer.WriteString(")\n")
+		}
+	}
+
+	g.buffer.WriteString("\n// Validate checks the fields of ")
+	g.buffer.WriteString(schema.Id.Name)
+	g.buffer.WriteString(" against their constraints\n")
+	g.buffer.WriteString("func (")
+	g.buffer.WriteString(receiver)
+	g.buffer.WriteString(" *")
+	g.buffer.WriteString(schema.Id.Name)
+
+	if len(schema.Generics) > 0 {
+		g.buffer.WriteString("[")
+
+		for i, generic := range schema.Generics {
+			if i > 0 {
+				g.buffer.WriteString(", ")
+			}
+
+			g.buffer.WriteString(generic.Id.Name)
+		}
+
+		g.buffer.WriteString("]")
+	}
+
+	g.buffer.WriteString(") Validate() error {\n")
+
+	for _, field := range fields {
+		value := receiver + "." + goFieldName(field)
+		guard := ""
+
+		if field.IsOptional {
+			guard = value + " != nil && "
+			value = "*" + value
+		}
+
+		typeName := g.tree.ResolveType(field.Type).Id.Name
+
+		// Named types of strings have to be converted to be counted and matched
+		text := value
+
+		if typeName != field.Type.Id.Name {
+			text = "string(" + value + ")"
+		}
+
+		length := "len(" + value + ")"
+
+		if typeName == "String" {
+			length = "utf8.RuneCountInString(" + text + ")"
+		}
+
+		for _, check := range emitter.Checks(g.tree, field) {
+			g.buffer.WriteString(Indent + "if ")
+			g.buffer.WriteString(guard)
+
+			switch check.Kind {
+			case emitter.CheckMin:
+				g.buffer.WriteString(value + " < " + check.Bound)
+			case emitter.CheckMax:
+				g.buffer.WriteString(value + " > " + check.Bound)
+			case emitter.CheckMinLength:
+				g.buffer.WriteString(length + " < " + check.Bound)
+			case emitter.CheckMaxLength:
+				g.buffer.WriteString(length + " > " + check.Bound)
+			case emitter.CheckPattern:
+				g.buffer.WriteString("!" + g.patternName(schema, field) + ".MatchString(" + text + ")")
+			}
+
+			g.buffer.WriteString(" {\n")
+			g.buffer.WriteString(Indent + Indent + "return errors.New(")
+			g.buffer.WriteString(strconv.Quote(check.Message))
+			g.buffer.WriteString(")\n")
+			g.buffer.WriteString(Indent + "}\n\n")
+		}
+	}
+
+	g.buffer.WriteString(Indent + "return nil\n")
+	g.buffer.WriteString("}\n")
+}
+
+// patternName is the variable the pattern of the field is compiled to once
+func (g *GoLangEmitter) patternName(schema *parser.SchemaDefinition, field *parser.FieldExpression) string {
+	return codestyle.ToCamelCase(schema.Id.Name) + codestyle.ToPascalCase(field.Id.Name) + "Pattern"
+}
+
+// goQuote prefers raw strings, which patterns rarely need escapes in
+func goQuote(value string) string {
+	if strings.Contains(value, "`") {
+		return strconv.Quote(value)
+	}
+
+	return "`" + value + "`"
+}
+
+// emitField exports the field so encoding/json can read and write it, the name
+// it has on the wire is kept in its tag
 func (g *GoLangEmitter) emitField(field *parser.FieldExpression) {
-	g.buffer.WriteString(field.Id.Name)
+	g.buffer.WriteString(goFieldName(field))
 	g.buffer.WriteString(" ")
 
 	if field.IsOptional {
@@ -100,38 +360,98 @@ func (g *GoLangEmitter) emitField(field *parser.FieldExpression) {
 	}
 
 	g.emitType(field.Type)
+	g.buffer.WriteString(" `json:\"")
+	g.buffer.WriteString(g.options.FieldName(field.Id.Name))
+	g.buffer.WriteString("\"`")
+}
+
+func goFieldName(field *parser.FieldExpression) string {
+	return codestyle.ToPascalCase(field.Id.Name)
 }
 
 func (g *GoLangEmitter) emitType(typeExpr *parser.TypeExpression) {
 	primitive, isPrimitive := goLangPrimitives[typeExpr.Id.Name]
 
+	switch {
+	case typeExpr.Size != nil:
+		g.buffer.WriteString("[" + typeExpr.Size.Value + "]")
+		g.emitType(typeExpr.Generics[0])
+
+		return
+	case typeExpr.Id.Name == "Set":
+		g.buffer.WriteString("map[")
+		g.emitType(typeExpr.Generics[0])
+		g.buffer.WriteString("]struct{}")
+
+		return
+	case typeExpr.Id.Name == "Tuple":
+		g.emitTuple(typeExpr)
+
+		return
+	}
+
 	if isPrimitive {
 		g.buffer.WriteString(primitive)
 	} else {
 		g.buffer.WriteString(typeExpr.Id.Name)
 	}
 
-	for i, typ := range typeExpr.Generics {
-		isFirst := i == 0
-		hasMultipleElements := len(typeExpr.Generics) > 1
-		isArray := typeExpr.Id.Name == "Array"
+	switch {
+	case typeExpr.Id.Name == "Array":
+		g.emitType(typeExpr.Generics[0])
+	case typeExpr.Id.Name == "Map":
+		g.buffer.WriteString("[")
+		g.emitType(typeExpr.Generics[0])
+		g.buffer.WriteString("]")
+		g.emitType(typeExpr.Generics[1])
+	case len(typeExpr.Generics) > 0:
+		// type arguments of generic schemas
+		g.buffer.WriteString("[")
 
-		if isFirst && (hasMultipleElements || !isArray) {
-			g.buffer.WriteString("[")
+		for i, typ := range typeExpr.Generics {
+			if i > 0 {
+				g.buffer.WriteString(", ")
+			}
+
+			g.emitType(typ)
 		}
 
-		g.emitType(typ)
+		g.buffer.WriteString("]")
+	}
+}
+
+// tupleFields name the elements of a tuple, Go has no tuples so they are
+// anonymous structs
+var tupleFields = []string{"First", "Second", "Third"}
+
+func (g *GoLangEmitter) emitTuple(typeExpr *parser.TypeExpression) {
+	g.buffer.WriteString("struct {")
 
-		if isFirst && (hasMultipleElements || !isArray) {
-			g.buffer.WriteString("]")
+	for i, typ := range typeExpr.Generics {
+		if i > 0 {
+			g.buffer.WriteString(";")
 		}
+
+		g.buffer.WriteString(" " + tupleFields[i] + " ")
+		g.emitType(typ)
 	}
+
+	g.buffer.WriteString(" }")
 }
 
+// emitEnum numbers the members in order unless they have explicit values, which
+// are kept when members are reordered
 func (g *GoLangEmitter) emitEnum(enum *parser.EnumDefinition) {
+	kind, hasValues := enum.ValueKind()
+
 	g.buffer.WriteString("type ")
 	g.buffer.WriteString(enum.Id.Name)
-	g.buffer.WriteString(" int\n")
+
+	if hasValues && kind == parser.StringLiteral {
+		g.buffer.WriteString(" string\n")
+	} else {
+		g.buffer.WriteString(" int\n")
+	}
 
 	g.buffer.WriteString("const (\n")
 
@@ -140,9 +460,15 @@ func (g *GoLangEmitter) emitEnum(enum *parser.EnumDefinition) {
 		isFirst := i == 0
 
 		g.buffer.WriteString(Indent)
-		g.emitLiteralValue(value)
+		g.emitLiteralValue(value.Id.Name)
 
-		if isFirst {
+		switch {
+		case hasValues:
+			g.buffer.WriteString(" ")
+			g.buffer.WriteString(enum.Id.Name)
+			g.buffer.WriteString(" = ")
+			g.buffer.WriteString(goLiteral(value.Value))
+		case isFirst:
 			g.buffer.WriteString(" ")
 			g.buffer.WriteString(enum.Id.Name)
 			g.buffer.WriteString(" = iota")
@@ -154,10 +480,134 @@ func (g *GoLangEmitter) emitEnum(enum *parser.EnumDefinition) {
 	g.buffer.WriteString(")\n")
 }
 
+func goLiteral(literal *parser.LiteralExpression) string {
+	if literal.Kind == parser.StringLiteral {
+		return strconv.Quote(literal.Value)
+	}
+
+	return literal.Value
+}
+
+// emitUnion declares the union as an interface only its variants implement,
+// encoding/json can't tell the variants of an interface apart so it comes
+// with functions that read and write the discriminator
+func (g *GoLangEmitter) emitUnion(union *parser.UnionDefinition) {
+	name := union.Id.Name
+	marker := "is" + name
+
+	g.buffer.WriteString("type ")
+	g.buffer.WriteString(name)
+	g.buffer.WriteString(" interface {\n")
+	g.buffer.WriteString(Indent)
+	g.buffer.WriteString(marker)
+	g.buffer.WriteString("()\n")
+	g.buffer.WriteString("}\n\n")
+
+	for _, variant := range union.Variants {
+		g.buffer.WriteString("func (")
+		g.buffer.WriteString(variant.Name())
+		g.buffer.WriteString(") ")
+		g.buffer.WriteString(marker)
+		g.buffer.WriteString("() {}\n")
+	}
+
+	g.emitUnionMarshaler(union)
+	g.emitUnionUnmarshaler(union)
+}
+
+func (g *GoLangEmitter) emitUnionMarshaler(union *parser.UnionDefinition) {
+	name := union.Id.Name
+
+	g.buffer.WriteString("\n// Marshal")
+	g.buffer.WriteString(name)
+	g.buffer.WriteString(" writes the variant with its name in the \"")
+	g.buffer.WriteString(union.Discriminator.Name)
+	g.buffer.WriteString("\" field\n")
+	g.buffer.WriteString("func Marshal")
+	g.buffer.WriteString(name)
+	g.buffer.WriteString("(value ")
+	g.buffer.WriteString(name)
+	g.buffer.WriteString(") ([]byte, error) {\n")
+	g.buffer.WriteString(Indent + "var discriminator string\n\n")
+	g.buffer.WriteString(Indent + "switch value.(type) {\n")
+
+	for _, variant := range union.Variants {
+		g.buffer.WriteString(Indent + "case ")
+		g.buffer.WriteString(variant.Name())
+		g.buffer.WriteString(", *")
+		g.buffer.WriteString(variant.Name())
+		g.buffer.WriteString(":\n")
+		g.buffer.WriteString(Indent + Indent + "discriminator = \"")
+		g.buffer.WriteString(variant.Name())
+		g.buffer.WriteString("\"\n")
+	}
+
+	g.buffer.WriteString(Indent + "default:\n")
+	g.buffer.WriteString(Indent + Indent + "return nil, fmt.Errorf(\"unknown ")
+	g.buffer.WriteString(name)
+	g.buffer.WriteString(" variant %T\", value)\n")
+	g.buffer.WriteString(Indent + "}\n\n")
+	g.buffer.WriteString(Indent + "data, err := json.Marshal(value)\n\n")
+	g.buffer.WriteString(Indent + "if err != nil {\n")
+	g.buffer.WriteString(Indent + Indent + "return nil, err\n")
+	g.buffer.WriteString(Indent + "}\n\n")
+	g.buffer.WriteString(Indent + "fields := make(map[string]json.RawMessage)\n\n")
+	g.buffer.WriteString(Indent + "if err := json.Unmarshal(data, &fields); err != nil {\n")
+	g.buffer.WriteString(Indent + Indent + "return nil, err\n")
+	g.buffer.WriteString(Indent + "}\n\n")
+	g.buffer.WriteString(Indent + "fields[\"")
+	g.buffer.WriteString(union.Discriminator.Name)
+	g.buffer.WriteString("\"], _ = json.Marshal(discriminator)\n\n")
+	g.buffer.WriteString(Indent + "return json.Marshal(fields)\n")
+	g.buffer.WriteString("}\n")
+}
+
+func (g *GoLangEmitter) emitUnionUnmarshaler(union *parser.UnionDefinition) {
+	name := union.Id.Name
+
+	g.buffer.WriteString("\n// Unmarshal")
+	g.buffer.WriteString(name)
+	g.buffer.WriteString(" reads the variant named by the \"")
+	g.buffer.WriteString(union.Discriminator.Name)
+	g.buffer.WriteString("\" field\n")
+	g.buffer.WriteString("func Unmarshal")
+	g.buffer.WriteString(name)
+	g.buffer.WriteString("(data []byte) (")
+	g.buffer.WriteString(name)
+	g.buffer.WriteString(", error) {\n")
+	g.buffer.WriteString(Indent + "var header struct {\n")
+	g.buffer.WriteString(Indent + Indent + "Discriminator string `json:\"")
+	g.buffer.WriteString(union.Discriminator.Name)
+	g.buffer.WriteString("\"`\n")
+	g.buffer.WriteString(Indent + "}\n\n")
+	g.buffer.WriteString(Indent + "if err := json.Unmarshal(data, &header); err != nil {\n")
+	g.buffer.WriteString(Indent + Indent + "return nil, err\n")
+	g.buffer.WriteString(Indent + "}\n\n")
+	g.buffer.WriteString(Indent + "switch header.Discriminator {\n")
+
+	for _, variant := range union.Variants {
+		g.buffer.WriteString(Indent + "case \"")
+		g.buffer.WriteString(variant.Name())
+		g.buffer.WriteString("\":\n")
+		g.buffer.WriteString(Indent + Indent + "var value ")
+		g.buffer.WriteString(variant.Name())
+		g.buffer.WriteString("\n")
+		g.buffer.WriteString(Indent + Indent + "err := json.Unmarshal(data, &value)\n")
+		g.buffer.WriteString(Indent + Indent + "return value, err\n")
+	}
+
+	g.buffer.WriteString(Indent + "default:\n")
+	g.buffer.WriteString(Indent + Indent + "return nil, fmt.Errorf(\"unknown ")
+	g.buffer.WriteString(name)
+	g.buffer.WriteString(" variant %q\", header.Discriminator)\n")
+	g.buffer.WriteString(Indent + "}\n")
+	g.buffer.WriteString("}\n")
+}
+
 func (g *GoLangEmitter) emitLiteralValue(enum string) {
 	g.buffer.WriteString(enum)
 }
 
-func NewGoLangEmitter() emitter.LanguageCodeEmitter {
-	return &GoLangEmitter{}
+func NewGoLangEmitter(options emitter.Options) emitter.LanguageCodeEmitter {
+	return &GoLangEmitter{options: options}
 }
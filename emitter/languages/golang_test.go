@@ -0,0 +1,168 @@
+package languages
+
+import (
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/syntax"
+	"github.com/Haato3o/eskema/emitter"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func parse(t *testing.T, source string) *parser.EskemaTree {
+	eskemaParser := parser.New(syntax.NewLexer([]byte(source), "test.skm").Lex())
+	tree := eskemaParser.Parse()
+
+	if errors := eskemaParser.Errors(); len(errors) > 0 {
+		t.Fatalf("got %v, expected the schema to parse", errors)
+	}
+
+	return tree
+}
+
+func emitGo(t *testing.T, source string) string {
+	return NewGoLangEmitter(emitter.Options{}).Emit(parse(t, source))
+}
+
+// goRun builds the generated code as the package of a temporary module along
+// with the files given and runs their tests
+func goRun(t *testing.T, code string, files map[string]string) string {
+	goBinary, err := exec.LookPath("go")
+
+	if err != nil {
+		t.Skip("go isn't installed")
+	}
+
+	directory := t.TempDir()
+	files["go.mod"] = "module example\n\ngo 1.19\n"
+	files["example.go"] = code
+
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(directory, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	command := exec.Command(goBinary, "vet", ".")
+	command.Dir = directory
+
+	if output, err := command.CombinedOutput(); err != nil {
+		t.Fatalf("got %s, expected the generated code to compile:\n%s", output, code)
+	}
+
+	command = exec.Command(goBinary, "test", ".")
+	command.Dir = directory
+	output, err := command.CombinedOutput()
+
+	if err != nil {
+		t.Fatalf("got %s, expected the tests of the generated code to pass:\n%s", output, code)
+	}
+
+	return string(output)
+}
+
+func TestGoLangEmitterComparableGenerics(t *testing.T) {
+	code := emitGo(t, `schema Index<K, V>
+{
+    entries: Map<K, V>
+};
+
+schema Tags<T>
+{
+    values: Set<T>
+};
+
+schema Catalog<Id, Name>
+{
+    byId: Index<Id, Name>,
+    names: Array<Name>
+};
+`)
+
+	for _, expected := range []string{"Index[K comparable, V any]", "Tags[T comparable]", "Catalog[Id comparable, Name any]"} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("got\n%s\nexpected it to contain %s", code, expected)
+		}
+	}
+
+	goRun(t, code, map[string]string{})
+}
+
+func TestGoLangEmitterUnionRoundTrip(t *testing.T) {
+	code := emitGo(t, `schema Card
+{
+    number: String,
+    expiresAt: Int32?
+};
+
+schema BankTransfer
+{
+    iban: String
+};
+
+union PaymentMethod: type
+{
+    Card,
+    BankTransfer
+};
+`)
+
+	goRun(t, code, map[string]string{"example_test.go": `package example
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	expiresAt := int32(2030)
+	data, err := MarshalPaymentMethod(Card{Number: "4242", ExpiresAt: &expiresAt})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fields := make(map[string]interface{})
+
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+
+	if fields["type"] != "Card" || fields["number"] != "4242" || fields["expiresAt"] != float64(2030) {
+		t.Errorf("got %s, expected the discriminator and every field", data)
+	}
+
+	value, err := UnmarshalPaymentMethod(data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if card, isCard := value.(Card); !isCard || card.Number != "4242" || card.ExpiresAt == nil || *card.ExpiresAt != 2030 {
+		t.Errorf("got %+v, expected the card to keep its fields", value)
+	}
+}
+`})
+}
+
+func TestGoLangEmitterFloatingPointConsts(t *testing.T) {
+	code := emitGo(t, `const RATIO: Double = 0.5;
+const SCALE: Float = 1.25;
+
+schema Measure
+{
+    value: Double,
+    weight: Float
+};
+`)
+
+	for _, expected := range []string{"RATIO float64 = 0.5", "SCALE float32 = 1.25", "Value float64", "Weight float32"} {
+		if !strings.Contains(code, expected) {
+			t.Errorf("got\n%s\nexpected it to contain %s", code, expected)
+		}
+	}
+
+	goRun(t, code, map[string]string{})
+}
@@ -3,11 +3,16 @@ package languages
 import (
 	"github.com/Haato3o/eskema/core/parser"
 	"github.com/Haato3o/eskema/emitter"
+	"strconv"
 	"strings"
 )
 
 const Indent = "    "
 
+// KotlinSerializable annotates the generated classes for kotlinx.serialization,
+// fields renamed by the naming style keep their name on the wire
+const KotlinSerializable = "serializable"
+
 var ktPrimitives = map[string]string{
 	"String":    "String",
 	"Char":      "Char",
@@ -27,38 +32,160 @@ var ktPrimitives = map[string]string{
 	"Array":     "List",
 	"Map":       "Map",
 	"Bool":      "Boolean",
+	"UUID":      "java.util.UUID",
+	"Decimal":   "java.math.BigDecimal",
+	"Bytes":     "ByteArray",
+	"Duration":  "kotlin.time.Duration",
+	"Url":       "java.net.URI",
+	"Set":       "Set",
+	"Tuple":     "Pair",
 }
 
 type KotlinEmitter struct {
-	buffer strings.Builder
+	parser.BaseVisitor
+	options  emitter.Options
+	buffer   strings.Builder
+	tree     *parser.EskemaTree
+	unions   map[string]*parser.UnionDefinition
+	extended map[string]bool
 }
 
 func (k *KotlinEmitter) Emit(tree *parser.EskemaTree) string {
-	k.buffer.WriteString("package com.example\n\n")
+	k.buffer.WriteString("package ")
+	k.buffer.WriteString(k.options.PackageOr("com.example"))
+	k.buffer.WriteString("\n\n")
+
+	k.tree = tree
+	k.unions = emitter.VariantUnions(tree)
+	k.extended = emitter.ExtendedSchemas(tree)
+
+	if k.options.IsEnabled(KotlinSerializable) {
+		// unions are told apart by their own discriminator, which kotlinx
+		// only lets classes choose through an experimental annotation
+		if len(k.unions) > 0 {
+			k.buffer.WriteString("import kotlinx.serialization.ExperimentalSerializationApi\n")
+		}
+
+		k.buffer.WriteString("import kotlinx.serialization.SerialName\n")
+		k.buffer.WriteString("import kotlinx.serialization.Serializable\n")
+
+		if len(k.unions) > 0 {
+			k.buffer.WriteString("import kotlinx.serialization.json.JsonClassDiscriminator\n")
+		}
+
+		k.buffer.WriteString("\n")
+	}
 
-	for _, expr := range tree.Expr {
-		k.emitExpression(expr)
+	if consts := emitter.Consts(tree); len(consts) > 0 {
+		k.emitConsts(consts)
+		k.buffer.WriteString("\n")
+	}
+
+	for _, declaration := range tree.Declarations {
+		// constants were already emitted together
+		if _, isConst := declaration.(*parser.ConstDefinition); isConst {
+			continue
+		}
+
+		declaration.Accept(k)
 		k.buffer.WriteString("\n")
 	}
 
 	return k.buffer.String()
 }
 
-func (k *KotlinEmitter) emitExpression(expr *parser.EskemaExpression) {
-	switch expr.Type {
-	case parser.SchemaExpr:
-		k.emitSchema(expr.Data.(*parser.SchemaDefinition))
-		break
-	case parser.EnumExpr:
-		k.emitEnum(expr.Data.(*parser.EnumDefinition))
-		break
-	default:
-		break
+func (k *KotlinEmitter) VisitConst(*parser.ConstDefinition) bool {
+	return false
+}
+
+func (k *KotlinEmitter) emitConsts(consts []*parser.ConstDefinition) {
+	k.buffer.WriteString("object ")
+	k.buffer.WriteString(emitter.ConstantsName)
+	k.buffer.WriteString(" {\n")
+
+	for _, constant := range consts {
+		k.buffer.WriteString(Indent + "const val ")
+		k.buffer.WriteString(constant.Id.Name)
+		k.buffer.WriteString(": ")
+		k.emitType(constant.Type)
+		k.buffer.WriteString(" = ")
+		k.buffer.WriteString(k.constValue(constant))
+		k.buffer.WriteString("\n")
+	}
+
+	k.buffer.WriteString("}\n")
+}
+
+// constValue writes numbers as literals of the type of the constant, Kotlin
+// doesn't convert integer literals to floating point ones
+func (k *KotlinEmitter) constValue(constant *parser.ConstDefinition) string {
+	value := constant.Value
+
+	if value.Kind == parser.StringLiteral {
+		return kotlinQuote(value.Value)
+	}
+
+	switch typeName := k.tree.ResolveType(constant.Type).Id.Name; {
+	case strings.HasPrefix(typeName, "UInt"):
+		return value.Value + "u"
+	case typeName == "Float":
+		return value.Value + "f"
+	case typeName == "Double" && !strings.ContainsAny(value.Value, ".eE"):
+		return value.Value + ".0"
 	}
+
+	return value.Value
+}
+
+func (k *KotlinEmitter) VisitSchema(schema *parser.SchemaDefinition) bool {
+	k.emitSchema(schema)
+
+	return false
+}
+
+func (k *KotlinEmitter) VisitEnum(enum *parser.EnumDefinition) bool {
+	k.emitEnum(enum)
+
+	return false
+}
+
+func (k *KotlinEmitter) VisitUnion(union *parser.UnionDefinition) bool {
+	k.emitUnion(union)
+
+	return false
+}
+
+func (k *KotlinEmitter) VisitAlias(alias *parser.AliasDefinition) bool {
+	k.buffer.WriteString("typealias ")
+	k.buffer.WriteString(alias.Id.Name)
+	k.buffer.WriteString(" = ")
+	k.emitType(alias.Type)
+	k.buffer.WriteString("\n")
+
+	return false
 }
 
 func (k *KotlinEmitter) emitSchema(schema *parser.SchemaDefinition) {
-	k.buffer.WriteString("data class ")
+	union, isVariant := k.unions[schema.Id.Name]
+
+	k.emitSerializable()
+
+	if isVariant && k.options.IsEnabled(KotlinSerializable) {
+		k.buffer.WriteString("@SerialName(\"")
+		k.buffer.WriteString(schema.Id.Name)
+		k.buffer.WriteString("\")\n")
+	}
+
+	// Data classes can't be inherited from, schemas other schemas extend are
+	// open classes instead
+	isOpen := k.extended[schema.Id.Name]
+
+	if isOpen {
+		k.buffer.WriteString("open class ")
+	} else {
+		k.buffer.WriteString("data class ")
+	}
+
 	k.buffer.WriteString(schema.Id.Name)
 
 	if len(schema.Generics) > 0 {
@@ -79,13 +206,38 @@ func (k *KotlinEmitter) emitSchema(schema *parser.SchemaDefinition) {
 
 	k.buffer.WriteString("(\n")
 
-	for i, field := range schema.Fields {
+	// The fields of the parent are overridden and passed on to its constructor,
+	// the included ones are copied
+	parentFields := make([]*parser.FieldExpression, 0)
+
+	if schema.Extends != nil {
+		if parent := k.tree.Schema(schema.Extends.Id.Name); parent != nil {
+			parentFields = k.tree.Fields(parent)
+		}
+	}
+
+	isOverride := make(map[*parser.FieldExpression]bool, len(parentFields))
+
+	for _, field := range parentFields {
+		isOverride[field] = true
+	}
+
+	fields := k.tree.Fields(schema)
 
-		isLast := i+1 == len(schema.Fields)
+	for i, field := range fields {
+
+		isLast := i+1 == len(fields)
 
 		k.buffer.WriteString(Indent)
 
-		k.emitField(field)
+		switch {
+		case isOverride[field]:
+			k.emitField(field, "override ")
+		case isOpen:
+			k.emitField(field, "open ")
+		default:
+			k.emitField(field, "")
+		}
 
 		if !isLast {
 			k.buffer.WriteString(",")
@@ -94,12 +246,110 @@ func (k *KotlinEmitter) emitSchema(schema *parser.SchemaDefinition) {
 		k.buffer.WriteString("\n")
 	}
 
-	k.buffer.WriteString(")\n")
+	k.buffer.WriteString(")")
+
+	supertypes := make([]string, 0, 2)
+
+	if schema.Extends != nil {
+		arguments := make([]string, 0, len(parentFields))
+
+		for _, field := range parentFields {
+			arguments = append(arguments, k.options.FieldName(field.Id.Name))
+		}
+
+		supertypes = append(supertypes, schema.Extends.Id.Name+"("+strings.Join(arguments, ", ")+")")
+	}
+
+	if isVariant {
+		supertypes = append(supertypes, union.Id.Name)
+	}
+
+	if len(supertypes) > 0 {
+		k.buffer.WriteString(" : ")
+		k.buffer.WriteString(strings.Join(supertypes, ", "))
+	}
+
+	// The init block of an open class would read the fields its children
+	// override before they are set, the classes that aren't open check the
+	// fields they inherit too
+	if !isOpen && emitter.HasChecks(k.tree, schema) {
+		k.emitInit(fields)
+	}
+
+	k.buffer.WriteString("\n")
 }
 
-func (k *KotlinEmitter) emitField(field *parser.FieldExpression) {
+func (k *KotlinEmitter) emitInit(fields []*parser.FieldExpression) {
+	k.buffer.WriteString(" {\n")
+	k.buffer.WriteString(Indent + "init {\n")
+
+	for _, field := range fields {
+		value := k.options.FieldName(field.Id.Name)
+		guard := ""
+
+		if field.IsOptional {
+			guard = value + " == null || "
+		}
+
+		typeName := k.tree.ResolveType(field.Type).Id.Name
+		length := value + ".size"
+
+		if typeName == "String" {
+			length = value + ".length"
+		}
+
+		// Unsigned numbers are only compared with unsigned literals
+		suffix := ""
+
+		if strings.HasPrefix(typeName, "UInt") {
+			suffix = "u"
+		}
+
+		for _, check := range emitter.Checks(k.tree, field) {
+			k.buffer.WriteString(Indent + Indent + "require(")
+			k.buffer.WriteString(guard)
+
+			switch check.Kind {
+			case emitter.CheckMin:
+				k.buffer.WriteString(value + " >= " + check.Bound + suffix)
+			case emitter.CheckMax:
+				k.buffer.WriteString(value + " <= " + check.Bound + suffix)
+			case emitter.CheckMinLength:
+				k.buffer.WriteString(length + " >= " + check.Bound)
+			case emitter.CheckMaxLength:
+				k.buffer.WriteString(length + " <= " + check.Bound)
+			case emitter.CheckPattern:
+				k.buffer.WriteString("Regex(" + kotlinQuote(check.Bound) + ").containsMatchIn(" + value + ")")
+			}
+
+			k.buffer.WriteString(") { ")
+			k.buffer.WriteString(kotlinQuote(check.Message))
+			k.buffer.WriteString(" }\n")
+		}
+	}
+
+	k.buffer.WriteString(Indent + "}\n")
+	k.buffer.WriteString("}")
+}
+
+// kotlinQuote escapes what Kotlin strings would otherwise interpret, templates
+// included
+func kotlinQuote(value string) string {
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "$", "\\$", "\n", "\\n").Replace(value) + "\""
+}
+
+func (k *KotlinEmitter) emitField(field *parser.FieldExpression, modifier string) {
+	name := k.options.FieldName(field.Id.Name)
+
+	if name != field.Id.Name && k.options.IsEnabled(KotlinSerializable) {
+		k.buffer.WriteString("@SerialName(\"")
+		k.buffer.WriteString(field.Id.Name)
+		k.buffer.WriteString("\") ")
+	}
+
+	k.buffer.WriteString(modifier)
 	k.buffer.WriteString("val ")
-	k.buffer.WriteString(field.Id.Name)
+	k.buffer.WriteString(name)
 	k.buffer.WriteString(": ")
 	k.emitType(field.Type)
 
@@ -111,6 +361,12 @@ func (k *KotlinEmitter) emitField(field *parser.FieldExpression) {
 func (k *KotlinEmitter) emitType(typeExpr *parser.TypeExpression) {
 	primitive, isPrimitive := ktPrimitives[typeExpr.Id.Name]
 
+	// Pair only holds two values, fixed-size arrays are lists since arrays
+	// don't compare by value in data classes
+	if typeExpr.Id.Name == "Tuple" && len(typeExpr.Generics) == 3 {
+		primitive = "Triple"
+	}
+
 	if isPrimitive {
 		k.buffer.WriteString(primitive)
 	} else {
@@ -136,16 +392,54 @@ func (k *KotlinEmitter) emitType(typeExpr *parser.TypeExpression) {
 	}
 }
 
+// emitEnum keeps explicit values in a value property, string values are the
+// names kotlinx writes the members with
 func (k *KotlinEmitter) emitEnum(enum *parser.EnumDefinition) {
+	kind, hasValues := enum.ValueKind()
+	valueType := "String"
+
+	if kind == parser.NumberLiteral {
+		valueType = "Int"
+
+		for _, value := range enum.Values {
+			if _, err := strconv.ParseInt(value.Value.Value, 10, 32); err != nil {
+				valueType = "Long"
+			}
+		}
+	}
+
+	k.emitSerializable()
 	k.buffer.WriteString("enum class ")
 	k.buffer.WriteString(enum.Id.Name)
+
+	if hasValues {
+		k.buffer.WriteString("(val value: ")
+		k.buffer.WriteString(valueType)
+		k.buffer.WriteString(")")
+	}
+
 	k.buffer.WriteString(" {\n")
 
 	for i, value := range enum.Values {
 
 		k.buffer.WriteString(Indent)
 
-		k.emitLiteralValue(value)
+		if hasValues && kind == parser.StringLiteral && k.options.IsEnabled(KotlinSerializable) {
+			k.buffer.WriteString("@SerialName(")
+			k.buffer.WriteString(kotlinQuote(value.Value.Value))
+			k.buffer.WriteString(") ")
+		}
+
+		k.emitLiteralValue(value.Id.Name)
+
+		switch {
+		case hasValues && kind == parser.StringLiteral:
+			k.buffer.WriteString("(" + kotlinQuote(value.Value.Value) + ")")
+		case hasValues && valueType == "Long":
+			k.buffer.WriteString("(" + value.Value.Value + "L)")
+		case hasValues:
+			k.buffer.WriteString("(" + value.Value.Value + ")")
+		}
 
 		isLast := i+1 == len(enum.Values)
 
@@ -159,10 +453,30 @@ func (k *KotlinEmitter) emitEnum(enum *parser.EnumDefinition) {
 	k.buffer.WriteString("}\n")
 }
 
+func (k *KotlinEmitter) emitUnion(union *parser.UnionDefinition) {
+	if k.options.IsEnabled(KotlinSerializable) {
+		k.buffer.WriteString("@OptIn(ExperimentalSerializationApi::class)\n")
+		k.emitSerializable()
+		k.buffer.WriteString("@JsonClassDiscriminator(\"")
+		k.buffer.WriteString(union.Discriminator.Name)
+		k.buffer.WriteString("\")\n")
+	}
+
+	k.buffer.WriteString("sealed interface ")
+	k.buffer.WriteString(union.Id.Name)
+	k.buffer.WriteString("\n")
+}
+
+func (k *KotlinEmitter) emitSerializable() {
+	if k.options.IsEnabled(KotlinSerializable) {
+		k.buffer.WriteString("@Serializable\n")
+	}
+}
+
 func (k *KotlinEmitter) emitLiteralValue(enum string) {
 	k.buffer.WriteString(enum)
 }
 
-func NewKotlinEmitter() emitter.LanguageCodeEmitter {
-	return &KotlinEmitter{}
+func NewKotlinEmitter(options emitter.Options) emitter.LanguageCodeEmitter {
+	return &KotlinEmitter{options: options}
 }
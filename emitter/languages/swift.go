@@ -26,65 +26,214 @@ var swiftPrimitives = map[string]string{
 	"Array":     "[]",
 	"Map":       "[:]",
 	"Bool":      "Bool",
+	"UUID":      "UUID",
+	"Decimal":   "Decimal",
+	"Bytes":     "Data",
+	"Duration":  "String",
+	"Url":       "URL",
+	"Set":       "Set",
+	"Tuple":     "()",
 }
 
+// swiftProtocolSuffix names the protocol of a schema other schemas inherit
+// from, structs can't inherit so they conform to it instead
+const swiftProtocolSuffix = "Protocol"
+
 type SwiftEmitter struct {
-	buffer strings.Builder
+	parser.BaseVisitor
+	options emitter.Options
+	buffer  strings.Builder
+	tree    *parser.EskemaTree
+	parents map[string]bool
 }
 
 func (s *SwiftEmitter) Emit(tree *parser.EskemaTree) string {
-	for _, expr := range tree.Expr {
-		s.emitExpression(expr)
+	s.tree = tree
+	s.parents = make(map[string]bool)
+
+	hasPatterns := false
+
+	for _, declaration := range tree.Declarations {
+		if schema, isSchema := declaration.(*parser.SchemaDefinition); isSchema {
+			for _, parent := range schema.Parents() {
+				s.parents[parent.Id.Name] = true
+			}
+
+			for _, field := range schema.Fields {
+				hasPatterns = hasPatterns || tree.Constraints(field).Pattern != ""
+			}
+		}
+	}
+
+	used := emitter.UsedTypes(tree)
+
+	// Patterns are matched with NSRegularExpression, through String.range, and
+	// UUID, Decimal, Data and URL are Foundation types
+	if hasPatterns || used["UUID"] || used["Decimal"] || used["Bytes"] || used["Url"] {
+		s.buffer.WriteString("import Foundation\n\n")
+	}
+
+	if consts := emitter.Consts(tree); len(consts) > 0 {
+		s.emitConsts(consts)
+		s.buffer.WriteString("\n\n")
+	}
+
+	for _, declaration := range tree.Declarations {
+		// constants were already emitted together
+		if _, isConst := declaration.(*parser.ConstDefinition); isConst {
+			continue
+		}
+
+		declaration.Accept(s)
 		s.buffer.WriteString("\n\n")
 	}
 
 	return s.buffer.String()
 }
 
-func (s *SwiftEmitter) emitExpression(expr *parser.EskemaExpression) {
-	switch expr.Type {
-	case parser.SchemaExpr:
-		s.emitSchema(expr.Data.(*parser.SchemaDefinition))
-		break
-	case parser.EnumExpr:
-		s.emitEnum(expr.Data.(*parser.EnumDefinition))
-		break
-	default:
-		break
+func (s *SwiftEmitter) VisitConst(*parser.ConstDefinition) bool {
+	return false
+}
+
+// emitConsts keeps the constants in an enum without cases, which can't be
+// created by mistake
+func (s *SwiftEmitter) emitConsts(consts []*parser.ConstDefinition) {
+	s.buffer.WriteString("public enum ")
+	s.buffer.WriteString(emitter.ConstantsName)
+	s.buffer.WriteString(" {\n")
+
+	for _, constant := range consts {
+		value := constant.Value.Value
+
+		if constant.Value.Kind == parser.StringLiteral {
+			value = swiftQuote(value)
+		}
+
+		s.buffer.WriteString(Indent + "public static let ")
+		s.buffer.WriteString(constant.Id.Name)
+		s.buffer.WriteString(": ")
+		s.emitType(constant.Type)
+		s.buffer.WriteString(" = ")
+		s.buffer.WriteString(value)
+		s.buffer.WriteString("\n")
 	}
+
+	s.buffer.WriteString("}")
+}
+
+func (s *SwiftEmitter) VisitSchema(schema *parser.SchemaDefinition) bool {
+	s.emitSchema(schema)
+
+	return false
+}
+
+func (s *SwiftEmitter) VisitEnum(enum *parser.EnumDefinition) bool {
+	s.emitEnum(enum)
+
+	return false
+}
+
+func (s *SwiftEmitter) VisitUnion(union *parser.UnionDefinition) bool {
+	s.emitUnion(union)
+
+	return false
+}
+
+func (s *SwiftEmitter) VisitAlias(alias *parser.AliasDefinition) bool {
+	s.buffer.WriteString("public typealias ")
+	s.buffer.WriteString(alias.Id.Name)
+	s.buffer.WriteString(" = ")
+	s.emitType(alias.Type)
+
+	return false
 }
 
 func (s *SwiftEmitter) emitSchema(schema *parser.SchemaDefinition) {
 	s.buffer.WriteString("public struct ")
 	s.buffer.WriteString(schema.Id.Name)
-	s.buffer.WriteString(": Decodable, Equatable {\n")
+	s.buffer.WriteString(": Decodable, Equatable")
+
+	// The protocol of the schema already refines the ones of its parents
+	if s.parents[schema.Id.Name] {
+		s.buffer.WriteString(", ")
+		s.buffer.WriteString(schema.Id.Name + swiftProtocolSuffix)
+	} else {
+		for _, parent := range schema.Parents() {
+			s.buffer.WriteString(", ")
+			s.buffer.WriteString(parent.Id.Name + swiftProtocolSuffix)
+		}
+	}
+
+	s.buffer.WriteString(" {\n")
 
 	// TODO: Emit generics
 
-	for _, field := range schema.Fields {
+	fields := s.tree.Fields(schema)
+	containsNullableFields := false
+
+	for _, field := range fields {
 		s.buffer.WriteString(Indent)
 
 		s.emitFieldDeclaration(field)
 
 		s.buffer.WriteString("\n")
+
+		containsNullableFields = containsNullableFields || field.IsOptional
+	}
+
+	if containsNullableFields {
+		s.emitNullableConstructor(fields)
 	}
 
-	if schema.ContainsNullableFields() {
-		s.emitNullableConstructor(schema)
+	if emitter.HasChecks(s.tree, schema) {
+		s.emitValidate(fields)
 	}
 
 	s.buffer.WriteString("}")
+
+	if s.parents[schema.Id.Name] {
+		s.buffer.WriteString("\n\n")
+		s.emitProtocol(schema)
+	}
 }
 
-func (s *SwiftEmitter) emitNullableConstructor(schema *parser.SchemaDefinition) {
+// emitProtocol requires the fields the schema declares itself, the ones it
+// inherits are required by the protocols of its parents
+func (s *SwiftEmitter) emitProtocol(schema *parser.SchemaDefinition) {
+	s.buffer.WriteString("protocol ")
+	s.buffer.WriteString(schema.Id.Name + swiftProtocolSuffix)
+
+	for i, parent := range schema.Parents() {
+		if i == 0 {
+			s.buffer.WriteString(": ")
+		} else {
+			s.buffer.WriteString(", ")
+		}
+
+		s.buffer.WriteString(parent.Id.Name + swiftProtocolSuffix)
+	}
+
+	s.buffer.WriteString(" {\n")
+
+	for _, field := range schema.Fields {
+		s.buffer.WriteString(Indent)
+		s.buffer.WriteString("var ")
+		s.emitField(field)
+		s.buffer.WriteString(" { get }\n")
+	}
+
+	s.buffer.WriteString("}")
+}
+
+func (s *SwiftEmitter) emitNullableConstructor(fields []*parser.FieldExpression) {
 
 	s.buffer.WriteString("\n")
 
 	s.buffer.WriteString(Indent)
 	s.buffer.WriteString("public init(")
 
-	for i, field := range schema.Fields {
-		isLast := i+1 == len(schema.Fields)
+	for i, field := range fields {
+		isLast := i+1 == len(fields)
 
 		s.emitConstructorField(field)
 
@@ -95,7 +244,7 @@ func (s *SwiftEmitter) emitNullableConstructor(schema *parser.SchemaDefinition)
 
 	s.buffer.WriteString(") {\n")
 
-	for _, field := range schema.Fields {
+	for _, field := range fields {
 		s.buffer.WriteString(Indent)
 		s.buffer.WriteString(Indent)
 		s.emitFieldInitializer(field)
@@ -106,8 +255,56 @@ func (s *SwiftEmitter) emitNullableConstructor(schema *parser.SchemaDefinition)
 	s.buffer.WriteString("}\n")
 }
 
+// emitValidate throws the same errors decoding does, values that break their
+// constraints are corrupted data
+func (s *SwiftEmitter) emitValidate(fields []*parser.FieldExpression) {
+	s.buffer.WriteString("\n")
+	s.buffer.WriteString(Indent + "public func validate() throws {\n")
+
+	for _, field := range fields {
+		name := s.options.FieldName(field.Id.Name)
+		condition := "if "
+
+		if field.IsOptional {
+			condition = "if let " + name + " = " + name + ", "
+		}
+
+		for _, check := range emitter.Checks(s.tree, field) {
+			s.buffer.WriteString(Indent + Indent)
+			s.buffer.WriteString(condition)
+
+			switch check.Kind {
+			case emitter.CheckMin:
+				s.buffer.WriteString(name + " < " + check.Bound)
+			case emitter.CheckMax:
+				s.buffer.WriteString(name + " > " + check.Bound)
+			case emitter.CheckMinLength:
+				s.buffer.WriteString(name + ".count < " + check.Bound)
+			case emitter.CheckMaxLength:
+				s.buffer.WriteString(name + ".count > " + check.Bound)
+			case emitter.CheckPattern:
+				s.buffer.WriteString(name + ".range(of: " + swiftQuote(check.Bound) + ", options: .regularExpression) == nil")
+			}
+
+			s.buffer.WriteString(" {\n")
+			s.buffer.WriteString(Indent + Indent + Indent)
+			s.buffer.WriteString("throw DecodingError.dataCorrupted(DecodingError.Context(codingPath: [], debugDescription: ")
+			s.buffer.WriteString(swiftQuote(check.Message))
+			s.buffer.WriteString("))\n")
+			s.buffer.WriteString(Indent + Indent + "}\n")
+		}
+	}
+
+	s.buffer.WriteString(Indent + "}\n")
+}
+
+// swiftQuote escapes backslashes, which would otherwise start interpolations
+func swiftQuote(value string) string {
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(value) + "\""
+}
+
 func (s *SwiftEmitter) emitField(field *parser.FieldExpression) {
-	s.buffer.WriteString(field.Id.Name)
+	s.buffer.WriteString(s.options.FieldName(field.Id.Name))
 	s.buffer.WriteString(": ")
 	s.emitType(field.Type)
 
@@ -131,14 +328,34 @@ func (s *SwiftEmitter) emitConstructorField(field *parser.FieldExpression) {
 
 func (s *SwiftEmitter) emitFieldInitializer(field *parser.FieldExpression) {
 	s.buffer.WriteString("self.")
-	s.buffer.WriteString(field.Id.Name)
+	s.buffer.WriteString(s.options.FieldName(field.Id.Name))
 	s.buffer.WriteString(" = ")
-	s.buffer.WriteString(field.Id.Name)
+	s.buffer.WriteString(s.options.FieldName(field.Id.Name))
+}
+
+func (s *SwiftEmitter) emitTuple(typeExpr *parser.TypeExpression) {
+	s.buffer.WriteString("(")
+
+	for i, typ := range typeExpr.Generics {
+		if i > 0 {
+			s.buffer.WriteString(", ")
+		}
+
+		s.emitType(typ)
+	}
+
+	s.buffer.WriteString(")")
 }
 
 func (s *SwiftEmitter) emitType(typeExpr *parser.TypeExpression) {
 	primitive, isPrimitive := swiftPrimitives[typeExpr.Id.Name]
 
+	if typeExpr.Id.Name == "Tuple" {
+		s.emitTuple(typeExpr)
+
+		return
+	}
+
 	isMap := typeExpr.Id.Name == "Map"
 	isArray := typeExpr.Id.Name == "Array"
 
@@ -181,28 +398,107 @@ func (s *SwiftEmitter) emitType(typeExpr *parser.TypeExpression) {
 	}
 }
 
+// emitEnum uses the explicit values of the members as their raw values, or
+// their names when they have none
 func (s *SwiftEmitter) emitEnum(enum *parser.EnumDefinition) {
+	kind, hasValues := enum.ValueKind()
+
 	s.buffer.WriteString("public enum ")
 	s.buffer.WriteString(enum.Id.Name)
-	s.buffer.WriteString(": String, Decodable, Equatable {\n")
+
+	if hasValues && kind == parser.NumberLiteral {
+		s.buffer.WriteString(": Int, Decodable, Equatable {\n")
+	} else {
+		s.buffer.WriteString(": String, Decodable, Equatable {\n")
+	}
 
 	for _, value := range enum.Values {
 
 		s.buffer.WriteString(Indent)
 		s.buffer.WriteString("case ")
-		s.buffer.WriteString(codestyle.ToCamelCase(value))
-		s.buffer.WriteString(" = \"")
-		s.emitLiteralValue(value)
-		s.buffer.WriteString("\"\n")
+		s.buffer.WriteString(codestyle.ToCamelCase(value.Id.Name))
+		s.buffer.WriteString(" = ")
+
+		switch {
+		case hasValues && kind == parser.NumberLiteral:
+			s.buffer.WriteString(value.Value.Value)
+		case hasValues:
+			s.buffer.WriteString(swiftQuote(value.Value.Value))
+		default:
+			s.buffer.WriteString("\"")
+			s.emitLiteralValue(value.Id.Name)
+			s.buffer.WriteString("\"")
+		}
+
+		s.buffer.WriteString("\n")
 	}
 
 	s.buffer.WriteString("}")
 }
 
+func (s *SwiftEmitter) emitUnion(union *parser.UnionDefinition) {
+	s.buffer.WriteString("public enum ")
+	s.buffer.WriteString(union.Id.Name)
+	s.buffer.WriteString(": Decodable, Equatable {\n")
+
+	for _, variant := range union.Variants {
+		s.buffer.WriteString(Indent)
+		s.buffer.WriteString("case ")
+		s.buffer.WriteString(codestyle.ToCamelCase(variant.Name()))
+		s.buffer.WriteString("(")
+		s.buffer.WriteString(variant.Name())
+		s.buffer.WriteString(")\n")
+	}
+
+	s.buffer.WriteString("\n")
+	s.buffer.WriteString(Indent)
+	s.buffer.WriteString("private enum CodingKeys: String, CodingKey {\n")
+	s.buffer.WriteString(Indent + Indent)
+	s.buffer.WriteString("case discriminator = \"")
+	s.buffer.WriteString(union.Discriminator.Name)
+	s.buffer.WriteString("\"\n")
+	s.buffer.WriteString(Indent)
+	s.buffer.WriteString("}\n\n")
+
+	s.buffer.WriteString(Indent)
+	s.buffer.WriteString("public init(from decoder: Decoder) throws {\n")
+	s.buffer.WriteString(Indent + Indent)
+	s.buffer.WriteString("let container = try decoder.container(keyedBy: CodingKeys.self)\n")
+	s.buffer.WriteString(Indent + Indent)
+	s.buffer.WriteString("let discriminator = try container.decode(String.self, forKey: .discriminator)\n\n")
+	s.buffer.WriteString(Indent + Indent)
+	s.buffer.WriteString("switch discriminator {\n")
+
+	for _, variant := range union.Variants {
+		s.buffer.WriteString(Indent + Indent)
+		s.buffer.WriteString("case \"")
+		s.buffer.WriteString(variant.Name())
+		s.buffer.WriteString("\":\n")
+		s.buffer.WriteString(Indent + Indent + Indent)
+		s.buffer.WriteString("self = .")
+		s.buffer.WriteString(codestyle.ToCamelCase(variant.Name()))
+		s.buffer.WriteString("(try ")
+		s.buffer.WriteString(variant.Name())
+		s.buffer.WriteString("(from: decoder))\n")
+	}
+
+	s.buffer.WriteString(Indent + Indent)
+	s.buffer.WriteString("default:\n")
+	s.buffer.WriteString(Indent + Indent + Indent)
+	s.buffer.WriteString("throw DecodingError.dataCorruptedError(forKey: .discriminator, in: container, debugDescription: \"unknown ")
+	s.buffer.WriteString(union.Id.Name)
+	s.buffer.WriteString(" \\(discriminator)\")\n")
+	s.buffer.WriteString(Indent + Indent)
+	s.buffer.WriteString("}\n")
+	s.buffer.WriteString(Indent)
+	s.buffer.WriteString("}\n")
+	s.buffer.WriteString("}")
+}
+
 func (s *SwiftEmitter) emitLiteralValue(enum string) {
 	s.buffer.WriteString(enum)
 }
 
-func NewSwiftEmitter() emitter.LanguageCodeEmitter {
-	return &SwiftEmitter{}
+func NewSwiftEmitter(options emitter.Options) emitter.LanguageCodeEmitter {
+	return &SwiftEmitter{options: options}
 }
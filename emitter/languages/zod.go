@@ -0,0 +1,727 @@
+package languages
+
+import (
+	"encoding/json"
+	"github.com/Haato3o/eskema/core/codestyle"
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/emitter"
+	"strings"
+)
+
+// zodPrimitives maps every primitive to its zod validator, integers are refined
+// to their ranges, 64-bit ones are capped by what a JS number can safely hold
+var zodPrimitives = map[string]string{
+	"String":    "z.string()",
+	"Char":      "z.string().length(1)",
+	"UInt8":     "z.number().int().min(0).max(255)",
+	"UInt16":    "z.number().int().min(0).max(65535)",
+	"UInt32":    "z.number().int().min(0).max(4294967295)",
+	"UInt64":    "z.number().int().min(0).max(Number.MAX_SAFE_INTEGER)",
+	"Int8":      "z.number().int().min(-128).max(127)",
+	"Int16":     "z.number().int().min(-32768).max(32767)",
+	"Int32":     "z.number().int().min(-2147483648).max(2147483647)",
+	"Int64":     "z.number().int().min(Number.MIN_SAFE_INTEGER).max(Number.MAX_SAFE_INTEGER)",
+	"Float":     "z.number()",
+	"Double":    "z.number()",
+	"TimeStamp": "z.string().datetime()",
+	"Date":      "z.string().date()",
+	"DateTime":  "z.string().datetime()",
+	"Array":     "z.array",
+	"Map":       "z.record",
+	"Bool":      "z.boolean()",
+	"UUID":      "z.string().uuid()",
+	"Decimal":   "z.number()",
+	"Bytes":     "z.string().base64()",
+	"Duration":  "z.string().duration()",
+	"Url":       "z.string().url()",
+	"Set":       "z.array",
+	"Tuple":     "z.tuple",
+}
+
+var typeScriptPrimitives = map[string]string{
+	"String":    "string",
+	"Char":      "string",
+	"UInt8":     "number",
+	"UInt16":    "number",
+	"UInt32":    "number",
+	"UInt64":    "number",
+	"Int8":      "number",
+	"Int16":     "number",
+	"Int32":     "number",
+	"Int64":     "number",
+	"Float":     "number",
+	"Double":    "number",
+	"TimeStamp": "string",
+	"Date":      "string",
+	"DateTime":  "string",
+	"Array":     "Array",
+	"Map":       "Record",
+	"Bool":      "boolean",
+	"UUID":      "string",
+	"Decimal":   "number",
+	"Bytes":     "string",
+	"Duration":  "string",
+	"Url":       "string",
+	"Set":       "Array",
+	"Tuple":     "",
+}
+
+const zodSchemaSuffix = "Schema"
+
+type ZodEmitter struct {
+	options  emitter.Options
+	buffer   strings.Builder
+	tree     *parser.EskemaTree
+	schemas  map[string]*parser.SchemaDefinition
+	unions   map[string]*parser.UnionDefinition
+	aliases  map[string]*parser.AliasDefinition
+	emitted  map[string]bool
+	visiting map[string]bool
+	// keys are the generics of each schema that end up as record keys
+	keys map[string]map[string]bool
+}
+
+// Emit writes every schema with the fields it inherits, zod objects can't
+// extend a schema typed as z.ZodType so inherited fields are always copied
+func (z *ZodEmitter) Emit(tree *parser.EskemaTree) string {
+	z.tree = tree
+	z.schemas = make(map[string]*parser.SchemaDefinition)
+	z.unions = make(map[string]*parser.UnionDefinition)
+	z.aliases = make(map[string]*parser.AliasDefinition)
+	z.emitted = make(map[string]bool)
+	z.visiting = make(map[string]bool)
+
+	z.buffer.WriteString("import { z } from \"zod\";\n\n")
+
+	for _, declaration := range tree.Declarations {
+		switch declaration := declaration.(type) {
+		case *parser.SchemaDefinition:
+			z.schemas[declaration.Id.Name] = declaration
+		case *parser.UnionDefinition:
+			z.unions[declaration.Id.Name] = declaration
+		case *parser.AliasDefinition:
+			z.aliases[declaration.Id.Name] = declaration
+		}
+	}
+
+	z.keys = emitter.KeyGenerics(tree, "Map")
+
+	if consts := emitter.Consts(tree); len(consts) > 0 {
+		z.emitConsts(consts)
+		z.buffer.WriteString("\n")
+	}
+
+	for _, declaration := range tree.Declarations {
+		if enum, isEnum := declaration.(*parser.EnumDefinition); isEnum {
+			z.emitEnum(enum)
+			z.buffer.WriteString("\n")
+		}
+	}
+
+	for _, declaration := range tree.Declarations {
+		switch declaration := declaration.(type) {
+		case *parser.SchemaDefinition:
+			z.visitSchema(declaration)
+		case *parser.UnionDefinition:
+			z.visitUnion(declaration)
+		case *parser.AliasDefinition:
+			z.visitAlias(declaration)
+		}
+	}
+
+	return z.buffer.String()
+}
+
+// emitConsts leaves the types to TypeScript, which infers the literal types of
+// constants
+func (z *ZodEmitter) emitConsts(consts []*parser.ConstDefinition) {
+	for _, constant := range consts {
+		value := constant.Value.Value
+
+		if constant.Value.Kind == parser.StringLiteral {
+			quoted, _ := json.Marshal(value)
+			value = string(quoted)
+		}
+
+		z.buffer.WriteString("export const ")
+		z.buffer.WriteString(constant.Id.Name)
+		z.buffer.WriteString(" = ")
+		z.buffer.WriteString(value)
+		z.buffer.WriteString(";\n")
+	}
+}
+
+// visitSchema emits every schema a schema depends on before the schema itself,
+// so only references that close a cycle need to be wrapped in z.lazy
+func (z *ZodEmitter) visitSchema(schema *parser.SchemaDefinition) {
+	name := schema.Id.Name
+
+	if z.emitted[name] || z.visiting[name] {
+		return
+	}
+
+	z.visiting[name] = true
+
+	for _, field := range z.tree.Fields(schema) {
+		z.visitType(field.Type)
+	}
+
+	z.emitSchema(schema)
+	z.buffer.WriteString("\n")
+
+	z.visiting[name] = false
+	z.emitted[name] = true
+}
+
+// visitUnion emits the variants of a union before the union, like visitSchema.
+// A variant that refers back to its union is still being emitted, the union
+// waits for its own declaration and the variant refers to it with z.lazy
+func (z *ZodEmitter) visitUnion(union *parser.UnionDefinition) {
+	name := union.Id.Name
+
+	if z.emitted[name] || z.visiting[name] {
+		return
+	}
+
+	for _, variant := range union.Variants {
+		if z.visiting[variant.Name()] {
+			return
+		}
+	}
+
+	z.visiting[name] = true
+
+	for _, variant := range union.Variants {
+		z.visitType(variant.Type)
+	}
+
+	z.emitUnion(union)
+	z.buffer.WriteString("\n")
+
+	z.visiting[name] = false
+	z.emitted[name] = true
+}
+
+func (z *ZodEmitter) visitAlias(alias *parser.AliasDefinition) {
+	name := alias.Id.Name
+
+	if z.emitted[name] || z.visiting[name] {
+		return
+	}
+
+	z.visiting[name] = true
+
+	z.visitType(alias.Type)
+	z.emitAlias(alias)
+	z.buffer.WriteString("\n")
+
+	z.visiting[name] = false
+	z.emitted[name] = true
+}
+
+func (z *ZodEmitter) visitType(typeExpr *parser.TypeExpression) {
+	if dependency, exists := z.schemas[typeExpr.Id.Name]; exists {
+		z.visitSchema(dependency)
+	}
+
+	if dependency, exists := z.unions[typeExpr.Id.Name]; exists {
+		z.visitUnion(dependency)
+	}
+
+	if dependency, exists := z.aliases[typeExpr.Id.Name]; exists {
+		z.visitAlias(dependency)
+	}
+
+	for _, generic := range typeExpr.Generics {
+		z.visitType(generic)
+	}
+}
+
+func (z *ZodEmitter) emitSchema(schema *parser.SchemaDefinition) {
+	isGeneric := len(schema.Generics) > 0
+	isRecursive := z.isRecursive(schema)
+
+	if isGeneric || isRecursive {
+		z.emitTypeDeclaration(schema)
+	}
+
+	z.buffer.WriteString("export const ")
+	z.buffer.WriteString(schema.Id.Name)
+	z.buffer.WriteString(zodSchemaSuffix)
+
+	if isGeneric {
+		z.buffer.WriteString(" = <")
+
+		for i, generic := range schema.Generics {
+			isLast := i+1 == len(schema.Generics)
+
+			z.buffer.WriteString(generic.Id.Name)
+
+			if z.keys[schema.Id.Name][generic.Id.Name] {
+				z.buffer.WriteString(" extends z.ZodType<string | number>")
+			} else {
+				z.buffer.WriteString(" extends z.ZodTypeAny")
+			}
+
+			if !isLast {
+				z.buffer.WriteString(", ")
+			}
+		}
+
+		z.buffer.WriteString(">(")
+
+		for i, generic := range schema.Generics {
+			isLast := i+1 == len(schema.Generics)
+
+			z.buffer.WriteString(z.genericParameterName(generic.Id.Name))
+			z.buffer.WriteString(": ")
+			z.buffer.WriteString(generic.Id.Name)
+
+			if !isLast {
+				z.buffer.WriteString(", ")
+			}
+		}
+
+		z.buffer.WriteString(") =>")
+	} else if isRecursive {
+		z.buffer.WriteString(": z.ZodType<")
+		z.buffer.WriteString(schema.Id.Name)
+		z.buffer.WriteString("> =")
+	} else {
+		z.buffer.WriteString(" =")
+	}
+
+	z.buffer.WriteString(" z.object({\n")
+
+	for _, field := range z.tree.Fields(schema) {
+		z.buffer.WriteString(Indent)
+		z.emitField(field, schema)
+		z.buffer.WriteString(",\n")
+	}
+
+	z.buffer.WriteString("});\n")
+
+	if !isGeneric && !isRecursive {
+		z.buffer.WriteString("export type ")
+		z.buffer.WriteString(schema.Id.Name)
+		z.buffer.WriteString(" = z.infer<typeof ")
+		z.buffer.WriteString(schema.Id.Name)
+		z.buffer.WriteString(zodSchemaSuffix)
+		z.buffer.WriteString(">;\n")
+	}
+}
+
+func (z *ZodEmitter) emitField(field *parser.FieldExpression, schema *parser.SchemaDefinition) {
+	z.buffer.WriteString(z.options.FieldName(field.Id.Name))
+	z.buffer.WriteString(": ")
+
+	// The constraints of an alias are already part of its schema
+	if len(field.Annotations) > 0 {
+		z.emitConstrainedType(field.Type, z.tree.Constraints(field), schema)
+	} else {
+		z.emitType(field.Type, schema)
+	}
+
+	if field.IsOptional {
+		z.buffer.WriteString(".nullable().optional()")
+	}
+}
+
+// emitConstrainedType writes the type an alias stands for instead of the alias,
+// the constraints of the alias are part of the ones the value is checked for
+func (z *ZodEmitter) emitConstrainedType(typeExpr *parser.TypeExpression, constraints parser.Constraints, schema *parser.SchemaDefinition) {
+	if constraints.IsEmpty() {
+		z.emitType(typeExpr, schema)
+		return
+	}
+
+	seen := make(map[*parser.AliasDefinition]bool)
+
+	for alias := z.aliases[typeExpr.Id.Name]; alias != nil && !seen[alias]; alias = z.aliases[typeExpr.Id.Name] {
+		seen[alias] = true
+		typeExpr = alias.Type
+	}
+
+	z.emitType(typeExpr, schema)
+
+	if constraints.Min != "" {
+		z.buffer.WriteString(".min(" + constraints.Min + ")")
+	}
+
+	if constraints.Max != "" {
+		z.buffer.WriteString(".max(" + constraints.Max + ")")
+	}
+
+	// Records have no length, their keys are counted instead
+	isMap := typeExpr.Id.Name == "Map"
+
+	if bound := constraints.MinLength; bound == "" || bound == "0" {
+		// every length is at least 0
+	} else if isMap {
+		z.buffer.WriteString(".refine((value) => Object.keys(value).length >= " + bound + ")")
+	} else if bound != "" {
+		z.buffer.WriteString(".min(" + bound + ")")
+	}
+
+	if bound := constraints.MaxLength; bound != "" && isMap {
+		z.buffer.WriteString(".refine((value) => Object.keys(value).length <= " + bound + ")")
+	} else if bound != "" {
+		z.buffer.WriteString(".max(" + bound + ")")
+	}
+
+	if constraints.Pattern != "" {
+		pattern, _ := json.Marshal(constraints.Pattern)
+
+		z.buffer.WriteString(".regex(new RegExp(")
+		z.buffer.Write(pattern)
+		z.buffer.WriteString("))")
+	}
+}
+
+func (z *ZodEmitter) emitType(typeExpr *parser.TypeExpression, schema *parser.SchemaDefinition) {
+	name := typeExpr.Id.Name
+
+	if primitive, isPrimitive := zodPrimitives[name]; isPrimitive {
+		z.buffer.WriteString(primitive)
+
+		// z.tuple takes its elements in an array, JSON has no sets so they are
+		// arrays too
+		if name == "Tuple" {
+			z.buffer.WriteString("([")
+			z.emitTypeArguments(typeExpr.Generics, schema)
+			z.buffer.WriteString("])")
+		} else if len(typeExpr.Generics) > 0 {
+			z.buffer.WriteString("(")
+			z.emitTypeArguments(typeExpr.Generics, schema)
+			z.buffer.WriteString(")")
+		}
+
+		if typeExpr.Size != nil {
+			z.buffer.WriteString(".length(" + typeExpr.Size.Value + ")")
+		}
+
+		return
+	}
+
+	for _, generic := range schema.Generics {
+		if generic.Id.Name == name {
+			z.buffer.WriteString(z.genericParameterName(name))
+			return
+		}
+	}
+
+	isForwardReference := !z.emitted[name] && (z.schemas[name] != nil || z.unions[name] != nil || z.aliases[name] != nil)
+
+	if isForwardReference {
+		z.buffer.WriteString("z.lazy(() => ")
+	}
+
+	z.buffer.WriteString(name)
+	z.buffer.WriteString(zodSchemaSuffix)
+
+	if len(typeExpr.Generics) > 0 {
+		z.buffer.WriteString("(")
+		z.emitTypeArguments(typeExpr.Generics, schema)
+		z.buffer.WriteString(")")
+	}
+
+	if isForwardReference {
+		z.buffer.WriteString(")")
+	}
+}
+
+func (z *ZodEmitter) emitTypeArguments(generics []*parser.TypeExpression, schema *parser.SchemaDefinition) {
+	for i, generic := range generics {
+		isLast := i+1 == len(generics)
+
+		z.emitType(generic, schema)
+
+		if !isLast {
+			z.buffer.WriteString(", ")
+		}
+	}
+}
+
+// emitTypeDeclaration writes the static type by hand for schemas z.infer can't
+// describe, which are generic factories and self referencing schemas
+func (z *ZodEmitter) emitTypeDeclaration(schema *parser.SchemaDefinition) {
+	z.buffer.WriteString("export type ")
+	z.buffer.WriteString(schema.Id.Name)
+
+	if len(schema.Generics) > 0 {
+		z.buffer.WriteString("<")
+
+		for i, generic := range schema.Generics {
+			isLast := i+1 == len(schema.Generics)
+
+			z.buffer.WriteString(generic.Id.Name)
+
+			if z.keys[schema.Id.Name][generic.Id.Name] {
+				z.buffer.WriteString(" extends string | number")
+			}
+
+			if !isLast {
+				z.buffer.WriteString(", ")
+			}
+		}
+
+		z.buffer.WriteString(">")
+	}
+
+	z.buffer.WriteString(" = {\n")
+
+	for _, field := range z.tree.Fields(schema) {
+		z.buffer.WriteString(Indent)
+		z.buffer.WriteString(z.options.FieldName(field.Id.Name))
+
+		if field.IsOptional {
+			z.buffer.WriteString("?")
+		}
+
+		z.buffer.WriteString(": ")
+		z.emitTypeScriptType(field.Type)
+
+		if field.IsOptional {
+			z.buffer.WriteString(" | null")
+		}
+
+		z.buffer.WriteString(";\n")
+	}
+
+	z.buffer.WriteString("};\n")
+}
+
+func (z *ZodEmitter) emitTypeScriptType(typeExpr *parser.TypeExpression) {
+	primitive, isPrimitive := typeScriptPrimitives[typeExpr.Id.Name]
+
+	if typeExpr.Id.Name == "Tuple" {
+		z.buffer.WriteString("[")
+
+		for i, typ := range typeExpr.Generics {
+			if i > 0 {
+				z.buffer.WriteString(", ")
+			}
+
+			z.emitTypeScriptType(typ)
+		}
+
+		z.buffer.WriteString("]")
+
+		return
+	}
+
+	if isPrimitive {
+		z.buffer.WriteString(primitive)
+	} else {
+		z.buffer.WriteString(typeExpr.Id.Name)
+	}
+
+	for i, typ := range typeExpr.Generics {
+		isFirst := i == 0
+		isLast := i+1 == len(typeExpr.Generics)
+
+		if isFirst {
+			z.buffer.WriteString("<")
+		}
+
+		z.emitTypeScriptType(typ)
+
+		if isLast {
+			z.buffer.WriteString(">")
+		} else {
+			z.buffer.WriteString(", ")
+		}
+	}
+}
+
+// emitEnum accepts the explicit values of the members, or their names when they
+// have none. z.enum only takes strings, integers are a union of literals
+func (z *ZodEmitter) emitEnum(enum *parser.EnumDefinition) {
+	kind, hasValues := enum.ValueKind()
+
+	z.buffer.WriteString("export const ")
+	z.buffer.WriteString(enum.Id.Name)
+	z.buffer.WriteString(zodSchemaSuffix)
+
+	switch {
+	case hasValues && kind == parser.NumberLiteral && len(enum.Values) == 1:
+		z.buffer.WriteString(" = z.literal(" + enum.Values[0].Value.Value + ");\n")
+	case hasValues && kind == parser.NumberLiteral:
+		z.buffer.WriteString(" = z.union([\n")
+
+		for _, value := range enum.Values {
+			z.buffer.WriteString(Indent)
+			z.buffer.WriteString("z.literal(" + value.Value.Value + "),\n")
+		}
+
+		z.buffer.WriteString("]);\n")
+	default:
+		z.buffer.WriteString(" = z.enum([\n")
+
+		for _, value := range enum.Values {
+			z.buffer.WriteString(Indent)
+
+			if hasValues {
+				literal, _ := json.Marshal(value.Value.Value)
+				z.buffer.Write(literal)
+			} else {
+				z.buffer.WriteString("\"")
+				z.emitLiteralValue(value.Id.Name)
+				z.buffer.WriteString("\"")
+			}
+
+			z.buffer.WriteString(",\n")
+		}
+
+		z.buffer.WriteString("]);\n")
+	}
+
+	z.buffer.WriteString("export type ")
+	z.buffer.WriteString(enum.Id.Name)
+	z.buffer.WriteString(" = z.infer<typeof ")
+	z.buffer.WriteString(enum.Id.Name)
+	z.buffer.WriteString(zodSchemaSuffix)
+	z.buffer.WriteString(">;\n")
+}
+
+// emitUnion adds the discriminator to every variant, zod picks the variant by
+// its literal value. Recursive variants are typed as z.ZodType, which can't be
+// extended, so their unions fall back to intersecting each variant
+func (z *ZodEmitter) emitUnion(union *parser.UnionDefinition) {
+	isRecursive := false
+
+	for _, variant := range union.Variants {
+		if schema, exists := z.schemas[variant.Name()]; exists && z.isRecursive(schema) {
+			isRecursive = true
+		}
+	}
+
+	z.buffer.WriteString("export const ")
+	z.buffer.WriteString(union.Id.Name)
+	z.buffer.WriteString(zodSchemaSuffix)
+
+	if isRecursive {
+		z.buffer.WriteString(" = z.union([\n")
+	} else {
+		z.buffer.WriteString(" = z.discriminatedUnion(\"")
+		z.buffer.WriteString(union.Discriminator.Name)
+		z.buffer.WriteString("\", [\n")
+	}
+
+	for _, variant := range union.Variants {
+		z.buffer.WriteString(Indent)
+		z.buffer.WriteString(variant.Name())
+		z.buffer.WriteString(zodSchemaSuffix)
+
+		if isRecursive {
+			z.buffer.WriteString(".and(z.object({ ")
+		} else {
+			z.buffer.WriteString(".extend({ ")
+		}
+
+		z.buffer.WriteString(union.Discriminator.Name)
+		z.buffer.WriteString(": z.literal(\"")
+		z.buffer.WriteString(variant.Name())
+
+		if isRecursive {
+			z.buffer.WriteString("\") })),\n")
+		} else {
+			z.buffer.WriteString("\") }),\n")
+		}
+	}
+
+	z.buffer.WriteString("]);\n")
+
+	z.buffer.WriteString("export type ")
+	z.buffer.WriteString(union.Id.Name)
+	z.buffer.WriteString(" = z.infer<typeof ")
+	z.buffer.WriteString(union.Id.Name)
+	z.buffer.WriteString(zodSchemaSuffix)
+	z.buffer.WriteString(">;\n")
+}
+
+// emitAlias names the schema of the type, an alias can't have generics so it is
+// emitted outside of any schema
+func (z *ZodEmitter) emitAlias(alias *parser.AliasDefinition) {
+	z.buffer.WriteString("export const ")
+	z.buffer.WriteString(alias.Id.Name)
+	z.buffer.WriteString(zodSchemaSuffix)
+	z.buffer.WriteString(" = ")
+	z.emitConstrainedType(alias.Type, z.tree.AliasConstraints(alias), &parser.SchemaDefinition{})
+	z.buffer.WriteString(";\n")
+
+	z.buffer.WriteString("export type ")
+	z.buffer.WriteString(alias.Id.Name)
+	z.buffer.WriteString(" = z.infer<typeof ")
+	z.buffer.WriteString(alias.Id.Name)
+	z.buffer.WriteString(zodSchemaSuffix)
+	z.buffer.WriteString(">;\n")
+}
+
+func (z *ZodEmitter) emitLiteralValue(enum string) {
+	z.buffer.WriteString(enum)
+}
+
+func (z *ZodEmitter) genericParameterName(generic string) string {
+	return codestyle.ToCamelCase(generic) + zodSchemaSuffix
+}
+
+func (z *ZodEmitter) isRecursive(schema *parser.SchemaDefinition) bool {
+	return z.reaches(schema, schema.Id.Name, make(map[string]bool))
+}
+
+func (z *ZodEmitter) reaches(schema *parser.SchemaDefinition, target string, seen map[string]bool) bool {
+	if seen[schema.Id.Name] {
+		return false
+	}
+
+	seen[schema.Id.Name] = true
+
+	for _, field := range z.tree.Fields(schema) {
+		if z.typeReaches(field.Type, target, seen) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (z *ZodEmitter) typeReaches(typeExpr *parser.TypeExpression, target string, seen map[string]bool) bool {
+	if typeExpr.Id.Name == target {
+		return true
+	}
+
+	if dependency, exists := z.schemas[typeExpr.Id.Name]; exists && z.reaches(dependency, target, seen) {
+		return true
+	}
+
+	if alias, exists := z.aliases[typeExpr.Id.Name]; exists && !seen[alias.Id.Name] {
+		seen[alias.Id.Name] = true
+
+		if z.typeReaches(alias.Type, target, seen) {
+			return true
+		}
+	}
+
+	if union, exists := z.unions[typeExpr.Id.Name]; exists && !seen[union.Id.Name] {
+		seen[union.Id.Name] = true
+
+		for _, variant := range union.Variants {
+			if z.typeReaches(variant.Type, target, seen) {
+				return true
+			}
+		}
+	}
+
+	for _, generic := range typeExpr.Generics {
+		if z.typeReaches(generic, target, seen) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func NewZodEmitter(options emitter.Options) emitter.LanguageCodeEmitter {
+	return &ZodEmitter{options: options}
+}
@@ -0,0 +1,60 @@
+package emitter
+
+import (
+	"fmt"
+	"github.com/Haato3o/eskema/core/codestyle"
+)
+
+const (
+	CamelCase  = "camel"
+	PascalCase = "pascal"
+	SnakeCase  = "snake"
+)
+
+// Options change what is generated, the zero value keeps the defaults of each
+// language
+type Options struct {
+	// Package is the package or namespace the code is generated in, languages
+	// without one ignore it
+	Package string
+	// Naming is the style field names are converted to, they are kept as
+	// declared when it is empty
+	Naming string
+	// Extra holds the options only some emitters understand
+	Extra map[string]string
+}
+
+func (o Options) Verify() error {
+	switch o.Naming {
+	case "", CamelCase, PascalCase, SnakeCase:
+		return nil
+	default:
+		return fmt.Errorf("unknown naming style '%s', expected camel, pascal or snake", o.Naming)
+	}
+}
+
+func (o Options) PackageOr(fallback string) string {
+	if o.Package == "" {
+		return fallback
+	}
+
+	return o.Package
+}
+
+// FieldName converts the name of a field to the naming style
+func (o Options) FieldName(name string) string {
+	switch o.Naming {
+	case CamelCase:
+		return codestyle.ToCamelCase(name)
+	case PascalCase:
+		return codestyle.ToPascalCase(name)
+	case SnakeCase:
+		return codestyle.ToSnakeCase(name)
+	default:
+		return name
+	}
+}
+
+func (o Options) IsEnabled(option string) bool {
+	return o.Extra[option] == "true"
+}
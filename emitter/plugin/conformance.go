@@ -0,0 +1,112 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/syntax"
+	"github.com/Haato3o/eskema/emitter"
+	"os/exec"
+	"reflect"
+)
+
+// conformanceSource uses every construct a plugin may be sent
+const conformanceSource = `// conformance schema
+enum Status
+{
+  // still active
+  ONLINE = "online",
+  OFFLINE = "offline" // gone
+};
+
+// generic page of results
+schema Page<T>
+{
+  items: Array<T>,
+  next: String? @nonEmpty
+};
+
+schema Entity
+{
+  createdAt: DateTime
+};
+
+// identifies a user
+alias UserId = Int64 @min(1);
+
+// most items in a page
+const MAX_PAGE_SIZE: Int32 = 100;
+
+schema User extends Entity
+{
+  id: UserId,
+  status: Status,
+  age: Int32? @min(0) @max(150),
+  friends: Page<User>?,
+  metadata: Map<String, Array<Double>>,
+  extra: Unknown,
+  account: Account
+};
+
+schema Guest
+{
+  name: String
+};
+
+// who is using the service
+union Account: type
+{
+  User,
+  Guest
+};
+`
+
+// Conformance sends the executable the requests every plugin has to answer
+// the same way eskema expects them to, plugin authors can call it from their
+// own tests
+func Conformance(ctx context.Context, executable string) error {
+	tokens := syntax.NewLexer([]byte(conformanceSource), "conformance.skm").Lex()
+	request := NewRequest("conformance.skm", parser.New(tokens).Parse(), emitter.Options{
+		Package: "conformance",
+		Naming:  emitter.CamelCase,
+		Extra:   map[string]string{"conformance": "true"},
+	})
+
+	first, err := Run(ctx, executable, request)
+
+	if err != nil {
+		return fmt.Errorf("should answer a request: %w", err)
+	}
+
+	if len(first.Files) == 0 {
+		return errors.New("should generate at least one file for a source with declarations")
+	}
+
+	second, err := Run(ctx, executable, request)
+
+	if err != nil {
+		return fmt.Errorf("should answer the same request twice: %w", err)
+	}
+
+	if !reflect.DeepEqual(first, second) {
+		return errors.New("should answer the same request with the same response, generated files are compared by --check")
+	}
+
+	empty := NewRequest("empty.skm", &parser.EskemaTree{}, emitter.Options{})
+
+	if _, err := Run(ctx, executable, empty); err != nil {
+		return fmt.Errorf("should answer a request for an empty source: %w", err)
+	}
+
+	unsupported := NewRequest("conformance.skm", &parser.EskemaTree{}, emitter.Options{})
+	unsupported.Version = Version + 1
+
+	var exitErr *exec.ExitError
+
+	if _, err := Run(ctx, executable, unsupported); !errors.As(err, &exitErr) {
+		return fmt.Errorf("should exit with an error when the protocol version isn't supported, got %v", err)
+	}
+
+	return nil
+}
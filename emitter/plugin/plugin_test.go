@@ -0,0 +1,165 @@
+package plugin
+
+import (
+	"context"
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/syntax"
+	"github.com/Haato3o/eskema/emitter"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNewRequestResolvesTypes(t *testing.T) {
+	source := "enum Status { ONLINE };\nschema Page<T> { items: Array<T>, status: Status?, next: Page<Other>, hash: Array<UInt8, 16> };"
+	tree := parser.New(syntax.NewLexer([]byte(source), "page.skm").Lex()).Parse()
+	request := NewRequest("page.skm", tree, emitter.Options{Package: "pages"})
+
+	if request.Version != Version || request.Source != "page.skm" || request.Options.Package != "pages" {
+		t.Errorf("got %+v, expected the version, source and options to be set", request)
+	}
+
+	page := request.Tree.Declarations[1]
+	expected := []*Type{
+		{Name: "Array", Kind: TypePrimitive, Generics: []*Type{{Name: "T", Kind: TypeGeneric}}},
+		{Name: "Status", Kind: TypeEnum},
+		{Name: "Page", Kind: TypeSchema, Generics: []*Type{{Name: "Other", Kind: TypeUnknown}}},
+		{Name: "Array", Kind: TypePrimitive, Generics: []*Type{{Name: "UInt8", Kind: TypePrimitive}}, Size: 16},
+	}
+
+	for i, field := range page.Fields {
+		if !reflect.DeepEqual(field.Type, expected[i]) {
+			t.Errorf("field %s: got %+v, expected %+v", field.Name, field.Type, expected[i])
+		}
+	}
+
+	if !page.Fields[1].IsOptional || page.Span == nil || page.Span.Line != 2 {
+		t.Errorf("got %+v, expected the field to be optional and the span of the schema", page)
+	}
+}
+
+func TestNewRequestKeepsEnumValues(t *testing.T) {
+	source := "enum Code { OK = 200, NOT_FOUND = 404 };\nenum Status { ONLINE };"
+	tree := parser.New(syntax.NewLexer([]byte(source), "enum.skm").Lex()).Parse()
+	request := NewRequest("enum.skm", tree, emitter.Options{})
+
+	if value := request.Tree.Declarations[0].Values[1]; value.Name != "NOT_FOUND" || value.Value != "404" {
+		t.Errorf("got %+v, expected the explicit value of the member", value)
+	}
+
+	if value := request.Tree.Declarations[1].Values[0]; value.Value != "" {
+		t.Errorf("got %+v, expected members without a value to have none", value)
+	}
+}
+
+func TestNewRequestConvertsUnions(t *testing.T) {
+	source := "schema A { }; schema B { }; union U: kind { A, B }; schema C { u: U };"
+	tree := parser.New(syntax.NewLexer([]byte(source), "union.skm").Lex()).Parse()
+	request := NewRequest("union.skm", tree, emitter.Options{})
+
+	union := request.Tree.Declarations[2]
+
+	if union.Kind != KindUnion || union.Discriminator != "kind" || len(union.Variants) != 2 || union.Variants[1].Name != "B" {
+		t.Errorf("got %+v, expected the discriminator and variants of the union", union)
+	}
+
+	if field := request.Tree.Declarations[3].Fields[0]; field.Type.Kind != TypeUnion {
+		t.Errorf("got %s, expected references to unions to be resolved", field.Type.Kind)
+	}
+}
+
+func TestNewRequestConvertsAliases(t *testing.T) {
+	source := "alias Email = String @pattern(\"^.+@.+$\");\nschema User { email: Email @length(3, 254) };"
+	tree := parser.New(syntax.NewLexer([]byte(source), "alias.skm").Lex()).Parse()
+	request := NewRequest("alias.skm", tree, emitter.Options{})
+
+	alias := request.Tree.Declarations[0]
+
+	if alias.Kind != KindAlias || alias.Type.Name != "String" || len(alias.Annotations) != 1 {
+		t.Fatalf("got %+v, expected the type and annotations of the alias", alias)
+	}
+
+	if annotation := alias.Annotations[0]; annotation.Name != "pattern" || annotation.Arguments[0] != `"^.+@.+$"` {
+		t.Errorf("got %+v, expected the arguments as they were written", annotation)
+	}
+
+	field := request.Tree.Declarations[1].Fields[0]
+
+	if field.Type.Kind != TypeAlias {
+		t.Errorf("got %s, expected references to aliases to be resolved", field.Type.Kind)
+	}
+
+	if len(field.Annotations) != 1 || field.Annotations[0].Name != "length" || strings.Join(field.Annotations[0].Arguments, ",") != "3,254" {
+		t.Errorf("got %+v, expected the annotations of the field", field.Annotations)
+	}
+}
+
+func TestNewRequestFlattensInheritedFields(t *testing.T) {
+	source := "schema A { id: Int64 };\nschema B { at: DateTime };\nschema C extends A includes B { total: Double };"
+	tree := parser.New(syntax.NewLexer([]byte(source), "order.skm").Lex()).Parse()
+	schema := NewRequest("order.skm", tree, emitter.Options{}).Tree.Declarations[2]
+
+	if schema.Extends != "A" || !reflect.DeepEqual(schema.Includes, []string{"B"}) {
+		t.Errorf("got %+v, expected the parents of the schema", schema)
+	}
+
+	from := make([]string, 0, len(schema.Fields))
+
+	for _, field := range schema.Fields {
+		from = append(from, field.Name+":"+field.From)
+	}
+
+	if expected := []string{"id:A", "at:B", "total:"}; !reflect.DeepEqual(from, expected) {
+		t.Errorf("got %v, expected %v", from, expected)
+	}
+}
+
+func TestResponseVerify(t *testing.T) {
+	tests := []struct {
+		Name     string
+		Response *Response
+		IsValid  bool
+	}{
+		{"should accept files and diagnostics", &Response{Version: Version, Files: []*File{{Name: "a/b.md"}}, Diagnostics: []*Diagnostic{{Severity: "note", Message: "m"}}}, true},
+		{"should reject other versions", &Response{Version: Version + 1}, false},
+		{"should reject absolute names", &Response{Version: Version, Files: []*File{{Name: "/etc/passwd"}}}, false},
+		{"should reject names outside of the output", &Response{Version: Version, Files: []*File{{Name: "../b.md"}}}, false},
+		{"should reject duplicated names", &Response{Version: Version, Files: []*File{{Name: "b.md"}, {Name: "b.md"}}}, false},
+		{"should reject unknown severities", &Response{Version: Version, Diagnostics: []*Diagnostic{{Severity: "fatal", Message: "m"}}}, false},
+	}
+
+	for _, test := range tests {
+		if err := test.Response.Verify(Version); (err == nil) != test.IsValid {
+			t.Errorf("%s: got %v", test.Name, err)
+		}
+	}
+}
+
+// TestConformance checks the reference plugin against the protocol, which
+// also checks that Conformance accepts a plugin that follows it
+func TestConformance(t *testing.T) {
+	executable := buildReferencePlugin(t)
+
+	if err := Conformance(context.Background(), executable); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func buildReferencePlugin(t *testing.T) string {
+	goTool, err := exec.LookPath("go")
+
+	if err != nil {
+		t.Skip("building the reference plugin needs the go tool")
+	}
+
+	executable := filepath.Join(t.TempDir(), "eskema-gen-markdown")
+	build := exec.Command(goTool, "build", "-o", executable, "github.com/Haato3o/eskema/examples/plugins/eskema-gen-markdown")
+
+	if output, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, output)
+	}
+
+	return executable
+}
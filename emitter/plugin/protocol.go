@@ -0,0 +1,402 @@
+// Package plugin is the protocol eskema speaks with emitters that live in
+// their own executables. The executable gets a Request as JSON on STDIN and
+// answers with a Response as JSON on STDOUT, anything written to STDERR is
+// shown to the user when the plugin fails.
+package plugin
+
+import (
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/syntax"
+	"github.com/Haato3o/eskema/emitter"
+	"strconv"
+)
+
+// Version is bumped whenever a change to the protocol could break plugins,
+// plugins answer with the version they were asked for or fail
+const Version = 1
+
+// Prefix marks a language as a plugin, plugin:eskema-gen-foo runs the
+// eskema-gen-foo executable found on PATH
+const Prefix = "plugin:"
+
+// Kinds of declarations
+const (
+	KindSchema = "schema"
+	KindEnum   = "enum"
+	KindUnion  = "union"
+	KindAlias  = "alias"
+	KindConst  = "const"
+)
+
+// Kinds of types, references are resolved against the declarations of the
+// source and the generics of the schema they are used in
+const (
+	TypePrimitive = "primitive"
+	TypeGeneric   = "generic"
+	TypeSchema    = "schema"
+	TypeEnum      = "enum"
+	TypeUnion     = "union"
+	TypeAlias     = "alias"
+	TypeUnknown   = "unknown"
+)
+
+type Request struct {
+	Version int `json:"version"`
+	// Source is the name of the schema the tree was parsed from, relative to
+	// the project
+	Source  string  `json:"source"`
+	Options Options `json:"options"`
+	Tree    *Tree   `json:"tree"`
+}
+
+type Options struct {
+	Package string            `json:"package"`
+	Naming  string            `json:"naming"`
+	Extra   map[string]string `json:"extra"`
+}
+
+type Tree struct {
+	Declarations []*Declaration `json:"declarations"`
+	Comments     []string       `json:"comments"`
+}
+
+// Comments are kept as they were written, with the leading // of each one
+type Comments struct {
+	Leading  []string `json:"leading"`
+	Trailing string   `json:"trailing"`
+}
+
+// Declaration is a schema, with generics and fields, an enum, with values, a
+// union, with a discriminator and variants, an alias, with a type and its
+// annotations, or a const, with a type and its value as it was written,
+// depending on its kind. The fields of a schema include the ones it inherits
+// from its parents
+type Declaration struct {
+	Kind          string        `json:"kind"`
+	Name          string        `json:"name"`
+	Comments      Comments      `json:"comments"`
+	Generics      []string      `json:"generics,omitempty"`
+	Extends       string        `json:"extends,omitempty"`
+	Includes      []string      `json:"includes,omitempty"`
+	Fields        []*Field      `json:"fields,omitempty"`
+	Values        []*EnumValue  `json:"values,omitempty"`
+	Discriminator string        `json:"discriminator,omitempty"`
+	Variants      []*Variant    `json:"variants,omitempty"`
+	Type          *Type         `json:"type,omitempty"`
+	Annotations   []*Annotation `json:"annotations,omitempty"`
+	Value         string        `json:"value,omitempty"`
+	Span          *Span         `json:"span,omitempty"`
+}
+
+// Field is declared by the schema itself unless From names the parent it is
+// inherited from. Its annotations are the ones written on the field, the ones
+// of the aliases its type refers to are on their declarations
+type Field struct {
+	Name        string        `json:"name"`
+	IsOptional  bool          `json:"optional"`
+	Type        *Type         `json:"type"`
+	Annotations []*Annotation `json:"annotations,omitempty"`
+	From        string        `json:"from,omitempty"`
+	Comments    Comments      `json:"comments"`
+	Span        *Span         `json:"span,omitempty"`
+}
+
+// Type is a reference to a primitive or a declaration, Size is the length of a
+// fixed-size array and is 0 for any other type
+type Type struct {
+	Name     string  `json:"name"`
+	Kind     string  `json:"kind"`
+	Generics []*Type `json:"generics,omitempty"`
+	Size     int     `json:"size,omitempty"`
+}
+
+// EnumValue is written as its name unless it has an explicit value, which is
+// kept as it was written so strings keep their quotes
+type EnumValue struct {
+	Name     string   `json:"name"`
+	Value    string   `json:"value,omitempty"`
+	Comments Comments `json:"comments"`
+	Span     *Span    `json:"span,omitempty"`
+}
+
+// Variant is a schema of the union, its name is the value of the discriminator
+type Variant struct {
+	Name     string   `json:"name"`
+	Comments Comments `json:"comments"`
+	Span     *Span    `json:"span,omitempty"`
+}
+
+// Annotation arguments are kept as they were written, strings keep their
+// quotes so plugins can tell them apart from numbers
+type Annotation struct {
+	Name      string   `json:"name"`
+	Arguments []string `json:"arguments"`
+}
+
+// Span points into the source of the request, lines and columns start at 1
+type Span struct {
+	Offset int64 `json:"offset"`
+	Line   int64 `json:"line"`
+	Column int64 `json:"column"`
+	Length int   `json:"length"`
+}
+
+type Response struct {
+	Version     int           `json:"version"`
+	Files       []*File       `json:"files"`
+	Diagnostics []*Diagnostic `json:"diagnostics"`
+}
+
+// File is a generated file, its name is a slash separated path relative to
+// the output directory of the target
+type File struct {
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// Diagnostic is reported with the diagnostics of the source, errors fail the
+// compilation. The span is optional and points into the source of the request
+type Diagnostic struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	Span     *Span  `json:"span,omitempty"`
+}
+
+// NewRequest converts the tree into the form sent to plugins
+func NewRequest(sourceName string, tree *parser.EskemaTree, options emitter.Options) *Request {
+	kinds := make(map[string]string, len(tree.Declarations))
+
+	for _, declaration := range tree.Declarations {
+		switch declaration.(type) {
+		case *parser.SchemaDefinition:
+			kinds[declaration.Name()] = TypeSchema
+		case *parser.EnumDefinition:
+			kinds[declaration.Name()] = TypeEnum
+		case *parser.UnionDefinition:
+			kinds[declaration.Name()] = TypeUnion
+		case *parser.AliasDefinition:
+			kinds[declaration.Name()] = TypeAlias
+		}
+	}
+
+	request := &Request{
+		Version: Version,
+		Source:  sourceName,
+		Options: Options{
+			Package: options.Package,
+			Naming:  options.Naming,
+			Extra:   options.Extra,
+		},
+		Tree: &Tree{
+			Declarations: make([]*Declaration, 0, len(tree.Declarations)),
+			Comments:     nonNil(tree.Comments),
+		},
+	}
+
+	if request.Options.Extra == nil {
+		request.Options.Extra = map[string]string{}
+	}
+
+	for _, declaration := range tree.Declarations {
+		switch node := declaration.(type) {
+		case *parser.SchemaDefinition:
+			request.Tree.Declarations = append(request.Tree.Declarations, newSchema(tree, node, kinds))
+		case *parser.EnumDefinition:
+			request.Tree.Declarations = append(request.Tree.Declarations, newEnum(node))
+		case *parser.UnionDefinition:
+			request.Tree.Declarations = append(request.Tree.Declarations, newUnion(node))
+		case *parser.AliasDefinition:
+			request.Tree.Declarations = append(request.Tree.Declarations, newAlias(node, kinds))
+		case *parser.ConstDefinition:
+			request.Tree.Declarations = append(request.Tree.Declarations, newConst(node, kinds))
+		}
+	}
+
+	return request
+}
+
+func newSchema(tree *parser.EskemaTree, schema *parser.SchemaDefinition, kinds map[string]string) *Declaration {
+	declaration := &Declaration{
+		Kind:     KindSchema,
+		Name:     schema.Name(),
+		Comments: newComments(schema.Comments),
+		Generics: make([]string, 0, len(schema.Generics)),
+		Fields:   make([]*Field, 0, len(schema.Fields)),
+		Span:     newSpan(schema.Span),
+	}
+
+	generics := make(map[string]bool, len(schema.Generics))
+
+	for _, generic := range schema.Generics {
+		generics[generic.Id.Name] = true
+		declaration.Generics = append(declaration.Generics, generic.Id.Name)
+	}
+
+	if schema.Extends != nil {
+		declaration.Extends = schema.Extends.Id.Name
+	}
+
+	for _, include := range schema.Includes {
+		declaration.Includes = append(declaration.Includes, include.Id.Name)
+	}
+
+	for _, resolved := range tree.ResolveFields(schema) {
+		field := resolved.Field
+		converted := &Field{
+			Name:        field.Id.Name,
+			IsOptional:  field.IsOptional,
+			Type:        newType(field.Type, generics, kinds),
+			Annotations: newAnnotations(field.Annotations),
+			Comments:    newComments(field.Comments),
+			Span:        newSpan(field.Span),
+		}
+
+		if resolved.IsInherited(schema) {
+			converted.From = resolved.Owner.Name()
+		}
+
+		declaration.Fields = append(declaration.Fields, converted)
+	}
+
+	return declaration
+}
+
+func newEnum(enum *parser.EnumDefinition) *Declaration {
+	declaration := &Declaration{
+		Kind:     KindEnum,
+		Name:     enum.Name(),
+		Comments: newComments(enum.Comments),
+		Values:   make([]*EnumValue, 0, len(enum.Values)),
+		Span:     newSpan(enum.Span),
+	}
+
+	for _, value := range enum.Values {
+		converted := &EnumValue{
+			Name:     value.Id.Name,
+			Comments: newComments(value.Comments),
+			Span:     newSpan(value.Span),
+		}
+
+		if value.Value != nil {
+			converted.Value = value.Value.Raw
+		}
+
+		declaration.Values = append(declaration.Values, converted)
+	}
+
+	return declaration
+}
+
+func newUnion(union *parser.UnionDefinition) *Declaration {
+	declaration := &Declaration{
+		Kind:          KindUnion,
+		Name:          union.Name(),
+		Comments:      newComments(union.Comments),
+		Discriminator: union.Discriminator.Name,
+		Variants:      make([]*Variant, 0, len(union.Variants)),
+		Span:          newSpan(union.Span),
+	}
+
+	for _, variant := range union.Variants {
+		declaration.Variants = append(declaration.Variants, &Variant{
+			Name:     variant.Name(),
+			Comments: newComments(variant.Comments),
+			Span:     newSpan(variant.Span),
+		})
+	}
+
+	return declaration
+}
+
+func newAlias(alias *parser.AliasDefinition, kinds map[string]string) *Declaration {
+	declaration := &Declaration{
+		Kind:        KindAlias,
+		Name:        alias.Name(),
+		Comments:    newComments(alias.Comments),
+		Type:        newType(alias.Type, nil, kinds),
+		Annotations: newAnnotations(alias.Annotations),
+		Span:        newSpan(alias.Span),
+	}
+
+	return declaration
+}
+
+func newConst(constant *parser.ConstDefinition, kinds map[string]string) *Declaration {
+	return &Declaration{
+		Kind:     KindConst,
+		Name:     constant.Name(),
+		Comments: newComments(constant.Comments),
+		Type:     newType(constant.Type, nil, kinds),
+		Value:    constant.Value.Raw,
+		Span:     newSpan(constant.Span),
+	}
+}
+
+func newAnnotations(annotations []*parser.Annotation) []*Annotation {
+	converted := make([]*Annotation, 0, len(annotations))
+
+	for _, annotation := range annotations {
+		arguments := make([]string, 0, len(annotation.Arguments))
+
+		for _, argument := range annotation.Arguments {
+			arguments = append(arguments, argument.Raw)
+		}
+
+		converted = append(converted, &Annotation{Name: annotation.Id.Name, Arguments: arguments})
+	}
+
+	return converted
+}
+
+func newType(expression *parser.TypeExpression, generics map[string]bool, kinds map[string]string) *Type {
+	name := expression.Id.Name
+	kind := TypeUnknown
+
+	if isPrimitive, _ := syntax.IsPrimitiveType(name); isPrimitive {
+		kind = TypePrimitive
+	} else if generics[name] {
+		kind = TypeGeneric
+	} else if declared, exists := kinds[name]; exists {
+		kind = declared
+	}
+
+	typ := &Type{Name: name, Kind: kind}
+
+	for _, generic := range expression.Generics {
+		typ.Generics = append(typ.Generics, newType(generic, generics, kinds))
+	}
+
+	if expression.Size != nil {
+		typ.Size, _ = strconv.Atoi(expression.Size.Value)
+	}
+
+	return typ
+}
+
+func newSpan(span syntax.Span) *Span {
+	if !span.IsValid() {
+		return nil
+	}
+
+	return &Span{
+		Offset: span.Start.Offset,
+		Line:   span.Start.Line,
+		Column: span.Start.Column,
+		Length: span.Length,
+	}
+}
+
+func newComments(comments syntax.Comments) Comments {
+	return Comments{Leading: nonNil(comments.Leading), Trailing: comments.Trailing}
+}
+
+// nonNil keeps empty lists as [] in the JSON, plugins written in other
+// languages shouldn't have to tell null and empty apart
+func nonNil[T any](values []T) []T {
+	if values == nil {
+		return []T{}
+	}
+
+	return values
+}
@@ -0,0 +1,89 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"strings"
+)
+
+var ErrInvalidResponse = errors.New("plugin sent an invalid response")
+
+var severities = map[string]bool{"error": true, "warning": true, "note": true}
+
+// Run starts the executable, which is looked up on PATH when it has no path
+// separators, and sends it the request. The response is checked before being
+// returned so callers can trust the names of the files
+func Run(ctx context.Context, executable string, request *Request) (*Response, error) {
+	input, err := json.Marshal(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	command := exec.CommandContext(ctx, executable)
+	command.Stdin = bytes.NewReader(input)
+	command.Stdout = &stdout
+	command.Stderr = &stderr
+
+	if err := command.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%s: %w\n%s", executable, err, message)
+		}
+
+		return nil, fmt.Errorf("%s: %w", executable, err)
+	}
+
+	response := &Response{}
+	decoder := json.NewDecoder(&stdout)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(response); err != nil {
+		return nil, fmt.Errorf("%s: %w: %v", executable, ErrInvalidResponse, err)
+	}
+
+	if err := response.Verify(request.Version); err != nil {
+		return nil, fmt.Errorf("%s: %w: %v", executable, ErrInvalidResponse, err)
+	}
+
+	return response, nil
+}
+
+// Verify checks that the response answers a request of the version and that
+// every file can be written inside of the output directory
+func (r *Response) Verify(version int) error {
+	if r.Version != version {
+		return fmt.Errorf("got protocol version %d, expected %d", r.Version, version)
+	}
+
+	isNamed := make(map[string]bool, len(r.Files))
+
+	for _, file := range r.Files {
+		if file == nil || !fs.ValidPath(file.Name) || file.Name == "." {
+			return fmt.Errorf("file names must be relative slash separated paths, got %+v", file)
+		}
+
+		if isNamed[file.Name] {
+			return fmt.Errorf("file %s was generated more than once", file.Name)
+		}
+
+		isNamed[file.Name] = true
+	}
+
+	for _, diagnostic := range r.Diagnostics {
+		if diagnostic == nil || !severities[diagnostic.Severity] {
+			return fmt.Errorf("diagnostics must be an error, a warning or a note, got %+v", diagnostic)
+		}
+
+		if diagnostic.Message == "" {
+			return errors.New("diagnostics must have a message")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Generate answers a single request, returning an error fails the plugin and
+// shows the error to the user
+type Generate func(request *Request) (*Response, error)
+
+// Serve is the main function of plugins written in Go, it reads the request
+// from STDIN, answers it on STDOUT and exits with 1 when anything fails
+func Serve(generate Generate) {
+	if err := serve(os.Stdin, os.Stdout, generate); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func serve(stdin io.Reader, stdout io.Writer, generate Generate) error {
+	request := &Request{}
+
+	if err := json.NewDecoder(stdin).Decode(request); err != nil {
+		return fmt.Errorf("invalid request: %w", err)
+	}
+
+	if request.Version != Version {
+		return fmt.Errorf("protocol version %d is not supported, this plugin speaks version %d", request.Version, Version)
+	}
+
+	response, err := generate(request)
+
+	if err != nil {
+		return err
+	}
+
+	response.Version = request.Version
+
+	if response.Files == nil {
+		response.Files = []*File{}
+	}
+
+	if response.Diagnostics == nil {
+		response.Diagnostics = []*Diagnostic{}
+	}
+
+	return json.NewEncoder(stdout).Encode(response)
+}
@@ -0,0 +1,309 @@
+// Package templates generates files from a directory of text/template files
+// written by the user, for outputs too small to be worth a plugin
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/Haato3o/eskema/core/codestyle"
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/syntax"
+	"github.com/Haato3o/eskema/emitter"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+const (
+	// Extension marks the files that are templates, it is removed from the name
+	// of the generated files
+	Extension = ".tmpl"
+	// TypesFileName is the table mapType converts primitives with, $1, $2, ...
+	// are replaced by the generics of the type
+	TypesFileName = "types.json"
+)
+
+// Placeholders in the names of templates choose what they are rendered for,
+// templates without one are rendered once for each source
+const (
+	SourcePlaceholder = "[source]"
+	SchemaPlaceholder = "[schema]"
+	EnumPlaceholder   = "[enum]"
+	UnionPlaceholder  = "[union]"
+	AliasPlaceholder  = "[alias]"
+)
+
+var ErrNoTemplates = errors.New("no templates found")
+
+type File struct {
+	Name    string
+	Content string
+}
+
+// Data is what templates are rendered with, Schema, Enum, Union and Alias are
+// only set for templates rendered for each of them
+type Data struct {
+	// Source is the name of the source the tree was parsed from
+	Source  string
+	Options emitter.Options
+	Tree    *parser.EskemaTree
+	Schemas []*parser.SchemaDefinition
+	Enums   []*parser.EnumDefinition
+	Unions  []*parser.UnionDefinition
+	Aliases []*parser.AliasDefinition
+	Consts  []*parser.ConstDefinition
+	Schema  *parser.SchemaDefinition
+	Enum    *parser.EnumDefinition
+	Union   *parser.UnionDefinition
+	Alias   *parser.AliasDefinition
+}
+
+type Emitter struct {
+	templates *template.Template
+	// names are the templates that generate files, partials starting with _
+	// only define templates for the others to use
+	names   []string
+	types   map[string]string
+	options emitter.Options
+}
+
+// New parses every template of the directory, including the ones in its
+// subdirectories, which generate files in the same subdirectories
+func New(templates fs.FS, options emitter.Options) (*Emitter, error) {
+	e := &Emitter{
+		names:   make([]string, 0),
+		types:   make(map[string]string),
+		options: options,
+	}
+
+	e.templates = template.New("").Funcs(template.FuncMap{
+		"camel":      codestyle.ToCamelCase,
+		"pascal":     codestyle.ToPascalCase,
+		"snake":      codestyle.ToSnakeCase,
+		"fieldName":  options.FieldName,
+		"isOptional": isOptional,
+		"mapType":    e.mapType,
+		// fields, resolveType and constraints are bound to the tree being
+		// rendered by Emit
+		"fields":      func(*parser.SchemaDefinition) []*parser.FieldExpression { return nil },
+		"resolveType": func(typ *parser.TypeExpression) *parser.TypeExpression { return typ },
+		"constraints": func(*parser.FieldExpression) parser.Constraints { return parser.Constraints{} },
+	})
+
+	err := fs.WalkDir(templates, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || path.Ext(name) != Extension {
+			return err
+		}
+
+		content, err := fs.ReadFile(templates, name)
+
+		if err != nil {
+			return err
+		}
+
+		if _, err := e.templates.New(name).Parse(string(content)); err != nil {
+			return err
+		}
+
+		if !strings.HasPrefix(path.Base(name), "_") {
+			e.names = append(e.names, name)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(e.names) == 0 {
+		return nil, ErrNoTemplates
+	}
+
+	if err := e.readTypes(templates); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(e.names)
+
+	return e, nil
+}
+
+func (e *Emitter) readTypes(templates fs.FS) error {
+	content, err := fs.ReadFile(templates, TypesFileName)
+
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(content, &e.types); err != nil {
+		return fmt.Errorf("%s: %w", TypesFileName, err)
+	}
+
+	return nil
+}
+
+// Emit renders every template for the tree, the names of the files are the
+// names of the templates with their placeholders replaced
+func (e *Emitter) Emit(sourceName string, tree *parser.EskemaTree) ([]*File, error) {
+	data := Data{
+		Source:  sourceName,
+		Options: e.options,
+		Tree:    tree,
+		Schemas: make([]*parser.SchemaDefinition, 0),
+		Enums:   make([]*parser.EnumDefinition, 0),
+		Unions:  make([]*parser.UnionDefinition, 0),
+		Aliases: make([]*parser.AliasDefinition, 0),
+		Consts:  emitter.Consts(tree),
+	}
+
+	for _, declaration := range tree.Declarations {
+		switch node := declaration.(type) {
+		case *parser.SchemaDefinition:
+			data.Schemas = append(data.Schemas, node)
+		case *parser.EnumDefinition:
+			data.Enums = append(data.Enums, node)
+		case *parser.UnionDefinition:
+			data.Unions = append(data.Unions, node)
+		case *parser.AliasDefinition:
+			data.Aliases = append(data.Aliases, node)
+		}
+	}
+
+	e.templates.Funcs(template.FuncMap{
+		"fields":      tree.Fields,
+		"resolveType": tree.ResolveType,
+		"constraints": tree.Constraints,
+	})
+
+	files := make([]*File, 0, len(e.names))
+	source := strings.TrimSuffix(sourceName, path.Ext(sourceName))
+
+	for _, name := range e.names {
+		outputName := strings.ReplaceAll(strings.TrimSuffix(name, Extension), SourcePlaceholder, source)
+
+		switch {
+		case strings.Contains(name, SchemaPlaceholder):
+			for _, schema := range data.Schemas {
+				perSchema := data
+				perSchema.Schema = schema
+
+				file, err := e.render(name, strings.ReplaceAll(outputName, SchemaPlaceholder, schema.Name()), perSchema)
+
+				if err != nil {
+					return nil, err
+				}
+
+				files = append(files, file)
+			}
+		case strings.Contains(name, EnumPlaceholder):
+			for _, enum := range data.Enums {
+				perEnum := data
+				perEnum.Enum = enum
+
+				file, err := e.render(name, strings.ReplaceAll(outputName, EnumPlaceholder, enum.Name()), perEnum)
+
+				if err != nil {
+					return nil, err
+				}
+
+				files = append(files, file)
+			}
+		case strings.Contains(name, UnionPlaceholder):
+			for _, union := range data.Unions {
+				perUnion := data
+				perUnion.Union = union
+
+				file, err := e.render(name, strings.ReplaceAll(outputName, UnionPlaceholder, union.Name()), perUnion)
+
+				if err != nil {
+					return nil, err
+				}
+
+				files = append(files, file)
+			}
+		case strings.Contains(name, AliasPlaceholder):
+			for _, alias := range data.Aliases {
+				perAlias := data
+				perAlias.Alias = alias
+
+				file, err := e.render(name, strings.ReplaceAll(outputName, AliasPlaceholder, alias.Name()), perAlias)
+
+				if err != nil {
+					return nil, err
+				}
+
+				files = append(files, file)
+			}
+		default:
+			file, err := e.render(name, outputName, data)
+
+			if err != nil {
+				return nil, err
+			}
+
+			files = append(files, file)
+		}
+	}
+
+	return files, nil
+}
+
+func (e *Emitter) render(name string, outputName string, data Data) (*File, error) {
+	var buffer bytes.Buffer
+
+	if err := e.templates.ExecuteTemplate(&buffer, name, data); err != nil {
+		return nil, err
+	}
+
+	return &File{Name: outputName, Content: buffer.String()}, nil
+}
+
+// mapType converts the type with the table of the templates, types that aren't
+// in it keep their name and primitives have to be in it
+func (e *Emitter) mapType(typ *parser.TypeExpression) (string, error) {
+	generics := make([]string, 0, len(typ.Generics))
+
+	for _, generic := range typ.Generics {
+		mapped, err := e.mapType(generic)
+
+		if err != nil {
+			return "", err
+		}
+
+		generics = append(generics, mapped)
+	}
+
+	if mapped, exists := e.types[typ.Id.Name]; exists {
+		// from the last generic so $1 doesn't replace the start of $10
+		for i := len(generics); i > 0; i-- {
+			mapped = strings.ReplaceAll(mapped, fmt.Sprintf("$%d", i), generics[i-1])
+		}
+
+		if typ.Size != nil {
+			mapped = strings.ReplaceAll(mapped, "$size", typ.Size.Value)
+		}
+
+		return mapped, nil
+	}
+
+	if isPrimitive, _ := syntax.IsPrimitiveType(typ.Id.Name); isPrimitive {
+		return "", fmt.Errorf("primitive %s has no type in %s", typ.Id.Name, TypesFileName)
+	}
+
+	if len(generics) == 0 {
+		return typ.Id.Name, nil
+	}
+
+	return fmt.Sprintf("%s<%s>", typ.Id.Name, strings.Join(generics, ", ")), nil
+}
+
+func isOptional(field *parser.FieldExpression) bool {
+	return field.IsOptional
+}
@@ -0,0 +1,133 @@
+package templates
+
+import (
+	"errors"
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/syntax"
+	"github.com/Haato3o/eskema/emitter"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+const source = `enum Status { ONLINE, OFFLINE };
+schema UserProfile { user_id: Int64, friends: Array<UserProfile>?, status: Status };`
+
+func TestEmit(t *testing.T) {
+	templates := fstest.MapFS{
+		"types.json":               {Data: []byte(`{"Int64": "Long", "Array": "List<$1>"}`)},
+		"_field.tmpl":              {Data: []byte(`{{define "field"}}{{fieldName .Id.Name}}: {{mapType .Type}}{{if isOptional .}}?{{end}}{{end}}`)},
+		"[source].md.tmpl":         {Data: []byte(`{{range .Schemas}}{{snake .Name}} {{end}}{{range .Enums}}{{camel .Name}}{{end}}`)},
+		"models/[schema].kt.tmpl":  {Data: []byte(`{{.Options.Package}}.{{pascal .Schema.Name}}({{range .Schema.Fields}}{{template "field" .}};{{end}})`)},
+		"models/[enum].kt.tmpl":    {Data: []byte(`{{.Enum.Name}} from {{.Source}}`)},
+		"README.txt":               {Data: []byte("not a template")},
+		"nested/[source].txt.tmpl": {Data: []byte(`{{len .Tree.Declarations}}`)},
+	}
+
+	tree := parser.New(syntax.NewLexer([]byte(source), "users.skm").Lex()).Parse()
+	templateEmitter, err := New(templates, emitter.Options{Package: "com.example", Naming: emitter.CamelCase})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := templateEmitter.Emit("api/users.skm", tree)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []*File{
+		{"api/users.md", "user_profile status"},
+		{"models/Status.kt", "Status from api/users.skm"},
+		{"models/UserProfile.kt", "com.example.UserProfile(userId: Long;friends: List<UserProfile>?;status: Status;)"},
+		{"nested/api/users.txt", "2"},
+	}
+
+	if !reflect.DeepEqual(files, expected) {
+		for _, file := range files {
+			t.Logf("%+v", file)
+		}
+
+		t.Errorf("got %d files, expected %d", len(files), len(expected))
+	}
+}
+
+func TestEmitResolvesInheritedFields(t *testing.T) {
+	templates := fstest.MapFS{
+		"[schema].txt.tmpl": {Data: []byte(`{{range fields .Schema}}{{.Id.Name}} {{end}}`)},
+	}
+
+	source := "schema A { id: Int64 };\nschema B extends A { name: String };"
+	tree := parser.New(syntax.NewLexer([]byte(source), "b.skm").Lex()).Parse()
+	templateEmitter, err := New(templates, emitter.Options{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := templateEmitter.Emit("b.skm", tree)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) != 2 || files[1].Content != "id name " {
+		t.Errorf("got %+v, expected B to have the fields of A", files)
+	}
+}
+
+func TestEmitResolvesAliases(t *testing.T) {
+	templates := fstest.MapFS{
+		"types.json":        {Data: []byte(`{"Int64": "int", "Array": "list[$1]"}`)},
+		"[alias].txt.tmpl":  {Data: []byte(`{{.Alias.Name}} = {{mapType .Alias.Type}}`)},
+		"[schema].txt.tmpl": {Data: []byte(`{{range .Schema.Fields}}{{mapType (resolveType .Type)}} {{(constraints .).MinLength}}{{end}}`)},
+	}
+
+	source := "alias Id = Int64;\nalias Ids = Array<Id> @nonEmpty;\nschema A { ids: Ids };"
+	tree := parser.New(syntax.NewLexer([]byte(source), "a.skm").Lex()).Parse()
+	templateEmitter, err := New(templates, emitter.Options{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := templateEmitter.Emit("a.skm", tree)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []*File{
+		{"Id.txt", "Id = int"},
+		{"Ids.txt", "Ids = list[Id]"},
+		{"A.txt", "list[int] 1"},
+	}
+
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("got %+v, expected aliases to be rendered and resolved", files)
+	}
+}
+
+func TestEmitFailsOnUnmappedPrimitives(t *testing.T) {
+	templates := fstest.MapFS{
+		"[schema].txt.tmpl": {Data: []byte(`{{range .Schema.Fields}}{{mapType .Type}}{{end}}`)},
+	}
+
+	tree := parser.New(syntax.NewLexer([]byte(source), "users.skm").Lex()).Parse()
+	templateEmitter, err := New(templates, emitter.Options{})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := templateEmitter.Emit("users.skm", tree); err == nil {
+		t.Error("expected an error for primitives missing from types.json")
+	}
+}
+
+func TestNewRequiresTemplates(t *testing.T) {
+	if _, err := New(fstest.MapFS{"_partial.tmpl": {}}, emitter.Options{}); !errors.Is(err, ErrNoTemplates) {
+		t.Errorf("got %v, expected %v", err, ErrNoTemplates)
+	}
+}
@@ -1,6 +1,6 @@
 namespace Example;
 
-enum State {
+public enum State {
     TEST_1,
     TEST_2,
     TEST_3
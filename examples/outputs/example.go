@@ -8,18 +8,18 @@ const (
 )
 
 type SimpleSchema struct {
-    value1 string
-    value2 map[string]int32
-    value3 bool
+    Value1 string `json:"value1"`
+    Value2 map[string]int32 `json:"value2"`
+    Value3 bool `json:"value3"`
 }
 
 type SimpleSchemaWithGenerics[T any] struct {
-    value1 T
-    value2 []T
+    Value1 T `json:"value1"`
+    Value2 []T `json:"value2"`
 }
 
-type ComplexSchema[TIn any, TOut any] struct {
-    value1 *map[TIn]SimpleSchemaWithGenerics[TOut]
-    value2 *[][]string
+type ComplexSchema[TIn comparable, TOut any] struct {
+    Value1 *map[TIn]SimpleSchemaWithGenerics[TOut] `json:"value1"`
+    Value2 *[][]string `json:"value2"`
 }
 
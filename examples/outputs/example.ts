@@ -0,0 +1,34 @@
+import { z } from "zod";
+
+export const StateSchema = z.enum([
+    "TEST_1",
+    "TEST_2",
+    "TEST_3",
+]);
+export type State = z.infer<typeof StateSchema>;
+
+export const SimpleSchemaSchema = z.object({
+    value1: z.string(),
+    value2: z.record(z.string(), z.number().int().min(-2147483648).max(2147483647)),
+    value3: z.boolean(),
+});
+export type SimpleSchema = z.infer<typeof SimpleSchemaSchema>;
+
+export type SimpleSchemaWithGenerics<T> = {
+    value1: T;
+    value2: Array<T>;
+};
+export const SimpleSchemaWithGenericsSchema = <T extends z.ZodTypeAny>(tSchema: T) => z.object({
+    value1: tSchema,
+    value2: z.array(tSchema),
+});
+
+export type ComplexSchema<TIn extends string | number, TOut> = {
+    value1?: Record<TIn, SimpleSchemaWithGenerics<TOut>> | null;
+    value2?: Array<Array<string>> | null;
+};
+export const ComplexSchemaSchema = <TIn extends z.ZodType<string | number>, TOut extends z.ZodTypeAny>(tInSchema: TIn, tOutSchema: TOut) => z.object({
+    value1: z.record(tInSchema, SimpleSchemaWithGenericsSchema(tOutSchema)).nullable().optional(),
+    value2: z.array(z.array(z.string())).nullable().optional(),
+});
+
@@ -0,0 +1,194 @@
+// eskema-gen-markdown is the reference plugin, it documents every schema and
+// enum of a source in a Markdown file. Install it on PATH and generate with
+// --language plugin:eskema-gen-markdown
+package main
+
+import (
+	"fmt"
+	"github.com/Haato3o/eskema/emitter/plugin"
+	"path"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	plugin.Serve(generate)
+}
+
+func generate(request *plugin.Request) (*plugin.Response, error) {
+	var builder strings.Builder
+	diagnostics := make([]*plugin.Diagnostic, 0)
+
+	builder.WriteString(fmt.Sprintf("# %s\n", request.Source))
+
+	for _, declaration := range request.Tree.Declarations {
+		builder.WriteString(fmt.Sprintf("\n## %s\n\n", declaration.Name))
+
+		if description := describe(declaration.Comments); description != "" {
+			builder.WriteString(description + "\n\n")
+		} else {
+			diagnostics = append(diagnostics, &plugin.Diagnostic{
+				Severity: "note",
+				Code:     "MD0001",
+				Message:  fmt.Sprintf("%s %s has no documentation", declaration.Kind, declaration.Name),
+				Span:     declaration.Span,
+			})
+		}
+
+		switch declaration.Kind {
+		case plugin.KindSchema:
+			writeSchema(&builder, declaration)
+		case plugin.KindEnum:
+			writeEnum(&builder, declaration)
+		case plugin.KindUnion:
+			writeUnion(&builder, declaration)
+		case plugin.KindAlias:
+			writeAlias(&builder, declaration)
+		case plugin.KindConst:
+			writeConst(&builder, declaration)
+		}
+	}
+
+	return &plugin.Response{
+		Files:       []*plugin.File{{Name: markdownName(request.Source), Content: builder.String()}},
+		Diagnostics: diagnostics,
+	}, nil
+}
+
+func writeSchema(builder *strings.Builder, schema *plugin.Declaration) {
+	if len(schema.Generics) > 0 {
+		builder.WriteString(fmt.Sprintf("Generics: `%s`\n\n", strings.Join(schema.Generics, "`, `")))
+	}
+
+	if schema.Extends != "" || len(schema.Includes) > 0 {
+		parents := make([]string, 0, len(schema.Includes)+1)
+
+		for _, parent := range append([]string{schema.Extends}, schema.Includes...) {
+			if parent != "" {
+				parents = append(parents, fmt.Sprintf("[`%s`](#%s)", parent, strings.ToLower(parent)))
+			}
+		}
+
+		builder.WriteString(fmt.Sprintf("Inherits from %s\n\n", strings.Join(parents, ", ")))
+	}
+
+	builder.WriteString("| Field | Type | Required | Description |\n")
+	builder.WriteString("| --- | --- | --- | --- |\n")
+
+	for _, field := range schema.Fields {
+		required := "yes"
+
+		if field.IsOptional {
+			required = "no"
+		}
+
+		builder.WriteString(fmt.Sprintf("| `%s` | %s%s | %s | %s |\n", field.Name, typeName(field.Type), annotations(field.Annotations), required, describe(field.Comments)))
+	}
+}
+
+func writeEnum(builder *strings.Builder, enum *plugin.Declaration) {
+	builder.WriteString("| Value | Description |\n")
+	builder.WriteString("| --- | --- |\n")
+
+	for _, value := range enum.Values {
+		name := value.Name
+
+		if value.Value != "" {
+			name += " = " + value.Value
+		}
+
+		builder.WriteString(fmt.Sprintf("| `%s` | %s |\n", escapeCell(name), describe(value.Comments)))
+	}
+}
+
+func writeUnion(builder *strings.Builder, union *plugin.Declaration) {
+	builder.WriteString(fmt.Sprintf("One of the following, told apart by the value of `%s`:\n\n", union.Discriminator))
+	builder.WriteString("| Variant | Description |\n")
+	builder.WriteString("| --- | --- |\n")
+
+	for _, variant := range union.Variants {
+		builder.WriteString(fmt.Sprintf("| [`%s`](#%s) | %s |\n", variant.Name, strings.ToLower(variant.Name), describe(variant.Comments)))
+	}
+}
+
+func writeAlias(builder *strings.Builder, alias *plugin.Declaration) {
+	builder.WriteString(fmt.Sprintf("Another name for %s\n", typeName(alias.Type)))
+
+	if len(alias.Annotations) == 0 {
+		return
+	}
+
+	builder.WriteString("\n| Constraint | Arguments |\n")
+	builder.WriteString("| --- | --- |\n")
+
+	for _, annotation := range alias.Annotations {
+		builder.WriteString(fmt.Sprintf("| `@%s` | %s |\n", annotation.Name, escapeCell(strings.Join(annotation.Arguments, ", "))))
+	}
+}
+
+func writeConst(builder *strings.Builder, constant *plugin.Declaration) {
+	builder.WriteString(fmt.Sprintf("Constant %s with the value `%s`\n", typeName(constant.Type), constant.Value))
+}
+
+// annotations follow the type of a field the way they are written
+func annotations(annotations []*plugin.Annotation) string {
+	written := ""
+
+	for _, annotation := range annotations {
+		written += fmt.Sprintf(" `@%s", annotation.Name)
+
+		if len(annotation.Arguments) > 0 {
+			written += fmt.Sprintf("(%s)", strings.Join(annotation.Arguments, ", "))
+		}
+
+		written += "`"
+	}
+
+	return escapeCell(written)
+}
+
+// escapeCell keeps pipes in patterns from ending the cell of the table
+func escapeCell(value string) string {
+	return strings.ReplaceAll(value, "|", "\\|")
+}
+
+// typeName links types declared in the same source to their section
+func typeName(typ *plugin.Type) string {
+	name := fmt.Sprintf("`%s`", typ.Name)
+
+	if typ.Kind == plugin.TypeSchema || typ.Kind == plugin.TypeEnum || typ.Kind == plugin.TypeUnion || typ.Kind == plugin.TypeAlias {
+		name = fmt.Sprintf("[%s](#%s)", name, strings.ToLower(typ.Name))
+	}
+
+	if len(typ.Generics) == 0 {
+		return name
+	}
+
+	generics := make([]string, 0, len(typ.Generics))
+
+	for _, generic := range typ.Generics {
+		generics = append(generics, typeName(generic))
+	}
+
+	if typ.Size > 0 {
+		generics = append(generics, strconv.Itoa(typ.Size))
+	}
+
+	return fmt.Sprintf("%s<%s>", name, strings.Join(generics, ", "))
+}
+
+func describe(comments plugin.Comments) string {
+	lines := make([]string, 0, len(comments.Leading)+1)
+
+	for _, comment := range append(comments.Leading, comments.Trailing) {
+		if line := strings.TrimSpace(strings.TrimPrefix(comment, "//")); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, " ")
+}
+
+func markdownName(sourceName string) string {
+	return strings.TrimSuffix(sourceName, path.Ext(sourceName)) + ".md"
+}
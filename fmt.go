@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Haato3o/eskema/cli"
+	"github.com/Haato3o/eskema/core/diff"
+	"github.com/Haato3o/eskema/core/printer"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const eskemaExtension = ".skm"
+
+func runFmt(arguments []string) {
+	args := cli.ParseFmtArguments(arguments)
+
+	if err := args.VerifyRequired(); err != nil {
+		log.Fatalln(err)
+	}
+
+	if len(args.FileNames) == 0 {
+		if args.ShouldWrite {
+			log.Fatalln("cannot use -w with standard input")
+		}
+
+		source, err := io.ReadAll(os.Stdin)
+
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		if err := formatSource(args, source, "<standard input>"); err != nil {
+			reportError(args.DiagnosticsFormat, err)
+			os.Exit(2)
+		}
+
+		return
+	}
+
+	hasErrors := false
+
+	for _, fileName := range collectEskemaFiles(args.FileNames) {
+		source, err := os.ReadFile(fileName)
+
+		if err == nil {
+			err = formatSource(args, source, fileName)
+		}
+
+		if err != nil {
+			reportError(args.DiagnosticsFormat, err)
+			hasErrors = true
+		}
+	}
+
+	if hasErrors {
+		os.Exit(2)
+	}
+}
+
+func formatSource(args *cli.FmtArguments, source []byte, fileName string) error {
+	formatted, err := printer.Format(source, fileName)
+
+	if err != nil {
+		return err
+	}
+
+	isFormatted := formatted == string(source)
+
+	if args.ShouldList && !isFormatted {
+		fmt.Println(fileName)
+	}
+
+	if args.ShouldPrintDiff && !isFormatted {
+		fmt.Print(diff.Unified(fileName+".orig", fileName, string(source), formatted))
+	}
+
+	if args.ShouldWrite && !isFormatted {
+		return os.WriteFile(fileName, []byte(formatted), 0644)
+	}
+
+	if !args.ShouldList && !args.ShouldPrintDiff && !args.ShouldWrite {
+		fmt.Print(formatted)
+	}
+
+	return nil
+}
+
+// collectEskemaFiles expands directories into every eskema file inside of them
+func collectEskemaFiles(paths []string) []string {
+	files := make([]string, 0, len(paths))
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+
+		if err != nil || !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		_ = filepath.WalkDir(path, func(file string, entry fs.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() && strings.HasSuffix(file, eskemaExtension) {
+				files = append(files, file)
+			}
+
+			return nil
+		})
+	}
+
+	return files
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"github.com/Haato3o/eskema/cli"
+	"github.com/Haato3o/eskema/core/project"
+	"github.com/Haato3o/eskema/core/syntax"
+	"github.com/Haato3o/eskema/emitter"
+	"github.com/Haato3o/eskema/pkg/eskema"
+	"log"
+	"os"
+	"path/filepath"
+)
+
+// runGenerate builds every target of the project from a single parse of its
+// inputs, nothing is written when a schema has errors
+func runGenerate(arguments []string) {
+	args := cli.ParseGenerateArguments(arguments)
+
+	if err := args.VerifyRequired(); err != nil {
+		log.Fatalln(err)
+	}
+
+	projectFileName, err := findProjectFile(args.ProjectFileName)
+
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	if args.ShouldWatch {
+		watchProject(projectFileName, args.DiagnosticsFormat)
+		return
+	}
+
+	eskemaProject, err := project.Load(projectFileName)
+
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	files, found, err := buildProject(eskemaProject)
+
+	if len(found) > 0 {
+		reportDiagnostics(args.DiagnosticsFormat, found)
+	}
+
+	if err != nil {
+		if len(found) == 0 {
+			log.Println(err)
+		}
+
+		os.Exit(1)
+	}
+
+	if args.ShouldCheck {
+		checkProject(eskemaProject, files)
+		return
+	}
+
+	for _, file := range files {
+		if err := writeGeneratedFile(eskemaProject, file); err != nil {
+			log.Fatalln(err)
+		}
+	}
+}
+
+// checkProject prints a diff for every file that would change and exits with 1
+// when there is at least one
+func checkProject(eskemaProject *project.Project, files []*eskema.File) {
+	staleFiles := 0
+
+	for _, file := range files {
+		isUpToDate, err := checkGeneratedFile(generatedFileName(eskemaProject, file), file.Content)
+
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		if !isUpToDate {
+			staleFiles++
+		}
+	}
+
+	if staleFiles > 0 {
+		log.Printf("%d of %d generated files are out of date, run eskema generate to update them\n", staleFiles, len(files))
+		os.Exit(1)
+	}
+}
+
+func findProjectFile(fileName string) (string, error) {
+	if fileName != "" {
+		return fileName, nil
+	}
+
+	return project.Find(".")
+}
+
+func buildProject(eskemaProject *project.Project) ([]*eskema.File, []*syntax.Diagnostic, error) {
+	sources, err := eskema.SourcesFromFS(os.DirFS(eskemaProject.Directory), eskemaProject.Inputs...)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(sources) == 0 {
+		return nil, nil, fmt.Errorf("no schemas match the inputs %v", eskemaProject.Inputs)
+	}
+
+	return eskema.Build(context.Background(), sources, projectTargets(eskemaProject), nil)
+}
+
+func projectTargets(eskemaProject *project.Project) []*eskema.Target {
+	targets := make([]*eskema.Target, 0, len(eskemaProject.Targets))
+
+	for _, target := range eskemaProject.Targets {
+		eskemaTarget := &eskema.Target{
+			Language:  target.Language,
+			Directory: target.Output,
+			Options: emitter.Options{
+				Package: target.Package,
+				Naming:  target.Naming,
+				Extra:   target.Options,
+			},
+		}
+
+		if target.Templates != "" {
+			eskemaTarget.Templates = os.DirFS(filepath.Join(eskemaProject.Directory, filepath.FromSlash(target.Templates)))
+		}
+
+		targets = append(targets, eskemaTarget)
+	}
+
+	return targets
+}
+
+func generatedFileName(eskemaProject *project.Project, file *eskema.File) string {
+	return filepath.Join(eskemaProject.Directory, filepath.FromSlash(file.Name))
+}
+
+func writeGeneratedFile(eskemaProject *project.Project, file *eskema.File) error {
+	fileName := generatedFileName(eskemaProject, file)
+
+	if err := os.MkdirAll(filepath.Dir(fileName), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(fileName, []byte(file.Content), 0644)
+}
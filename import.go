@@ -0,0 +1,35 @@
+package main
+
+import (
+	"github.com/Haato3o/eskema/cli"
+	"github.com/Haato3o/eskema/core/printer"
+	"log"
+	"os"
+)
+
+func runImport(arguments []string) {
+	args := cli.ParseImportArguments(arguments)
+
+	if err := args.VerifyRequired(); err != nil {
+		log.Fatalln(err)
+	}
+
+	schemaImporter, err := cli.GetSchemaImporter(args.Format)
+
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	tree, warnings, err := schemaImporter.Import(args.FileName)
+
+	for _, warning := range warnings {
+		log.Println(warning)
+	}
+
+	if err != nil {
+		reportError(cli.TextDiagnostics, err)
+		os.Exit(1)
+	}
+
+	writeOutput(args.Output, printer.Print(tree))
+}
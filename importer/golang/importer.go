@@ -0,0 +1,359 @@
+package golang
+
+import (
+	"fmt"
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/syntax"
+	"github.com/Haato3o/eskema/importer"
+	"go/ast"
+	goimporter "go/importer"
+	goparser "go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var basicPrimitives = map[types.BasicKind]string{
+	types.Bool:    "Bool",
+	types.String:  "String",
+	types.Int:     "Int64",
+	types.Int8:    "Int8",
+	types.Int16:   "Int16",
+	types.Int32:   "Int32",
+	types.Int64:   "Int64",
+	types.Uint:    "UInt64",
+	types.Uint8:   "UInt8",
+	types.Uint16:  "UInt16",
+	types.Uint32:  "UInt32",
+	types.Uint64:  "UInt64",
+	types.Float32: "Float",
+	types.Float64: "Double",
+}
+
+var namedPrimitives = map[string]string{
+	"time.Time":     "DateTime",
+	"time.Duration": "Duration",
+	"net/url.URL":   "Url",
+}
+
+type GoLangImporter struct{}
+
+// Import loads the Go package in the given directory and converts its exported
+// structs into schemas and its typed constants into enums
+func (i *GoLangImporter) Import(directory string) (*parser.EskemaTree, []*importer.Warning, error) {
+	fileSet := token.NewFileSet()
+	entries, err := os.ReadDir(directory)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	files := make([]*ast.File, 0)
+
+	for _, entry := range entries {
+		name := entry.Name()
+
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		file, err := goparser.ParseFile(fileSet, filepath.Join(directory, name), nil, goparser.SkipObjectResolution)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		files = append(files, file)
+	}
+
+	if len(files) == 0 {
+		return nil, nil, fmt.Errorf("%s: no Go files found", directory)
+	}
+
+	converter := &goConverter{
+		fileSet:  fileSet,
+		warnings: make([]*importer.Warning, 0),
+	}
+
+	config := &types.Config{
+		Importer: goimporter.ForCompiler(fileSet, "source", nil),
+		Error: func(err error) {
+			if typeErr, isTypeErr := err.(types.Error); isTypeErr && !typeErr.Soft {
+				converter.warn(typeErr.Pos, typeErr.Msg)
+			}
+		},
+	}
+
+	pkg, _ := config.Check(files[0].Name.Name, fileSet, files, nil)
+	converter.pkg = pkg
+
+	return converter.convert(), converter.warnings, nil
+}
+
+type goConverter struct {
+	fileSet  *token.FileSet
+	pkg      *types.Package
+	warnings []*importer.Warning
+}
+
+func (c *goConverter) convert() *parser.EskemaTree {
+	tree := &parser.EskemaTree{
+		Declarations: make([]parser.Declaration, 0),
+	}
+
+	scope := c.pkg.Scope()
+	objects := make([]types.Object, 0, len(scope.Names()))
+
+	for _, name := range scope.Names() {
+		objects = append(objects, scope.Lookup(name))
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Pos() < objects[j].Pos()
+	})
+
+	enumValues := c.collectEnumValues(objects)
+
+	for _, object := range objects {
+		typeName, isTypeName := object.(*types.TypeName)
+
+		if !isTypeName || !typeName.Exported() || typeName.IsAlias() {
+			continue
+		}
+
+		named, isNamed := typeName.Type().(*types.Named)
+
+		if !isNamed {
+			continue
+		}
+
+		if structType, isStruct := named.Underlying().(*types.Struct); isStruct {
+			tree.Declarations = append(tree.Declarations, c.convertStruct(named, structType))
+		} else if values, isEnum := enumValues[typeName]; isEnum {
+			tree.Declarations = append(tree.Declarations, &parser.EnumDefinition{
+				Id:     parser.IdentifierExpression{Name: typeName.Name()},
+				Values: values,
+			})
+		}
+	}
+
+	return tree
+}
+
+// collectEnumValues finds the typed constant pattern used for enums in Go, string
+// constants are named after their value since that's what ends up on the wire
+func (c *goConverter) collectEnumValues(objects []types.Object) map[*types.TypeName][]*parser.EnumValue {
+	enums := make(map[*types.TypeName][]*parser.EnumValue)
+
+	for _, object := range objects {
+		constant, isConstant := object.(*types.Const)
+
+		if !isConstant || !constant.Exported() {
+			continue
+		}
+
+		named, isNamed := constant.Type().(*types.Named)
+
+		if !isNamed || named.Obj().Pkg() != c.pkg {
+			continue
+		}
+
+		value := constant.Name()
+
+		if basic, isBasic := named.Underlying().(*types.Basic); isBasic && basic.Info()&types.IsString != 0 {
+			literal := strings.Trim(constant.Val().ExactString(), "\"")
+			value = importer.ToIdentifier(literal)
+
+			if value != literal {
+				c.warn(constant.Pos(), fmt.Sprintf("'%s' is not a valid identifier and was imported as '%s'", literal, value))
+			}
+		}
+
+		enums[named.Obj()] = append(enums[named.Obj()], importer.NewEnumValue(value))
+	}
+
+	return enums
+}
+
+func (c *goConverter) convertStruct(named *types.Named, structType *types.Struct) *parser.SchemaDefinition {
+	schema := &parser.SchemaDefinition{
+		Id:     parser.IdentifierExpression{Name: named.Obj().Name()},
+		Fields: c.convertFields(structType),
+	}
+
+	typeParams := named.TypeParams()
+
+	for i := 0; i < typeParams.Len(); i++ {
+		schema.Generics = append(schema.Generics, importer.NewType(typeParams.At(i).Obj().Name()))
+	}
+
+	return schema
+}
+
+// convertFields follows encoding/json rules, unexported and "-" fields are skipped
+// and untagged embedded structs have their fields promoted
+func (c *goConverter) convertFields(structType *types.Struct) []*parser.FieldExpression {
+	fields := make([]*parser.FieldExpression, 0, structType.NumFields())
+
+	for i := 0; i < structType.NumFields(); i++ {
+		field := structType.Field(i)
+		name, options, isIgnored := parseJsonTag(structType.Tag(i))
+
+		if isIgnored {
+			continue
+		}
+
+		if field.Embedded() && name == "" {
+			if embedded, isStruct := dereference(field.Type()).Underlying().(*types.Struct); isStruct {
+				fields = append(fields, c.convertFields(embedded)...)
+				continue
+			}
+		}
+
+		if !field.Exported() {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name()
+		}
+
+		if identifier := importer.ToIdentifier(name); identifier != name {
+			c.warn(field.Pos(), fmt.Sprintf("'%s' is not a valid identifier and was imported as '%s'", name, identifier))
+			name = identifier
+		}
+
+		fieldType := field.Type()
+		_, isPointer := fieldType.(*types.Pointer)
+
+		fields = append(fields, &parser.FieldExpression{
+			Id:         parser.IdentifierExpression{Name: name},
+			IsOptional: isPointer || strings.Contains(","+options+",", ",omitempty,"),
+			Type:       c.convertType(dereference(fieldType), field.Pos()),
+		})
+	}
+
+	return fields
+}
+
+func (c *goConverter) convertType(typ types.Type, position token.Pos) *parser.TypeExpression {
+	switch value := typ.(type) {
+	case *types.Basic:
+		if primitive, isPrimitive := basicPrimitives[value.Kind()]; isPrimitive {
+			return importer.NewType(primitive)
+		}
+	case *types.Slice:
+		return importer.NewType("Array", c.convertType(value.Elem(), position))
+	case *types.Array:
+		array := importer.NewType("Array", c.convertType(value.Elem(), position))
+		size := strconv.FormatInt(value.Len(), 10)
+		array.Size = &parser.LiteralExpression{Kind: parser.NumberLiteral, Raw: size, Value: size}
+
+		return array
+	case *types.Map:
+		// map[T]struct{} is how sets are written in Go
+		if elem, isStruct := value.Elem().(*types.Struct); isStruct && elem.NumFields() == 0 {
+			return importer.NewType("Set", c.convertType(value.Key(), position))
+		}
+
+		return importer.NewType("Map", c.convertType(value.Key(), position), c.convertType(value.Elem(), position))
+	case *types.Pointer:
+		return c.convertType(value.Elem(), position)
+	case *types.TypeParam:
+		return importer.NewType(value.Obj().Name())
+	case *types.Named:
+		return c.convertNamed(value, position)
+	}
+
+	c.warn(position, fmt.Sprintf("type '%s' has no eskema equivalent and was imported as String", typ))
+
+	return importer.NewType("String")
+}
+
+func (c *goConverter) convertNamed(named *types.Named, position token.Pos) *parser.TypeExpression {
+	obj := named.Obj()
+
+	if obj.Pkg() != nil {
+		if primitive, isPrimitive := namedPrimitives[obj.Pkg().Path()+"."+obj.Name()]; isPrimitive {
+			return importer.NewType(primitive)
+		}
+	}
+
+	if obj.Pkg() != c.pkg || !obj.Exported() {
+		c.warn(position, fmt.Sprintf("type '%s' is not declared in this package, its underlying type was imported instead", named))
+
+		return c.convertType(named.Underlying(), position)
+	}
+
+	if _, isStruct := named.Underlying().(*types.Struct); !isStruct && !c.hasConstants(obj) {
+		return c.convertType(named.Underlying(), position)
+	}
+
+	typeExpression := importer.NewType(obj.Name())
+	typeArgs := named.TypeArgs()
+
+	for i := 0; i < typeArgs.Len(); i++ {
+		typeExpression.Generics = append(typeExpression.Generics, c.convertType(typeArgs.At(i), position))
+	}
+
+	return typeExpression
+}
+
+func (c *goConverter) hasConstants(obj *types.TypeName) bool {
+	scope := c.pkg.Scope()
+
+	for _, name := range scope.Names() {
+		if constant, isConstant := scope.Lookup(name).(*types.Const); isConstant && constant.Exported() {
+			if named, isNamed := constant.Type().(*types.Named); isNamed && named.Obj() == obj {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (c *goConverter) warn(position token.Pos, message string) {
+	location := c.fileSet.Position(position)
+
+	c.warnings = append(c.warnings, &importer.Warning{
+		Metadata: &syntax.Metadata{
+			Filename: location.Filename,
+			Offset:   int64(location.Offset),
+			Line:     int64(location.Line),
+			Column:   int64(location.Column),
+		},
+		Message: message,
+	})
+}
+
+// parseJsonTag splits a json struct tag into its name and options, a tag of "-"
+// means the field is never serialized
+func parseJsonTag(tag string) (string, string, bool) {
+	value := reflect.StructTag(tag).Get("json")
+
+	if value == "-" {
+		return "", "", true
+	}
+
+	name, options, _ := strings.Cut(value, ",")
+
+	return name, options, false
+}
+
+func dereference(typ types.Type) types.Type {
+	if pointer, isPointer := typ.(*types.Pointer); isPointer {
+		return pointer.Elem()
+	}
+
+	return typ
+}
+
+func NewGoLangImporter() importer.SchemaImporter {
+	return &GoLangImporter{}
+}
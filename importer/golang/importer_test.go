@@ -0,0 +1,99 @@
+package golang
+
+import (
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/printer"
+	"github.com/Haato3o/eskema/core/syntax"
+	"testing"
+)
+
+func TestGoLangImporter(t *testing.T) {
+	expected := `enum Status
+{
+    ONLINE,
+    OFFLINE
+};
+
+schema Auditable
+{
+    createdAt: DateTime
+};
+
+schema User
+{
+    createdAt: DateTime,
+    id: Int64,
+    email: String?,
+    status: Status,
+    friends: Array<User>,
+    labels: Map<String, String>,
+    tags: Set<String>,
+    hash: Array<UInt8, 16>,
+    manager: User?
+};
+
+schema Page<T>
+{
+    items: Array<T>
+};
+`
+
+	tree, warnings, err := (&GoLangImporter{}).Import("testdata/models")
+
+	if err != nil {
+		t.Fatalf("got %v, expected no error", err)
+	}
+
+	t.Run("should convert exported structs and typed constants", func(t *testing.T) {
+		if actual := printer.Print(tree); actual != expected {
+			t.Errorf("got\n%s\nexpected\n%s", actual, expected)
+		}
+	})
+
+	t.Run("should not report warnings for supported types", func(t *testing.T) {
+		if len(warnings) != 0 {
+			t.Errorf("got %v, expected no warnings", warnings)
+		}
+	})
+}
+
+func TestGoLangImporterInvalidTagNames(t *testing.T) {
+	expected := `schema Event
+{
+    user_id: String,
+    _type: String,
+    name: String
+};
+`
+
+	tree, warnings, err := (&GoLangImporter{}).Import("testdata/tags")
+
+	if err != nil {
+		t.Fatalf("got %v, expected no error", err)
+	}
+
+	t.Run("should turn tag names into identifiers", func(t *testing.T) {
+		actual := printer.Print(tree)
+
+		if actual != expected {
+			t.Errorf("got\n%s\nexpected\n%s", actual, expected)
+		}
+
+		eskemaParser := parser.New(syntax.NewLexer([]byte(actual), "tags.skm").Lex())
+		eskemaParser.Parse()
+
+		if errors := eskemaParser.Errors(); len(errors) > 0 {
+			t.Errorf("got %v, expected the imported schema to parse", errors)
+		}
+	})
+
+	t.Run("should warn about renamed fields", func(t *testing.T) {
+		if len(warnings) != 2 {
+			t.Fatalf("got %v, expected 2 warnings", warnings)
+		}
+
+		if warnings[0].Message != "'user-id' is not a valid identifier and was imported as 'user_id'" || warnings[0].Metadata.Line != 4 {
+			t.Errorf("got %v, expected the renamed field and its line", warnings[0])
+		}
+	})
+}
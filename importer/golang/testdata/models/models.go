@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+type Status string
+
+const (
+	StatusOnline  Status = "ONLINE"
+	StatusOffline Status = "OFFLINE"
+)
+
+type UserId int64
+
+type Auditable struct {
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type User struct {
+	Auditable
+	Id      UserId              `json:"id"`
+	Email   string              `json:"email,omitempty"`
+	Status  Status              `json:"status"`
+	Friends []*User             `json:"friends"`
+	Labels  map[string]string   `json:"labels"`
+	Tags    map[string]struct{} `json:"tags"`
+	Hash    [16]byte            `json:"hash"`
+	Manager *User               `json:"manager"`
+	Secret  string              `json:"-"`
+	hidden  string
+}
+
+type Page[T any] struct {
+	Items []T `json:"items"`
+}
@@ -0,0 +1,7 @@
+package tags
+
+type Event struct {
+	UserId string `json:"user-id"`
+	Type   string `json:"@type"`
+	Name   string `json:"name"`
+}
@@ -0,0 +1,27 @@
+package importer
+
+import (
+	"fmt"
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/syntax"
+)
+
+type Warning struct {
+	Metadata *syntax.Metadata
+	Message  string
+}
+
+func (w *Warning) String() string {
+	// Not every format can point to a line, those warnings only carry the file name
+	if w.Metadata.Line == 0 {
+		return fmt.Sprintf("%s Warning: %s", w.Metadata.Filename, w.Message)
+	}
+
+	return fmt.Sprintf("%v Warning: %s", w.Metadata, w.Message)
+}
+
+// SchemaImporter converts a contract written in another format into an eskema tree,
+// constructs it can't represent are reported as warnings instead of failing the import
+type SchemaImporter interface {
+	Import(fileName string) (*parser.EskemaTree, []*Warning, error)
+}
@@ -0,0 +1,226 @@
+package inference
+
+import (
+	"fmt"
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/syntax"
+	"github.com/Haato3o/eskema/importer"
+	"os"
+	"strings"
+)
+
+var shapePrimitives = map[shapeKind]string{
+	boolShape:     "Bool",
+	int32Shape:    "Int32",
+	int64Shape:    "Int64",
+	doubleShape:   "Double",
+	dateShape:     "Date",
+	dateTimeShape: "DateTime",
+	stringShape:   "String",
+}
+
+type Sample struct {
+	FileName string
+	Data     []byte
+}
+
+type SchemaInferrer struct {
+	rootName   string
+	fileName   string
+	schemas    []*parser.SchemaDefinition
+	signatures map[string]string
+	names      map[string]bool
+	warnings   []*importer.Warning
+}
+
+// InferFromFiles reads every file as a sample of the same payload
+func InferFromFiles(rootName string, fileNames ...string) (*parser.EskemaTree, []*importer.Warning, error) {
+	samples := make([]*Sample, 0, len(fileNames))
+
+	for _, fileName := range fileNames {
+		rawData, err := os.ReadFile(fileName)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		samples = append(samples, &Sample{FileName: fileName, Data: rawData})
+	}
+
+	return Infer(rootName, samples...)
+}
+
+// Infer unifies the structure of every sample into a single schema named rootName,
+// when a sample is an array each one of its elements is considered a sample
+func Infer(rootName string, samples ...*Sample) (*parser.EskemaTree, []*importer.Warning, error) {
+	var root *shape
+
+	for _, sample := range samples {
+		sampleShape, err := decodeShape(sample.Data)
+
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", sample.FileName, err)
+		}
+
+		if sampleShape.Kind == arrayShape {
+			sampleShape = sampleShape.Element
+		}
+
+		root = unify(root, sampleShape)
+	}
+
+	inferrer := &SchemaInferrer{
+		rootName:   importer.ToTypeName(rootName),
+		schemas:    make([]*parser.SchemaDefinition, 0),
+		signatures: make(map[string]string),
+		names:      make(map[string]bool),
+		warnings:   make([]*importer.Warning, 0),
+	}
+
+	if len(samples) > 0 {
+		inferrer.fileName = samples[0].FileName
+	}
+
+	if root == nil || root.Kind != objectShape {
+		return nil, nil, fmt.Errorf("samples must be JSON objects or arrays of objects")
+	}
+
+	inferrer.toType(root, inferrer.rootName, "$")
+
+	return inferrer.tree(), inferrer.warnings, nil
+}
+
+// tree lists the schemas parents first, they are collected children first
+// because a schema can only be deduplicated once its fields are known
+func (i *SchemaInferrer) tree() *parser.EskemaTree {
+	tree := &parser.EskemaTree{
+		Declarations: make([]parser.Declaration, 0, len(i.schemas)),
+	}
+
+	for index := len(i.schemas) - 1; index >= 0; index-- {
+		tree.Declarations = append(tree.Declarations, i.schemas[index])
+	}
+
+	return tree
+}
+
+func (i *SchemaInferrer) toType(value *shape, name string, path string) *parser.TypeExpression {
+	if primitive, isPrimitive := shapePrimitives[value.Kind]; isPrimitive {
+		return importer.NewType(primitive)
+	}
+
+	switch value.Kind {
+	case arrayShape:
+		if value.Element == nil || value.Element.Kind == nullShape {
+			i.warn(path, "array was always empty and was inferred as Array<String>")
+
+			return importer.NewType("Array", importer.NewType("String"))
+		}
+
+		return importer.NewType("Array", i.toType(value.Element, singularize(name), path+"[]"))
+	case objectShape:
+		return importer.NewType(i.declareSchema(value, name, path))
+	case nullShape:
+		i.warn(path, "value was always null and was inferred as String")
+	default:
+		i.warn(path, "value has different types across samples and was inferred as String")
+	}
+
+	return importer.NewType("String")
+}
+
+// declareSchema names an object after the path that led to it, objects with
+// the exact same fields are declared only once and share the first name given
+func (i *SchemaInferrer) declareSchema(value *shape, name string, path string) string {
+	schema := &parser.SchemaDefinition{
+		Fields: make([]*parser.FieldExpression, 0, len(value.Keys)),
+	}
+
+	for _, key := range value.Keys {
+		field := value.Fields[key]
+		fieldName := importer.ToIdentifier(key)
+
+		if fieldName != key {
+			i.warn(path+"."+key, fmt.Sprintf("'%s' is not a valid identifier and was inferred as '%s'", key, fieldName))
+		}
+
+		schema.Fields = append(schema.Fields, &parser.FieldExpression{
+			Id:         parser.IdentifierExpression{Name: fieldName},
+			IsOptional: field.IsNullable || value.FieldCounts[key] < value.Count,
+			Type:       i.toType(field, name+importer.ToTypeName(key), path+"."+key),
+		})
+	}
+
+	signature := schemaSignature(schema)
+
+	if existing, exists := i.signatures[signature]; exists {
+		return existing
+	}
+
+	schema.Id.Name = i.uniqueName(name)
+	i.signatures[signature] = schema.Id.Name
+	i.schemas = append(i.schemas, schema)
+
+	return schema.Id.Name
+}
+
+func (i *SchemaInferrer) uniqueName(name string) string {
+	unique := name
+
+	for index := 2; i.names[unique]; index++ {
+		unique = fmt.Sprintf("%s%d", name, index)
+	}
+
+	i.names[unique] = true
+
+	return unique
+}
+
+func (i *SchemaInferrer) warn(path string, message string) {
+	i.warnings = append(i.warnings, &importer.Warning{
+		Metadata: &syntax.Metadata{Filename: i.fileName},
+		Message:  fmt.Sprintf("at '%s': %s", path, message),
+	})
+}
+
+func schemaSignature(schema *parser.SchemaDefinition) string {
+	var builder strings.Builder
+
+	for _, field := range schema.Fields {
+		builder.WriteString(field.Id.Name)
+		builder.WriteString(":")
+		writeTypeSignature(&builder, field.Type)
+
+		if field.IsOptional {
+			builder.WriteString("?")
+		}
+
+		builder.WriteString(";")
+	}
+
+	return builder.String()
+}
+
+func writeTypeSignature(builder *strings.Builder, typeExpr *parser.TypeExpression) {
+	builder.WriteString(typeExpr.Id.Name)
+
+	for _, generic := range typeExpr.Generics {
+		builder.WriteString("<")
+		writeTypeSignature(builder, generic)
+		builder.WriteString(">")
+	}
+}
+
+// singularize names array elements after their field, friends holds Friend items
+func singularize(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "ss"):
+		return name + "Item"
+	case strings.HasSuffix(name, "s") && len(name) > 1:
+		return strings.TrimSuffix(name, "s")
+	default:
+		return name + "Item"
+	}
+}
@@ -0,0 +1,73 @@
+package inference
+
+import (
+	"github.com/Haato3o/eskema/core/printer"
+	"testing"
+)
+
+func TestInfer(t *testing.T) {
+	samples := []*Sample{
+		{"first.json", []byte(`{"id": 1, "price": 10, "createdAt": "2023-03-01T10:00:00Z", "billing": {"street": "a"}, "lines": [{"sku": "x"}]}`)},
+		{"second.json", []byte(`[{"id": 3000000000, "price": 2.5, "createdAt": "2023-03-02T10:00:00Z", "billing": {"street": "b"}, "shipping": {"street": "c"}, "lines": []}]`)},
+	}
+	expected := `schema Order
+{
+    id: Int64,
+    price: Double,
+    createdAt: DateTime,
+    billing: OrderBilling,
+    lines: Array<OrderLine>,
+    shipping: OrderBilling?
+};
+
+schema OrderLine
+{
+    sku: String
+};
+
+schema OrderBilling
+{
+    street: String
+};
+`
+
+	tree, warnings, err := Infer("order", samples...)
+
+	if err != nil {
+		t.Fatalf("got %v, expected no error", err)
+	}
+
+	t.Run("should unify samples and deduplicate identical objects", func(t *testing.T) {
+		if actual := printer.Print(tree); actual != expected {
+			t.Errorf("got\n%s\nexpected\n%s", actual, expected)
+		}
+	})
+
+	t.Run("should not report warnings when every value is known", func(t *testing.T) {
+		if len(warnings) != 0 {
+			t.Errorf("got %v, expected no warnings", warnings)
+		}
+	})
+}
+
+func TestUnifyNumbers(t *testing.T) {
+	testCases := []struct {
+		Left     shapeKind
+		Right    shapeKind
+		Expected shapeKind
+	}{
+		{int32Shape, int32Shape, int32Shape},
+		{int32Shape, int64Shape, int64Shape},
+		{int64Shape, doubleShape, doubleShape},
+		{dateTimeShape, stringShape, stringShape},
+		{boolShape, stringShape, mixedShape},
+	}
+
+	for _, testCase := range testCases {
+		actual := unify(newShape(testCase.Left), newShape(testCase.Right)).Kind
+
+		if actual != testCase.Expected {
+			t.Errorf("got %v, expected %v", actual, testCase.Expected)
+		}
+	}
+}
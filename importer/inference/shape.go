@@ -0,0 +1,244 @@
+package inference
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type shapeKind int
+
+const (
+	nullShape shapeKind = iota
+	boolShape
+	int32Shape
+	int64Shape
+	doubleShape
+	dateShape
+	dateTimeShape
+	stringShape
+	arrayShape
+	objectShape
+	mixedShape
+)
+
+var (
+	isoDatePattern     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	isoDateTimePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}[Tt ]\d{2}:\d{2}(:\d{2}(\.\d+)?)?([Zz]|[+-]\d{2}(:?\d{2})?)?$`)
+)
+
+// shape is the structure observed for a value across every sample, objects count
+// how many times each field was seen so missing ones can be made optional
+type shape struct {
+	Kind        shapeKind
+	IsNullable  bool
+	Element     *shape
+	Keys        []string
+	Fields      map[string]*shape
+	FieldCounts map[string]int
+	Count       int
+}
+
+func newShape(kind shapeKind) *shape {
+	return &shape{Kind: kind}
+}
+
+func newObjectShape() *shape {
+	return &shape{
+		Kind:        objectShape,
+		Keys:        make([]string, 0),
+		Fields:      make(map[string]*shape),
+		FieldCounts: make(map[string]int),
+		Count:       1,
+	}
+}
+
+// decodeShape reads a JSON document token by token to keep the order of the keys
+func decodeShape(input []byte) (*shape, error) {
+	decoder := json.NewDecoder(bytes.NewReader(input))
+	decoder.UseNumber()
+
+	return decodeValue(decoder)
+}
+
+func decodeValue(decoder *json.Decoder) (*shape, error) {
+	token, err := decoder.Token()
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch value := token.(type) {
+	case json.Delim:
+		if value == '[' {
+			return decodeArray(decoder)
+		}
+
+		return decodeObject(decoder)
+	case bool:
+		return newShape(boolShape), nil
+	case json.Number:
+		return newShape(numberKind(value.String())), nil
+	case string:
+		return newShape(stringKind(value)), nil
+	default:
+		return newShape(nullShape), nil
+	}
+}
+
+func decodeArray(decoder *json.Decoder) (*shape, error) {
+	array := newShape(arrayShape)
+
+	for decoder.More() {
+		element, err := decodeValue(decoder)
+
+		if err != nil {
+			return nil, err
+		}
+
+		array.Element = unify(array.Element, element)
+	}
+
+	_, err := decoder.Token()
+
+	return array, err
+}
+
+func decodeObject(decoder *json.Decoder) (*shape, error) {
+	object := newObjectShape()
+
+	for decoder.More() {
+		token, err := decoder.Token()
+
+		if err != nil {
+			return nil, err
+		}
+
+		key := token.(string)
+		value, err := decodeValue(decoder)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if _, exists := object.Fields[key]; !exists {
+			object.Keys = append(object.Keys, key)
+			object.FieldCounts[key] = 1
+		}
+
+		object.Fields[key] = unify(object.Fields[key], value)
+	}
+
+	_, err := decoder.Token()
+
+	return object, err
+}
+
+func numberKind(value string) shapeKind {
+	if strings.ContainsAny(value, ".eE") {
+		return doubleShape
+	}
+
+	number, err := strconv.ParseInt(value, 10, 64)
+
+	if err != nil {
+		return doubleShape
+	}
+
+	if number >= -2147483648 && number <= 2147483647 {
+		return int32Shape
+	}
+
+	return int64Shape
+}
+
+func stringKind(value string) shapeKind {
+	if isoDateTimePattern.MatchString(value) {
+		return dateTimeShape
+	}
+
+	if isoDatePattern.MatchString(value) {
+		return dateShape
+	}
+
+	return stringShape
+}
+
+// unify merges two observations of the same value, numbers widen from Int32 to
+// Int64 to Double, dates fall back to String and anything else that disagrees is mixed
+func unify(left *shape, right *shape) *shape {
+	if left == nil {
+		return right
+	}
+
+	if right == nil {
+		return left
+	}
+
+	if left.Kind == nullShape {
+		right.IsNullable = true
+		return right
+	}
+
+	if right.Kind == nullShape {
+		left.IsNullable = true
+		return left
+	}
+
+	isNullable := left.IsNullable || right.IsNullable
+
+	var unified *shape
+
+	switch {
+	case left.Kind == right.Kind && left.Kind == objectShape:
+		unified = unifyObjects(left, right)
+	case left.Kind == right.Kind && left.Kind == arrayShape:
+		unified = left
+		unified.Element = unify(left.Element, right.Element)
+	case left.Kind == right.Kind:
+		unified = left
+	case isNumber(left.Kind) && isNumber(right.Kind):
+		unified = newShape(maxKind(left.Kind, right.Kind))
+	case isText(left.Kind) && isText(right.Kind):
+		unified = newShape(stringShape)
+	default:
+		unified = newShape(mixedShape)
+	}
+
+	unified.IsNullable = isNullable
+
+	return unified
+}
+
+func unifyObjects(left *shape, right *shape) *shape {
+	for _, key := range right.Keys {
+		if _, exists := left.Fields[key]; !exists {
+			left.Keys = append(left.Keys, key)
+		}
+
+		left.Fields[key] = unify(left.Fields[key], right.Fields[key])
+		left.FieldCounts[key] += right.FieldCounts[key]
+	}
+
+	left.Count += right.Count
+
+	return left
+}
+
+func isNumber(kind shapeKind) bool {
+	return kind == int32Shape || kind == int64Shape || kind == doubleShape
+}
+
+func isText(kind shapeKind) bool {
+	return kind == stringShape || kind == dateShape || kind == dateTimeShape
+}
+
+func maxKind(left shapeKind, right shapeKind) shapeKind {
+	if left > right {
+		return left
+	}
+
+	return right
+}
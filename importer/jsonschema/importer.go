@@ -0,0 +1,374 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Haato3o/eskema/core/codestyle"
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/syntax"
+	"github.com/Haato3o/eskema/importer"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var formatPrimitives = map[string]string{
+	"date-time": "DateTime",
+	"date":      "Date",
+	"byte":      "Bytes",
+	"int32":     "Int32",
+	"int64":     "Int64",
+	"float":     "Float",
+	"double":    "Double",
+	"uuid":      "UUID",
+	"uri":       "Url",
+	"duration":  "Duration",
+}
+
+var definitionPrefixes = []string{"#/definitions/", "#/$defs/"}
+
+type JsonSchemaImporter struct{}
+
+func (i *JsonSchemaImporter) Import(fileName string) (*parser.EskemaTree, []*importer.Warning, error) {
+	rawData, err := os.ReadFile(fileName)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return i.ImportSource(rawData, fileName)
+}
+
+func (i *JsonSchemaImporter) ImportSource(input []byte, fileName string) (*parser.EskemaTree, []*importer.Warning, error) {
+	root := &jsonSchema{}
+
+	if err := json.Unmarshal(input, root); err != nil {
+		return nil, nil, err
+	}
+
+	converter := &jsonSchemaConverter{
+		fileName:    fileName,
+		root:        root,
+		tree:        &parser.EskemaTree{Declarations: make([]parser.Declaration, 0)},
+		names:       make(map[string]bool),
+		definitions: make(map[string]*parser.TypeExpression),
+		resolving:   make(map[string]bool),
+		warnings:    make([]*importer.Warning, 0),
+	}
+
+	converter.convert()
+
+	return converter.tree, converter.warnings, nil
+}
+
+type jsonSchemaConverter struct {
+	fileName    string
+	root        *jsonSchema
+	rootName    string
+	tree        *parser.EskemaTree
+	names       map[string]bool
+	definitions map[string]*parser.TypeExpression
+	resolving   map[string]bool
+	warnings    []*importer.Warning
+}
+
+func (c *jsonSchemaConverter) convert() {
+	rootName := c.root.Title
+
+	if rootName == "" {
+		rootName = strings.TrimSuffix(filepath.Base(c.fileName), filepath.Ext(c.fileName))
+		rootName = strings.TrimSuffix(rootName, ".schema")
+	}
+
+	c.rootName = c.peekName(c.toTypeName(rootName, "#"))
+	c.convertType(c.root, c.rootName, "#")
+
+	for _, prefix := range definitionPrefixes {
+		definitions := c.definitionsOf(prefix)
+
+		if definitions == nil {
+			continue
+		}
+
+		for _, name := range definitions.Keys {
+			c.resolveDefinition(prefix, name, prefix+name)
+		}
+	}
+}
+
+func (c *jsonSchemaConverter) definitionsOf(prefix string) *orderedSchemas {
+	if prefix == "#/$defs/" {
+		return c.root.Defs
+	}
+
+	return c.root.Definitions
+}
+
+// convertType maps a JSON schema into an eskema type, declaring schemas and enums
+// along the way, anonymous ones are named after the path that led to them
+func (c *jsonSchemaConverter) convertType(schema *jsonSchema, name string, pointer string) *parser.TypeExpression {
+	if schema.Ref != "" {
+		return c.resolveReference(schema.Ref, pointer)
+	}
+
+	compositions := []struct {
+		Keyword  string
+		Variants []*json.RawMessage
+	}{
+		{"anyOf", schema.AnyOf},
+		{"oneOf", schema.OneOf},
+		{"allOf", schema.AllOf},
+	}
+
+	for _, composition := range compositions {
+		if len(composition.Variants) > 0 {
+			c.warn(pointer, "'%s' is not supported and was imported as String", composition.Keyword)
+
+			return importer.NewType("String")
+		}
+	}
+
+	types, _ := schema.types()
+
+	if len(types) > 1 {
+		c.warn(pointer, "multiple types %v are not supported and were imported as String", types)
+
+		return importer.NewType("String")
+	}
+
+	typ := ""
+
+	if len(types) == 1 {
+		typ = types[0]
+	} else if schema.Properties != nil {
+		typ = "object"
+	} else if schema.Items != nil {
+		typ = "array"
+	} else if len(schema.Enum) > 0 {
+		typ = "string"
+	}
+
+	switch typ {
+	case "object":
+		return c.convertObject(schema, name, pointer)
+	case "array":
+		if schema.Items == nil {
+			c.warn(pointer, "array without items was imported as Array<String>")
+
+			return importer.NewType("Array", importer.NewType("String"))
+		}
+
+		return importer.NewType("Array", c.convertType(schema.Items, name+"Item", pointer+"/items"))
+	case "string":
+		if len(schema.Enum) > 0 {
+			return c.convertEnum(schema, name, pointer)
+		}
+
+		if primitive, exists := formatPrimitives[schema.Format]; exists {
+			return importer.NewType(primitive)
+		}
+
+		return importer.NewType("String")
+	case "integer":
+		if primitive, exists := formatPrimitives[schema.Format]; exists {
+			return importer.NewType(primitive)
+		}
+
+		return importer.NewType("Int64")
+	case "number":
+		if primitive, exists := formatPrimitives[schema.Format]; exists {
+			return importer.NewType(primitive)
+		}
+
+		return importer.NewType("Double")
+	case "boolean":
+		return importer.NewType("Bool")
+	default:
+		c.warn(pointer, "schema without a type was imported as String")
+
+		return importer.NewType("String")
+	}
+}
+
+func (c *jsonSchemaConverter) convertObject(schema *jsonSchema, name string, pointer string) *parser.TypeExpression {
+	if schema.Properties == nil {
+		valueType := importer.NewType("String")
+
+		if valueSchema := schema.additionalPropertiesSchema(); valueSchema != nil {
+			valueType = c.convertType(valueSchema, name+"Value", pointer+"/additionalProperties")
+		} else {
+			c.warn(pointer, "object without properties was imported as Map<String, String>")
+		}
+
+		return importer.NewType("Map", importer.NewType("String"), valueType)
+	}
+
+	definition := &parser.SchemaDefinition{
+		Id:     parser.IdentifierExpression{Name: c.declareName(name)},
+		Fields: make([]*parser.FieldExpression, 0),
+	}
+
+	c.tree.Declarations = append(c.tree.Declarations, definition)
+
+	for _, property := range schema.Properties.Keys {
+		propertySchema := schema.Properties.Values[property]
+		propertyPointer := pointer + "/properties/" + property
+		_, isNullable := propertySchema.types()
+
+		definition.Fields = append(definition.Fields, &parser.FieldExpression{
+			Id:         parser.IdentifierExpression{Name: c.toFieldName(property, propertyPointer)},
+			IsOptional: isNullable || !schema.isRequired(property),
+			Type:       c.convertType(propertySchema, definition.Id.Name+importer.ToTypeName(property), propertyPointer),
+		})
+	}
+
+	return importer.NewType(definition.Id.Name)
+}
+
+func (c *jsonSchemaConverter) convertEnum(schema *jsonSchema, name string, pointer string) *parser.TypeExpression {
+	definition := &parser.EnumDefinition{
+		Id:     parser.IdentifierExpression{Name: c.declareName(name)},
+		Values: make([]*parser.EnumValue, 0),
+	}
+
+	literals := make([]string, 0, len(schema.Enum))
+	isRenamed := false
+
+	for _, value := range schema.Enum {
+		literal, isString := value.(string)
+
+		if !isString {
+			c.warn(pointer, "enum value '%v' is not a string and was skipped", value)
+			continue
+		}
+
+		identifier := c.toIdentifier(literal, pointer)
+		isRenamed = isRenamed || identifier != literal
+		literals = append(literals, literal)
+		definition.Values = append(definition.Values, importer.NewEnumValue(identifier))
+	}
+
+	// eskema needs every value of an enum to be explicit once one of them is, so
+	// renaming a single value keeps the original strings of all of them
+	if isRenamed {
+		for i, value := range definition.Values {
+			definition.Values[i] = importer.NewStringEnumValue(value.Id.Name, literals[i])
+		}
+	}
+
+	c.tree.Declarations = append(c.tree.Declarations, definition)
+
+	return importer.NewType(definition.Id.Name)
+}
+
+func (c *jsonSchemaConverter) resolveReference(ref string, pointer string) *parser.TypeExpression {
+	for _, prefix := range definitionPrefixes {
+		if !strings.HasPrefix(ref, prefix) {
+			continue
+		}
+
+		definitions := c.definitionsOf(prefix)
+		name := strings.TrimPrefix(ref, prefix)
+
+		if definitions != nil && definitions.Values[name] != nil {
+			return c.resolveDefinition(prefix, name, pointer)
+		}
+	}
+
+	if ref == "#" {
+		return importer.NewType(c.rootName)
+	}
+
+	c.warn(pointer, "reference '%s' could not be resolved and was imported as String", ref)
+
+	return importer.NewType("String")
+}
+
+// resolveDefinition converts each definition only once, named declarations are
+// registered before their properties are visited so recursive references resolve,
+// anything else referencing itself can't be expressed and is imported as String
+func (c *jsonSchemaConverter) resolveDefinition(prefix string, name string, pointer string) *parser.TypeExpression {
+	key := prefix + name
+
+	if typeExpression, exists := c.definitions[key]; exists {
+		return typeExpression
+	}
+
+	if c.resolving[key] {
+		c.warn(pointer, "definition '%s' references itself without an object or enum in between and was imported as String", key)
+
+		return importer.NewType("String")
+	}
+
+	c.resolving[key] = true
+	defer delete(c.resolving, key)
+
+	schema := c.definitionsOf(prefix).Values[name]
+
+	if schema.Properties != nil || len(schema.Enum) > 0 {
+		c.definitions[key] = importer.NewType(c.peekName(c.toTypeName(name, key)))
+	}
+
+	typeExpression := c.convertType(schema, importer.ToTypeName(name), key)
+	c.definitions[key] = typeExpression
+
+	return typeExpression
+}
+
+// declareName returns a unique name for a new declaration, a numeric suffix is
+// added when two anonymous objects end up with the same path based name
+func (c *jsonSchemaConverter) declareName(name string) string {
+	unique := c.peekName(name)
+	c.names[unique] = true
+
+	return unique
+}
+
+func (c *jsonSchemaConverter) peekName(name string) string {
+	unique := name
+
+	for i := 2; c.names[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+
+	return unique
+}
+
+func (c *jsonSchemaConverter) toFieldName(property string, pointer string) string {
+	return c.toIdentifier(property, pointer)
+}
+
+func (c *jsonSchemaConverter) toIdentifier(value string, pointer string) string {
+	identifier := importer.ToIdentifier(value)
+
+	if importer.IsReserved(value) {
+		c.warn(pointer, "'%s' is reserved in eskema and was imported as '%s'", value, identifier)
+	} else if identifier != value {
+		c.warn(pointer, "'%s' is not a valid identifier and was imported as '%s'", value, identifier)
+	}
+
+	return identifier
+}
+
+// toTypeName warns when a declaration is renamed because its name is reserved,
+// changing the casing of a name is expected and isn't reported
+func (c *jsonSchemaConverter) toTypeName(value string, pointer string) string {
+	typeName := importer.ToTypeName(value)
+
+	if importer.IsReserved(codestyle.ToPascalCase(value)) {
+		c.warn(pointer, "'%s' is reserved in eskema and was imported as '%s'", value, typeName)
+	}
+
+	return typeName
+}
+
+func (c *jsonSchemaConverter) warn(pointer string, format string, args ...interface{}) {
+	c.warnings = append(c.warnings, &importer.Warning{
+		Metadata: &syntax.Metadata{Filename: c.fileName},
+		Message:  fmt.Sprintf("at '%s': %s", pointer, fmt.Sprintf(format, args...)),
+	})
+}
+
+func NewJsonSchemaImporter() importer.SchemaImporter {
+	return &JsonSchemaImporter{}
+}
@@ -0,0 +1,198 @@
+package jsonschema
+
+import (
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/printer"
+	"github.com/Haato3o/eskema/core/syntax"
+	"strings"
+	"testing"
+)
+
+func TestJsonSchemaImporter(t *testing.T) {
+	source := `{
+  "title": "user",
+  "type": "object",
+  "required": ["id", "status"],
+  "properties": {
+    "id": {"type": "integer", "format": "int32"},
+    "status": {"enum": ["ONLINE", "OFFLINE"]},
+    "email": {"type": ["string", "null"]},
+    "createdAt": {"type": "string", "format": "date-time"},
+    "address": {"type": "object", "properties": {"street": {"type": "string"}}},
+    "friends": {"type": "array", "items": {"$ref": "#/definitions/Friend"}},
+    "scores": {"type": "object", "additionalProperties": {"type": "number"}}
+  },
+  "definitions": {
+    "Friend": {"type": "object", "required": ["best"], "properties": {"best": {"$ref": "#/definitions/Friend"}}}
+  }
+}`
+	expected := `schema User
+{
+    id: Int32,
+    status: UserStatus,
+    email: String?,
+    createdAt: DateTime?,
+    address: UserAddress?,
+    friends: Array<Friend>?,
+    scores: Map<String, Double>?
+};
+
+enum UserStatus
+{
+    ONLINE,
+    OFFLINE
+};
+
+schema UserAddress
+{
+    street: String?
+};
+
+schema Friend
+{
+    best: Friend
+};
+`
+
+	tree, warnings, err := (&JsonSchemaImporter{}).ImportSource([]byte(source), "user.schema.json")
+
+	if err != nil {
+		t.Fatalf("got %v, expected no error", err)
+	}
+
+	t.Run("should convert json schema into eskema source", func(t *testing.T) {
+		if actual := printer.Print(tree); actual != expected {
+			t.Errorf("got\n%s\nexpected\n%s", actual, expected)
+		}
+	})
+
+	t.Run("should not report warnings for supported keywords", func(t *testing.T) {
+		if len(warnings) != 0 {
+			t.Errorf("got %v, expected no warnings", warnings)
+		}
+	})
+}
+
+func TestJsonSchemaImporterRecursiveDefinitions(t *testing.T) {
+	source := `{
+  "title": "forest",
+  "type": "object",
+  "properties": {
+    "tree": {"$ref": "#/$defs/Tree"},
+    "left": {"$ref": "#/$defs/Left"}
+  },
+  "$defs": {
+    "Tree": {"type": "array", "items": {"$ref": "#/$defs/Tree"}},
+    "Left": {"$ref": "#/$defs/Right"},
+    "Right": {"$ref": "#/$defs/Left"}
+  }
+}`
+	expected := `schema Forest
+{
+    tree: Array<String>?,
+    left: String?
+};
+`
+
+	tree, warnings, err := (&JsonSchemaImporter{}).ImportSource([]byte(source), "forest.schema.json")
+
+	if err != nil {
+		t.Fatalf("got %v, expected no error", err)
+	}
+
+	t.Run("should import recursive definitions without recursing forever", func(t *testing.T) {
+		if actual := printer.Print(tree); actual != expected {
+			t.Errorf("got\n%s\nexpected\n%s", actual, expected)
+		}
+	})
+
+	t.Run("should report each cycle", func(t *testing.T) {
+		if len(warnings) != 2 {
+			t.Fatalf("got %v, expected 2 warnings", warnings)
+		}
+
+		for _, warning := range warnings {
+			if !strings.Contains(warning.Message, "references itself") {
+				t.Errorf("got %q, expected a cycle warning", warning.Message)
+			}
+		}
+	})
+}
+
+func TestJsonSchemaImporterReservedNames(t *testing.T) {
+	source := `{
+  "title": "task",
+  "type": "object",
+  "required": ["enum", "state"],
+  "properties": {
+    "enum": {"type": "string"},
+    "const": {"type": "string"},
+    "payload": {"type": "string", "format": "byte"},
+    "state": {"enum": ["in-progress", "done"]},
+    "due": {"$ref": "#/definitions/Date"}
+  },
+  "definitions": {
+    "Date": {"type": "object", "properties": {"day": {"type": "integer"}}}
+  }
+}`
+	expected := `schema Task
+{
+    enum_: String,
+    const_: String?,
+    payload: Bytes?,
+    state: TaskState,
+    due: DateType?
+};
+
+enum TaskState
+{
+    in_progress = "in-progress",
+    done = "done"
+};
+
+schema DateType
+{
+    day: Int64?
+};
+`
+
+	tree, warnings, err := (&JsonSchemaImporter{}).ImportSource([]byte(source), "task.schema.json")
+
+	if err != nil {
+		t.Fatalf("got %v, expected no error", err)
+	}
+
+	t.Run("should rename reserved names and keep renamed enum values on the wire", func(t *testing.T) {
+		actual := printer.Print(tree)
+
+		if actual != expected {
+			t.Errorf("got\n%s\nexpected\n%s", actual, expected)
+		}
+
+		eskemaParser := parser.New(syntax.NewLexer([]byte(actual), "task.skm").Lex())
+		eskemaParser.Parse()
+
+		if errors := eskemaParser.Errors(); len(errors) > 0 {
+			t.Errorf("got %v, expected the imported schema to parse", errors)
+		}
+	})
+
+	t.Run("should warn about renamed names", func(t *testing.T) {
+		expected := []string{
+			"at '#/properties/enum': 'enum' is reserved in eskema and was imported as 'enum_'",
+			"at '#/properties/const': 'const' is reserved in eskema and was imported as 'const_'",
+			"at '#/properties/state': 'in-progress' is not a valid identifier and was imported as 'in_progress'",
+			"at '#/definitions/Date': 'Date' is reserved in eskema and was imported as 'DateType'",
+		}
+
+		if len(warnings) != len(expected) {
+			t.Fatalf("got %v, expected %d warnings", warnings, len(expected))
+		}
+
+		for i, warning := range warnings {
+			if warning.Message != expected[i] {
+				t.Errorf("got %q, expected %q", warning.Message, expected[i])
+			}
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+type jsonSchema struct {
+	Title                string             `json:"title"`
+	Type                 interface{}        `json:"type"`
+	Format               string             `json:"format"`
+	Ref                  string             `json:"$ref"`
+	Properties           *orderedSchemas    `json:"properties"`
+	Required             []string           `json:"required"`
+	Items                *jsonSchema        `json:"items"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties"`
+	Enum                 []interface{}      `json:"enum"`
+	Definitions          *orderedSchemas    `json:"definitions"`
+	Defs                 *orderedSchemas    `json:"$defs"`
+	AnyOf                []*json.RawMessage `json:"anyOf"`
+	OneOf                []*json.RawMessage `json:"oneOf"`
+	AllOf                []*json.RawMessage `json:"allOf"`
+}
+
+// types returns the declared types without "null", together with whether "null" was one of them
+func (s *jsonSchema) types() ([]string, bool) {
+	types := make([]string, 0)
+	isNullable := false
+
+	add := func(value interface{}) {
+		if name, isString := value.(string); isString {
+			if name == "null" {
+				isNullable = true
+			} else {
+				types = append(types, name)
+			}
+		}
+	}
+
+	switch value := s.Type.(type) {
+	case []interface{}:
+		for _, typ := range value {
+			add(typ)
+		}
+	default:
+		add(value)
+	}
+
+	return types, isNullable
+}
+
+func (s *jsonSchema) isRequired(property string) bool {
+	for _, required := range s.Required {
+		if required == property {
+			return true
+		}
+	}
+
+	return false
+}
+
+// additionalPropertiesSchema returns the schema of map values, which is nil when
+// additionalProperties is absent or a boolean
+func (s *jsonSchema) additionalPropertiesSchema() *jsonSchema {
+	trimmed := bytes.TrimSpace(s.AdditionalProperties)
+
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil
+	}
+
+	var schema jsonSchema
+
+	if err := json.Unmarshal(trimmed, &schema); err != nil {
+		return nil
+	}
+
+	return &schema
+}
+
+// orderedSchemas keeps properties in the order they were declared so the
+// imported fields and the generated names are deterministic
+type orderedSchemas struct {
+	Keys   []string
+	Values map[string]*jsonSchema
+}
+
+func (o *orderedSchemas) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	o.Keys = make([]string, 0)
+	o.Values = make(map[string]*jsonSchema)
+
+	if _, err := decoder.Token(); err != nil {
+		return err
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+
+		if err != nil {
+			return err
+		}
+
+		key := token.(string)
+		schema := &jsonSchema{}
+
+		var raw json.RawMessage
+
+		if err := decoder.Decode(&raw); err != nil {
+			return err
+		}
+
+		if trimmed := bytes.TrimSpace(raw); len(trimmed) > 0 && trimmed[0] == '{' {
+			if err := json.Unmarshal(trimmed, schema); err != nil {
+				return err
+			}
+		}
+
+		o.Keys = append(o.Keys, key)
+		o.Values[key] = schema
+	}
+
+	return nil
+}
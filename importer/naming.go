@@ -0,0 +1,80 @@
+package importer
+
+import (
+	"github.com/Haato3o/eskema/core/codestyle"
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/syntax"
+	"regexp"
+	"strconv"
+)
+
+var invalidIdentifierCharacters = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// IsReserved reports whether the lexer reads name as a keyword, primitive or
+// boolean instead of a name
+func IsReserved(name string) bool {
+	isKeyword, _ := syntax.IsKeyword(name)
+	isPrimitive, _ := syntax.IsPrimitiveType(name)
+
+	return isKeyword || isPrimitive || name == syntax.TrueValue || name == syntax.FalseValue
+}
+
+// ToIdentifier replaces every character eskema doesn't accept in names with '_',
+// reserved names get a trailing '_'
+func ToIdentifier(value string) string {
+	identifier := invalidIdentifierCharacters.ReplaceAllString(value, "_")
+
+	if identifier == "" || (identifier[0] >= '0' && identifier[0] <= '9') {
+		identifier = "_" + identifier
+	}
+
+	if IsReserved(identifier) {
+		identifier += "_"
+	}
+
+	return identifier
+}
+
+// ToTypeName turns value into a PascalCase name, reserved names get a trailing
+// 'Type'
+func ToTypeName(value string) string {
+	identifier := invalidIdentifierCharacters.ReplaceAllString(value, "_")
+
+	if identifier == "" {
+		return "Root"
+	}
+
+	typeName := codestyle.ToPascalCase(identifier)
+
+	if IsReserved(typeName) {
+		typeName += "Type"
+	}
+
+	return typeName
+}
+
+func NewType(name string, generics ...*parser.TypeExpression) *parser.TypeExpression {
+	if generics == nil {
+		generics = make([]*parser.TypeExpression, 0)
+	}
+
+	return &parser.TypeExpression{
+		Id:       parser.IdentifierExpression{Name: name},
+		Generics: generics,
+	}
+}
+
+func NewEnumValue(name string) *parser.EnumValue {
+	return &parser.EnumValue{
+		Id: parser.IdentifierExpression{Name: name},
+	}
+}
+
+// NewStringEnumValue creates a value written on the wire as value instead of
+// its name
+func NewStringEnumValue(name string, value string) *parser.EnumValue {
+	enumValue := NewEnumValue(name)
+	enumValue.Value = &parser.LiteralExpression{Kind: parser.StringLiteral, Raw: strconv.Quote(value), Value: value}
+
+	return enumValue
+}
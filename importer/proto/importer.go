@@ -0,0 +1,263 @@
+package proto
+
+import (
+	"github.com/Haato3o/eskema/core/codestyle"
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/importer"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var protoPrimitives = map[string]string{
+	"double":   "Double",
+	"float":    "Float",
+	"int32":    "Int32",
+	"sint32":   "Int32",
+	"sfixed32": "Int32",
+	"int64":    "Int64",
+	"sint64":   "Int64",
+	"sfixed64": "Int64",
+	"uint32":   "UInt32",
+	"fixed32":  "UInt32",
+	"uint64":   "UInt64",
+	"fixed64":  "UInt64",
+	"bool":     "Bool",
+	"string":   "String",
+	"bytes":    "Bytes",
+}
+
+// wellKnownTypes are the google.protobuf messages that map to a primitive, being
+// messages they have presence so singular fields of these types are imported as optional
+var wellKnownTypes = map[string]string{
+	"google.protobuf.Timestamp":   "TimeStamp",
+	"google.protobuf.Duration":    "Duration",
+	"google.protobuf.DoubleValue": "Double",
+	"google.protobuf.FloatValue":  "Float",
+	"google.protobuf.Int64Value":  "Int64",
+	"google.protobuf.UInt64Value": "UInt64",
+	"google.protobuf.Int32Value":  "Int32",
+	"google.protobuf.UInt32Value": "UInt32",
+	"google.protobuf.BoolValue":   "Bool",
+	"google.protobuf.StringValue": "String",
+}
+
+type ProtoImporter struct{}
+
+func (i *ProtoImporter) Import(fileName string) (*parser.EskemaTree, []*importer.Warning, error) {
+	rawData, err := os.ReadFile(fileName)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return i.ImportSource(rawData, fileName)
+}
+
+func (i *ProtoImporter) ImportSource(input []byte, fileName string) (*parser.EskemaTree, []*importer.Warning, error) {
+	tokens := newProtoLexer(input, fileName).Lex()
+	protoParser := newProtoParser(tokens)
+
+	file, err := protoParser.Parse()
+
+	if err != nil {
+		return nil, protoParser.warnings, err
+	}
+
+	converter := &protoConverter{
+		file:     file,
+		declared: make(map[string]*protoDeclaration),
+		warnings: protoParser.warnings,
+	}
+
+	return converter.convert(), converter.warnings, nil
+}
+
+type protoConverter struct {
+	file     *protoFile
+	declared map[string]*protoDeclaration
+	warnings []*importer.Warning
+}
+
+func (c *protoConverter) convert() *parser.EskemaTree {
+	tree := &parser.EskemaTree{
+		Declarations: make([]parser.Declaration, 0),
+	}
+
+	for _, declaration := range c.file.Declarations {
+		if declaration.Message != nil {
+			c.declared[declaration.Message.Name] = declaration
+		} else {
+			c.declared[declaration.Enum.Name] = declaration
+		}
+	}
+
+	for _, declaration := range c.file.Declarations {
+		if declaration.Message != nil {
+			tree.Declarations = append(tree.Declarations, c.convertMessage(declaration.Message))
+		} else {
+			tree.Declarations = append(tree.Declarations, c.convertEnum(declaration.Enum))
+		}
+	}
+
+	return tree
+}
+
+func (c *protoConverter) convertMessage(message *protoMessage) *parser.SchemaDefinition {
+	schema := &parser.SchemaDefinition{
+		Id:     parser.IdentifierExpression{Name: toEskemaName(message.Name)},
+		Fields: make([]*parser.FieldExpression, 0),
+	}
+
+	for _, field := range message.Fields {
+		schema.Fields = append(schema.Fields, c.convertField(field, message.Name))
+	}
+
+	return schema
+}
+
+func (c *protoConverter) convertField(field *protoField, scope string) *parser.FieldExpression {
+	fieldExpression := &parser.FieldExpression{
+		Id: parser.IdentifierExpression{Name: codestyle.ToCamelCase(field.Name)},
+	}
+
+	if field.IsMap {
+		key, _ := c.convertType(field, field.MapKey, scope)
+		value, _ := c.convertType(field, field.MapValue, scope)
+
+		fieldExpression.Type = importer.NewType("Map", key, value)
+
+		return fieldExpression
+	}
+
+	typeExpression, hasPresence := c.convertType(field, field.Type, scope)
+
+	if field.Label == repeatedLabel {
+		fieldExpression.Type = importer.NewType("Array", typeExpression)
+
+		return fieldExpression
+	}
+
+	fieldExpression.Type = typeExpression
+	fieldExpression.IsOptional = field.Label == optionalLabel || hasPresence
+
+	return fieldExpression
+}
+
+// convertType resolves a proto type reference, it also reports whether a singular
+// field of that type has presence in proto3, which is the case for every message
+func (c *protoConverter) convertType(field *protoField, name string, scope string) (*parser.TypeExpression, bool) {
+	if primitive, isPrimitive := protoPrimitives[name]; isPrimitive {
+		return importer.NewType(primitive), false
+	}
+
+	wellKnownName := strings.TrimPrefix(name, ".")
+
+	if wellKnown, isWellKnown := wellKnownTypes[wellKnownName]; isWellKnown {
+		return importer.NewType(wellKnown), true
+	}
+
+	if strings.HasPrefix(wellKnownName, "google.protobuf.") {
+		c.warn(field, "well-known type '"+wellKnownName+"' has no eskema equivalent and was imported as String")
+
+		return importer.NewType("String"), false
+	}
+
+	if resolved, exists := c.resolve(name, scope); exists {
+		return importer.NewType(toEskemaName(resolved)), c.declared[resolved].Message != nil
+	}
+
+	c.warn(field, "type '"+name+"' is not declared in this file and was imported as is")
+
+	segments := strings.Split(name, ".")
+
+	return importer.NewType(segments[len(segments)-1]), true
+}
+
+// resolve follows protobuf scoping rules, looking the name up from the innermost
+// message outwards
+func (c *protoConverter) resolve(name string, scope string) (string, bool) {
+	if strings.HasPrefix(name, ".") {
+		name = strings.TrimPrefix(name[1:], c.file.Package+".")
+
+		return name, c.declared[name] != nil
+	}
+
+	if c.file.Package != "" {
+		name = strings.TrimPrefix(name, c.file.Package+".")
+	}
+
+	for {
+		candidate := qualify(scope, name)
+
+		if c.declared[candidate] != nil {
+			return candidate, true
+		}
+
+		if scope == "" {
+			return "", false
+		}
+
+		if index := strings.LastIndex(scope, "."); index >= 0 {
+			scope = scope[:index]
+		} else {
+			scope = ""
+		}
+	}
+}
+
+// convertEnum keeps the numbers of the values explicit unless they are numbered
+// by position, values aliasing a number that's already taken are skipped since
+// eskema needs every value to be unique
+func (c *protoConverter) convertEnum(enum *protoEnum) *parser.EnumDefinition {
+	definition := &parser.EnumDefinition{
+		Id:     parser.IdentifierExpression{Name: toEskemaName(enum.Name)},
+		Values: make([]*parser.EnumValue, 0, len(enum.Values)),
+	}
+
+	isPositional := true
+	aliases := make(map[int64]string, len(enum.Values))
+
+	for i, value := range enum.Values {
+		isPositional = isPositional && value.Number == int64(i)
+	}
+
+	for _, value := range enum.Values {
+		if alias, isAlias := aliases[value.Number]; isAlias {
+			c.warnAt(value.Token, "'"+value.Name+"' is an alias of '"+alias+"' and was skipped")
+			continue
+		}
+
+		aliases[value.Number] = value.Name
+		enumValue := importer.NewEnumValue(value.Name)
+
+		if !isPositional {
+			number := strconv.FormatInt(value.Number, 10)
+			enumValue.Value = &parser.LiteralExpression{Kind: parser.NumberLiteral, Raw: number, Value: number}
+		}
+
+		definition.Values = append(definition.Values, enumValue)
+	}
+
+	return definition
+}
+
+func (c *protoConverter) warn(field *protoField, message string) {
+	c.warnAt(field.Token, message)
+}
+
+func (c *protoConverter) warnAt(token *protoToken, message string) {
+	c.warnings = append(c.warnings, &importer.Warning{
+		Metadata: token.Metadata,
+		Message:  message,
+	})
+}
+
+// toEskemaName flattens nested proto declarations, Outer.Inner becomes OuterInner
+func toEskemaName(name string) string {
+	return strings.ReplaceAll(name, ".", "")
+}
+
+func NewProtoImporter() importer.SchemaImporter {
+	return &ProtoImporter{}
+}
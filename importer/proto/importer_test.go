@@ -0,0 +1,187 @@
+package proto
+
+import (
+	"errors"
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/printer"
+	"github.com/Haato3o/eskema/core/syntax"
+	"testing"
+)
+
+func TestProtoImporter(t *testing.T) {
+	source := `syntax = "proto3";
+package shop.v1;
+
+message Order {
+  enum Status { UNKNOWN = 0; PAID = 1; }
+  message Line { string sku = 1; }
+  int64 order_id = 1;
+  repeated Line lines = 2;
+  map<string, int32> stock = 3;
+  optional string note = 4;
+  google.protobuf.Timestamp created_at = 5;
+  .shop.v1.Order.Status status = 6;
+  bytes receipt = 8;
+  google.protobuf.Duration ttl = 9;
+  oneof payment { string card = 7; }
+}
+
+service Orders { rpc Get(Order) returns (Order) {} }
+`
+	expected := `schema Order
+{
+    orderId: Int64,
+    lines: Array<OrderLine>,
+    stock: Map<String, Int32>,
+    note: String?,
+    createdAt: TimeStamp?,
+    status: OrderStatus,
+    receipt: Bytes,
+    ttl: Duration?
+};
+
+enum OrderStatus
+{
+    UNKNOWN,
+    PAID
+};
+
+schema OrderLine
+{
+    sku: String
+};
+`
+
+	tree, warnings, err := (&ProtoImporter{}).ImportSource([]byte(source), "test.proto")
+
+	t.Run("should import proto source without errors", func(t *testing.T) {
+		if err != nil {
+			t.Fatalf("got %v, expected no error", err)
+		}
+	})
+
+	t.Run("should convert messages and enums into eskema source", func(t *testing.T) {
+		if actual := printer.Print(tree); actual != expected {
+			t.Errorf("got\n%s\nexpected\n%s", actual, expected)
+		}
+	})
+
+	t.Run("should report unsupported constructs with their line", func(t *testing.T) {
+		expectedLines := []int64{15, 18}
+
+		if len(warnings) != len(expectedLines) {
+			t.Fatalf("got %d warnings, expected %d", len(warnings), len(expectedLines))
+		}
+
+		for i, warning := range warnings {
+			if warning.Metadata.Line != expectedLines[i] {
+				t.Errorf("got line %d, expected %d", warning.Metadata.Line, expectedLines[i])
+			}
+		}
+	})
+}
+
+func TestProtoImporterBytes(t *testing.T) {
+	source := `syntax = "proto3";
+
+message Blob {
+  bytes content = 1;
+  repeated bytes chunks = 2;
+  map<string, bytes> parts = 3;
+}
+`
+	expected := `schema Blob
+{
+    content: Bytes,
+    chunks: Array<Bytes>,
+    parts: Map<String, Bytes>
+};
+`
+
+	tree, warnings, err := (&ProtoImporter{}).ImportSource([]byte(source), "test.proto")
+
+	if err != nil {
+		t.Fatalf("got %v, expected no error", err)
+	}
+
+	t.Run("should import bytes as a primitive instead of a reference", func(t *testing.T) {
+		if actual := printer.Print(tree); actual != expected {
+			t.Errorf("got\n%s\nexpected\n%s", actual, expected)
+		}
+	})
+
+	t.Run("should not report warnings for bytes", func(t *testing.T) {
+		if len(warnings) != 0 {
+			t.Errorf("got %v, expected no warnings", warnings)
+		}
+	})
+}
+
+func TestProtoImporterSyntaxError(t *testing.T) {
+	_, _, err := (&ProtoImporter{}).ImportSource([]byte("message Broken {\n  string name = 1;\n  int32 = 2;\n}"), "test.proto")
+
+	var diagnostic *syntax.Diagnostic
+
+	if !errors.As(err, &diagnostic) {
+		t.Fatalf("got %v, expected a diagnostic", err)
+	}
+
+	if diagnostic.Code != syntax.CodeUnexpectedToken || diagnostic.Span.Start.Line != 3 || diagnostic.Message != "expected field name, got '='" {
+		t.Errorf("got %v, expected an unexpected token error at line 3", diagnostic)
+	}
+}
+
+func TestProtoImporterEnumNumbers(t *testing.T) {
+	source := `syntax = "proto3";
+
+enum Level {
+  option allow_alias = true;
+  LOW = 0;
+  HIGH = 10;
+  NEGATIVE = -1 [deprecated = true];
+  MAXIMUM = 0xA;
+}
+
+enum Color { RED = 0; GREEN = 1; }
+`
+	expected := `enum Level
+{
+    LOW = 0,
+    HIGH = 10,
+    NEGATIVE = -1
+};
+
+enum Color
+{
+    RED,
+    GREEN
+};
+`
+
+	tree, warnings, err := (&ProtoImporter{}).ImportSource([]byte(source), "test.proto")
+
+	if err != nil {
+		t.Fatalf("got %v, expected no error", err)
+	}
+
+	t.Run("should keep enum numbers unless values are numbered by position", func(t *testing.T) {
+		actual := printer.Print(tree)
+
+		if actual != expected {
+			t.Errorf("got\n%s\nexpected\n%s", actual, expected)
+		}
+
+		eskemaParser := parser.New(syntax.NewLexer([]byte(actual), "test.skm").Lex())
+		eskemaParser.Parse()
+
+		if errs := eskemaParser.Errors(); len(errs) > 0 {
+			t.Errorf("got %v, expected the imported schema to parse", errs)
+		}
+	})
+
+	t.Run("should report skipped aliases", func(t *testing.T) {
+		if len(warnings) != 1 || warnings[0].Message != "'MAXIMUM' is an alias of 'HIGH' and was skipped" || warnings[0].Metadata.Line != 8 {
+			t.Errorf("got %v, expected a warning for the alias", warnings)
+		}
+	})
+}
@@ -0,0 +1,166 @@
+package proto
+
+import (
+	"github.com/Haato3o/eskema/core/syntax"
+	"unicode"
+)
+
+type protoTokenType int
+
+const (
+	identifierToken protoTokenType = iota
+	numberToken
+	stringToken
+	symbolToken
+	endOfFileToken
+)
+
+type protoToken struct {
+	Metadata *syntax.Metadata
+	Value    string
+	Type     protoTokenType
+}
+
+type protoLexer struct {
+	fileName string
+	input    []rune
+	current  int
+	line     int64
+	column   int64
+}
+
+func (l *protoLexer) Lex() []*protoToken {
+	tokens := make([]*protoToken, 0)
+
+	for {
+		token := l.next()
+
+		tokens = append(tokens, token)
+
+		if token.Type == endOfFileToken {
+			return tokens
+		}
+	}
+}
+
+func (l *protoLexer) peekAt(offset int) rune {
+	index := l.current + offset
+
+	if index >= len(l.input) {
+		return 0
+	}
+
+	return l.input[index]
+}
+
+func (l *protoLexer) consume() rune {
+	char := l.input[l.current]
+	l.current++
+	l.column++
+
+	if char == '\n' {
+		l.line++
+		l.column = 1
+	}
+
+	return char
+}
+
+func (l *protoLexer) skipWhitespaceAndComments() {
+	for l.current < len(l.input) {
+		char := l.peekAt(0)
+
+		switch {
+		case unicode.IsSpace(char):
+			l.consume()
+		case char == '/' && l.peekAt(1) == '/':
+			for l.current < len(l.input) && l.peekAt(0) != '\n' {
+				l.consume()
+			}
+		case char == '/' && l.peekAt(1) == '*':
+			l.consume()
+			l.consume()
+
+			for l.current < len(l.input) && !(l.peekAt(0) == '*' && l.peekAt(1) == '/') {
+				l.consume()
+			}
+
+			if l.current < len(l.input) {
+				l.consume()
+				l.consume()
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (l *protoLexer) next() *protoToken {
+	l.skipWhitespaceAndComments()
+
+	metadata := &syntax.Metadata{
+		Filename: l.fileName,
+		Offset:   int64(l.current),
+		Line:     l.line,
+		Column:   l.column,
+	}
+
+	if l.current >= len(l.input) {
+		return &protoToken{Metadata: metadata, Type: endOfFileToken}
+	}
+
+	start := l.current
+	char := l.peekAt(0)
+
+	switch {
+	case isIdentifierStart(char) || (char == '.' && isIdentifierStart(l.peekAt(1))):
+		for l.current < len(l.input) && (isIdentifierPart(l.peekAt(0)) || l.peekAt(0) == '.') {
+			l.consume()
+		}
+
+		return &protoToken{Metadata: metadata, Value: string(l.input[start:l.current]), Type: identifierToken}
+	case unicode.IsDigit(char):
+		for l.current < len(l.input) && (isIdentifierPart(l.peekAt(0)) || l.peekAt(0) == '.') {
+			l.consume()
+		}
+
+		return &protoToken{Metadata: metadata, Value: string(l.input[start:l.current]), Type: numberToken}
+	case char == '"' || char == '\'':
+		quote := l.consume()
+
+		for l.current < len(l.input) && l.peekAt(0) != quote {
+			if l.consume() == '\\' && l.current < len(l.input) {
+				l.consume()
+			}
+		}
+
+		end := l.current
+
+		if l.current < len(l.input) {
+			l.consume()
+		}
+
+		return &protoToken{Metadata: metadata, Value: string(l.input[start+1 : end]), Type: stringToken}
+	default:
+		l.consume()
+
+		return &protoToken{Metadata: metadata, Value: string(char), Type: symbolToken}
+	}
+}
+
+func isIdentifierStart(char rune) bool {
+	return char == '_' || unicode.IsLetter(char)
+}
+
+func isIdentifierPart(char rune) bool {
+	return isIdentifierStart(char) || unicode.IsDigit(char)
+}
+
+func newProtoLexer(input []byte, fileName string) *protoLexer {
+	return &protoLexer{
+		fileName: fileName,
+		input:    []rune(string(input)),
+		line:     1,
+		column:   1,
+	}
+}
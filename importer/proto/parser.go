@@ -0,0 +1,438 @@
+package proto
+
+import (
+	"fmt"
+	"github.com/Haato3o/eskema/core/syntax"
+	"github.com/Haato3o/eskema/importer"
+	"strconv"
+)
+
+type fieldLabel int
+
+const (
+	noLabel fieldLabel = iota
+	repeatedLabel
+	optionalLabel
+)
+
+type protoField struct {
+	Token    *protoToken
+	Name     string
+	Type     string
+	Label    fieldLabel
+	MapKey   string
+	MapValue string
+	IsMap    bool
+}
+
+type protoMessage struct {
+	Name   string
+	Fields []*protoField
+}
+
+type protoEnumValue struct {
+	Token  *protoToken
+	Name   string
+	Number int64
+}
+
+type protoEnum struct {
+	Name   string
+	Values []*protoEnumValue
+}
+
+type protoDeclaration struct {
+	Message *protoMessage
+	Enum    *protoEnum
+}
+
+type protoFile struct {
+	Package      string
+	Declarations []*protoDeclaration
+}
+
+type protoParser struct {
+	tokens   []*protoToken
+	current  int
+	file     *protoFile
+	warnings []*importer.Warning
+}
+
+func (p *protoParser) peek() *protoToken {
+	return p.tokens[p.current]
+}
+
+func (p *protoParser) next() *protoToken {
+	token := p.tokens[p.current]
+
+	if token.Type != endOfFileToken {
+		p.current++
+	}
+
+	return token
+}
+
+func (p *protoParser) warn(token *protoToken, format string, args ...interface{}) {
+	p.warnings = append(p.warnings, &importer.Warning{
+		Metadata: token.Metadata,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (p *protoParser) expect(value string) (*protoToken, error) {
+	token := p.next()
+
+	if token.Value != value || token.Type == stringToken {
+		return token, unexpectedToken(token, fmt.Sprintf("'%s'", value))
+	}
+
+	return token, nil
+}
+
+func (p *protoParser) expectType(tokenType protoTokenType, description string) (*protoToken, error) {
+	token := p.next()
+
+	if token.Type != tokenType {
+		return token, unexpectedToken(token, description)
+	}
+
+	return token, nil
+}
+
+func (p *protoParser) Parse() (*protoFile, error) {
+	for {
+		token := p.peek()
+
+		if token.Type == endOfFileToken {
+			return p.file, nil
+		}
+
+		if err := p.parseTopLevel(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (p *protoParser) parseTopLevel() error {
+	token := p.next()
+
+	switch token.Value {
+	case "syntax":
+		if _, err := p.expect("="); err != nil {
+			return err
+		}
+
+		version, err := p.expectType(stringToken, "string")
+
+		if err != nil {
+			return err
+		}
+
+		if version.Value != "proto3" {
+			p.warn(version, "'%s' is not proto3, proto2 only semantics are ignored", version.Value)
+		}
+
+		_, err = p.expect(";")
+		return err
+	case "package":
+		name, err := p.expectType(identifierToken, "package name")
+
+		if err != nil {
+			return err
+		}
+
+		p.file.Package = name.Value
+
+		_, err = p.expect(";")
+		return err
+	case "import", "option":
+		return p.skipStatement()
+	case "message":
+		return p.parseMessage("")
+	case "enum":
+		return p.parseEnum("")
+	case "service":
+		p.warn(token, "services are not supported and were skipped")
+		return p.skipStatement()
+	case "extend":
+		p.warn(token, "extensions are not supported and were skipped")
+		return p.skipStatement()
+	case ";":
+		return nil
+	default:
+		return unexpectedToken(token, "'message', 'enum' or 'service'")
+	}
+}
+
+func (p *protoParser) parseMessage(scope string) error {
+	name, err := p.expectType(identifierToken, "message name")
+
+	if err != nil {
+		return err
+	}
+
+	message := &protoMessage{
+		Name:   qualify(scope, name.Value),
+		Fields: make([]*protoField, 0),
+	}
+
+	p.file.Declarations = append(p.file.Declarations, &protoDeclaration{Message: message})
+
+	if _, err := p.expect("{"); err != nil {
+		return err
+	}
+
+	for {
+		token := p.peek()
+
+		switch token.Value {
+		case "}":
+			p.next()
+			return nil
+		case "message":
+			p.next()
+			err = p.parseMessage(message.Name)
+		case "enum":
+			p.next()
+			err = p.parseEnum(message.Name)
+		case "oneof":
+			p.next()
+			p.warn(token, "oneof is not supported, its fields were skipped")
+			err = p.skipStatement()
+		case "extensions":
+			p.next()
+			p.warn(token, "extension ranges are not supported and were skipped")
+			err = p.skipStatement()
+		case "extend":
+			p.next()
+			p.warn(token, "extensions are not supported and were skipped")
+			err = p.skipStatement()
+		case "group":
+			p.next()
+			p.warn(token, "groups are not supported and were skipped")
+			err = p.skipStatement()
+		case "option", "reserved":
+			p.next()
+			err = p.skipStatement()
+		case ";":
+			p.next()
+		default:
+			if token.Type == endOfFileToken {
+				return unexpectedToken(token, "'}'")
+			}
+
+			var field *protoField
+			field, err = p.parseField()
+
+			if field != nil {
+				message.Fields = append(message.Fields, field)
+			}
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (p *protoParser) parseField() (*protoField, error) {
+	field := &protoField{Token: p.peek()}
+
+	switch field.Token.Value {
+	case "repeated":
+		p.next()
+		field.Label = repeatedLabel
+	case "optional":
+		p.next()
+		field.Label = optionalLabel
+	case "required":
+		p.next()
+		p.warn(field.Token, "'required' is a proto2 label and was ignored")
+	}
+
+	typeName, err := p.expectType(identifierToken, "field type")
+
+	if err != nil {
+		return nil, err
+	}
+
+	if typeName.Value == "map" && p.peek().Value == "<" {
+		p.next()
+		field.IsMap = true
+
+		key, err := p.expectType(identifierToken, "map key type")
+
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := p.expect(","); err != nil {
+			return nil, err
+		}
+
+		value, err := p.expectType(identifierToken, "map value type")
+
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := p.expect(">"); err != nil {
+			return nil, err
+		}
+
+		field.MapKey = key.Value
+		field.MapValue = value.Value
+	} else {
+		field.Type = typeName.Value
+	}
+
+	name, err := p.expectType(identifierToken, "field name")
+
+	if err != nil {
+		return nil, err
+	}
+
+	field.Name = name.Value
+
+	return field, p.skipStatement()
+}
+
+func (p *protoParser) parseEnum(scope string) error {
+	name, err := p.expectType(identifierToken, "enum name")
+
+	if err != nil {
+		return err
+	}
+
+	enum := &protoEnum{
+		Name:   qualify(scope, name.Value),
+		Values: make([]*protoEnumValue, 0),
+	}
+
+	p.file.Declarations = append(p.file.Declarations, &protoDeclaration{Enum: enum})
+
+	if _, err := p.expect("{"); err != nil {
+		return err
+	}
+
+	for {
+		token := p.next()
+
+		switch {
+		case token.Value == "}":
+			return nil
+		case token.Value == ";":
+			continue
+		case token.Value == "option" || token.Value == "reserved":
+			err = p.skipStatement()
+		case token.Type == identifierToken:
+			var value *protoEnumValue
+
+			if value, err = p.parseEnumValue(token); err == nil {
+				enum.Values = append(enum.Values, value)
+				err = p.skipStatement()
+			}
+		default:
+			err = unexpectedToken(token, "enum value")
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// parseEnumValue reads the number after the name of an enum value, which can be
+// negative and written in hex or octal
+func (p *protoParser) parseEnumValue(name *protoToken) (*protoEnumValue, error) {
+	if _, err := p.expect("="); err != nil {
+		return nil, err
+	}
+
+	sign := ""
+
+	if p.peek().Value == "-" && p.peek().Type == symbolToken {
+		p.next()
+		sign = "-"
+	}
+
+	number, err := p.expectType(numberToken, "enum value number")
+
+	if err != nil {
+		return nil, err
+	}
+
+	value, parseErr := strconv.ParseInt(sign+number.Value, 0, 32)
+
+	if parseErr != nil {
+		return nil, unexpectedToken(number, "enum value number")
+	}
+
+	return &protoEnumValue{Token: name, Name: name.Value, Number: value}, nil
+}
+
+// skipStatement consumes everything until the end of the current statement,
+// which is either a ';' or a balanced '{ }' block
+func (p *protoParser) skipStatement() error {
+	depth := 0
+
+	for {
+		token := p.next()
+
+		if token.Type == endOfFileToken {
+			return unexpectedToken(token, "';' or '}'")
+		}
+
+		if token.Type != symbolToken {
+			continue
+		}
+
+		switch token.Value {
+		case "{":
+			depth++
+		case "}":
+			depth--
+
+			if depth == 0 {
+				return nil
+			}
+		case ";":
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+func qualify(scope string, name string) string {
+	if scope == "" {
+		return name
+	}
+
+	return scope + "." + name
+}
+
+// unexpectedToken reports the token the same way the eskema parser does, so
+// both are rendered as diagnostics pointing at the source
+func unexpectedToken(token *protoToken, expected string) error {
+	value := token.Value
+
+	if token.Type == endOfFileToken {
+		value = "EOF"
+	}
+
+	return &syntax.Diagnostic{
+		Severity: syntax.SeverityError,
+		Code:     syntax.CodeUnexpectedToken,
+		Message:  fmt.Sprintf("expected %s, got '%s'", expected, value),
+		Span:     syntax.Span{Start: token.Metadata, Length: len(token.Value)},
+	}
+}
+
+func newProtoParser(tokens []*protoToken) *protoParser {
+	return &protoParser{
+		tokens:   tokens,
+		file:     &protoFile{Declarations: make([]*protoDeclaration, 0)},
+		warnings: make([]*importer.Warning, 0),
+	}
+}
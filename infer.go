@@ -0,0 +1,28 @@
+package main
+
+import (
+	"github.com/Haato3o/eskema/cli"
+	"github.com/Haato3o/eskema/core/printer"
+	"github.com/Haato3o/eskema/importer/inference"
+	"log"
+)
+
+func runInfer(arguments []string) {
+	args := cli.ParseInferArguments(arguments)
+
+	if err := args.VerifyRequired(); err != nil {
+		log.Fatalln(err)
+	}
+
+	tree, warnings, err := inference.InferFromFiles(args.RootName, args.FileNames...)
+
+	for _, warning := range warnings {
+		log.Println(warning)
+	}
+
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	writeOutput(args.Output, printer.Print(tree))
+}
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"github.com/Haato3o/eskema/lsp"
+	"log"
+	"os"
+)
+
+// runLsp serves the language server over stdio, logs go to stderr so they don't
+// get mixed with the protocol messages
+func runLsp(_ []string) {
+	server := lsp.NewServer(os.Stdin, os.Stdout)
+
+	if err := server.Run(); err != nil {
+		log.Fatalln(err)
+	}
+}
@@ -0,0 +1,230 @@
+package lsp
+
+import (
+	"fmt"
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/syntax"
+	"net/url"
+)
+
+type symbolKind int
+
+const (
+	schemaSymbol symbolKind = iota
+	enumSymbol
+	unionSymbol
+	aliasSymbol
+	constSymbol
+)
+
+// document is the analysis of a single open file, names are indexed straight
+// from the tokens since the tree doesn't keep track of where things are
+type document struct {
+	Uri          string
+	Text         string
+	Tree         *parser.EskemaTree
+	Tokens       []*syntax.Token
+	Diagnostics  []Diagnostic
+	Declarations map[string]*syntax.Token
+	Kinds        map[string]symbolKind
+	References   map[string][]*syntax.Token
+	Fields       map[*syntax.Token]bool
+}
+
+func newDocument(uri string, text string) *document {
+	doc := &document{
+		Uri:          uri,
+		Text:         text,
+		Diagnostics:  make([]Diagnostic, 0),
+		Declarations: make(map[string]*syntax.Token),
+		Kinds:        make(map[string]symbolKind),
+		References:   make(map[string][]*syntax.Token),
+		Fields:       make(map[*syntax.Token]bool),
+	}
+
+	stream := syntax.NewLexer([]byte(text), uriToPath(uri)).Lex()
+	doc.Tokens = stream.Tokens()
+
+	eskemaParser := parser.New(stream)
+	doc.Tree = eskemaParser.Parse()
+
+	for _, diagnostic := range eskemaParser.Diagnostics() {
+		doc.addDiagnostic(diagnostic)
+	}
+
+	doc.index()
+
+	return doc
+}
+
+func (d *document) addDiagnostic(diagnostic *syntax.Diagnostic) {
+	severity := DiagnosticError
+
+	if diagnostic.Severity != syntax.SeverityError {
+		severity = DiagnosticWarning
+	}
+
+	d.Diagnostics = append(d.Diagnostics, Diagnostic{
+		Range:    spanRange(diagnostic.Span),
+		Severity: severity,
+		Code:     diagnostic.Code,
+		Source:   "eskema",
+		Message:  diagnostic.Message,
+	})
+}
+
+// index walks the tokens keeping track of the declaration being read, literals
+// in a type position are references unless they name a generic of the schema.
+// The parents of a schema come after its generics in the header
+func (d *document) index() {
+	var kind symbolKind
+	var generics map[string]bool
+
+	isDeclaringName := false
+	isInsideHeader := false
+	isInsideParents := false
+	isInsideBody := false
+	typeReferences := make([]*syntax.Token, 0)
+
+	for i, token := range d.Tokens {
+		switch token.Type {
+		case syntax.KeywordToken:
+			_, keyword := syntax.IsKeyword(token.Value)
+			kind = schemaSymbol
+
+			switch keyword {
+			case syntax.EnumKeyword:
+				kind = enumSymbol
+			case syntax.UnionKeyword:
+				kind = unionSymbol
+			case syntax.AliasKeyword:
+				kind = aliasSymbol
+			case syntax.ConstKeyword:
+				kind = constSymbol
+			}
+
+			isDeclaringName = true
+			isInsideHeader = true
+			isInsideParents = false
+			generics = make(map[string]bool)
+		case syntax.ScopeStartToken:
+			isInsideHeader = false
+			isInsideBody = true
+		case syntax.ScopeEndToken:
+			isInsideBody = false
+		case syntax.LiteralToken:
+			switch {
+			case isDeclaringName:
+				isDeclaringName = false
+
+				if _, exists := d.Declarations[token.Value]; !exists {
+					d.Declarations[token.Value] = token
+					d.Kinds[token.Value] = kind
+				}
+
+				d.References[token.Value] = append(d.References[token.Value], token)
+			case i > 0 && d.Tokens[i-1].Type == syntax.AtToken:
+				// the name of an annotation isn't a type
+			case kind == aliasSymbol || kind == constSymbol:
+				typeReferences = append(typeReferences, token)
+			case isInsideHeader && (token.Value == syntax.ExtendsModifier || token.Value == syntax.IncludesModifier):
+				isInsideParents = true
+			case isInsideHeader && isInsideParents:
+				typeReferences = append(typeReferences, token)
+			case isInsideHeader:
+				generics[token.Value] = true
+			case isInsideBody && kind == schemaSymbol:
+				if i+1 < len(d.Tokens) && d.Tokens[i+1].Type == syntax.ColonToken {
+					d.Fields[token] = true
+				} else if !generics[token.Value] {
+					typeReferences = append(typeReferences, token)
+				}
+			case isInsideBody && kind == unionSymbol:
+				typeReferences = append(typeReferences, token)
+			}
+		}
+	}
+
+	for _, token := range typeReferences {
+		d.References[token.Value] = append(d.References[token.Value], token)
+
+		if _, isDeclared := d.Declarations[token.Value]; !isDeclared {
+			d.addDiagnostic(&syntax.Diagnostic{
+				Severity: syntax.SeverityWarning,
+				Code:     syntax.CodeUnknownType,
+				Message:  fmt.Sprintf("unknown type '%s'", token.Value),
+				Span:     syntax.SpanOf(token),
+			})
+		}
+	}
+}
+
+// symbolAt returns the name of the schema or enum referenced at the position
+func (d *document) symbolAt(position Position) (string, *syntax.Token) {
+	for name, tokens := range d.References {
+		for _, token := range tokens {
+			if contains(tokenRange(token), position) {
+				return name, token
+			}
+		}
+	}
+
+	return "", nil
+}
+
+func (d *document) tokenAt(position Position) *syntax.Token {
+	for _, token := range d.Tokens {
+		if contains(tokenRange(token), position) {
+			return token
+		}
+	}
+
+	return nil
+}
+
+func (d *document) fullRange() Range {
+	end := Position{}
+
+	for _, char := range d.Text {
+		if char == '\n' {
+			end.Line++
+			end.Character = 0
+		} else {
+			end.Character++
+		}
+	}
+
+	return Range{End: end}
+}
+
+func tokenRange(token *syntax.Token) Range {
+	return spanRange(syntax.SpanOf(token))
+}
+
+func spanRange(span syntax.Span) Range {
+	start := Position{
+		Line:      int(span.Start.Line) - 1,
+		Character: int(span.Start.Column) - 1,
+	}
+
+	return Range{
+		Start: start,
+		End:   Position{Line: start.Line, Character: start.Character + span.Length},
+	}
+}
+
+func contains(r Range, position Position) bool {
+	return position.Line == r.Start.Line &&
+		position.Character >= r.Start.Character &&
+		position.Character <= r.End.Character
+}
+
+func uriToPath(uri string) string {
+	parsed, err := url.Parse(uri)
+
+	if err != nil || parsed.Scheme != "file" {
+		return uri
+	}
+
+	return parsed.Path
+}
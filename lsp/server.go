@@ -0,0 +1,450 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/printer"
+	"github.com/Haato3o/eskema/core/syntax"
+	"io"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+type handler func(s *Server, params json.RawMessage) (interface{}, error)
+
+var handlers = map[string]handler{
+	"initialize":                      (*Server).initialize,
+	"shutdown":                        (*Server).shutdown,
+	"textDocument/completion":         (*Server).completion,
+	"textDocument/definition":         (*Server).definition,
+	"textDocument/references":         (*Server).references,
+	"textDocument/hover":              (*Server).hover,
+	"textDocument/rename":             (*Server).rename,
+	"textDocument/formatting":         (*Server).formatting,
+	"textDocument/didOpen":            (*Server).didOpen,
+	"textDocument/didChange":          (*Server).didChange,
+	"textDocument/didClose":           (*Server).didClose,
+	"initialized":                     (*Server).ignore,
+	"$/cancelRequest":                 (*Server).ignore,
+	"$/setTrace":                      (*Server).ignore,
+	"workspace/didChangeWatchedFiles": (*Server).ignore,
+}
+
+// Server is a language server for eskema files that speaks the Language Server
+// Protocol, documents are fully synchronized and analyzed on every change
+type Server struct {
+	reader     *bufio.Reader
+	writer     io.Writer
+	documents  map[string]*document
+	isShutdown bool
+}
+
+type requestError struct {
+	Code    int
+	Message string
+}
+
+func (e *requestError) Error() string {
+	return e.Message
+}
+
+func (s *Server) Run() error {
+	for {
+		body, err := readMessage(s.reader)
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		msg := &message{}
+
+		if err := json.Unmarshal(body, msg); err != nil {
+			s.respondError(nil, ErrParseError, err.Error())
+			continue
+		}
+
+		if msg.Method == "exit" {
+			return nil
+		}
+
+		s.handle(msg)
+	}
+}
+
+func (s *Server) handle(msg *message) {
+	// a bug in a single handler shouldn't take the whole server down with it
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			log.Printf("panic while handling '%s': %v", msg.Method, recovered)
+
+			if msg.Id != nil {
+				s.respondError(msg.Id, ErrInternalError, fmt.Sprintf("internal error while handling '%s'", msg.Method))
+			}
+		}
+	}()
+
+	method, exists := handlers[msg.Method]
+
+	if !exists {
+		if msg.Id != nil {
+			s.respondError(msg.Id, ErrMethodNotFound, fmt.Sprintf("method '%s' is not supported", msg.Method))
+		}
+
+		return
+	}
+
+	if s.isShutdown && msg.Id != nil {
+		s.respondError(msg.Id, ErrInvalidRequest, "server is shutting down")
+		return
+	}
+
+	result, err := method(s, msg.Params)
+
+	if msg.Id == nil {
+		if err != nil {
+			log.Println(err)
+		}
+
+		return
+	}
+
+	if err != nil {
+		code := ErrInvalidParams
+
+		if reqErr, isRequestError := err.(*requestError); isRequestError {
+			code = reqErr.Code
+		}
+
+		s.respondError(msg.Id, code, err.Error())
+		return
+	}
+
+	s.send(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      msg.Id,
+		"result":  result,
+	})
+}
+
+func (s *Server) respondError(id *json.RawMessage, code int, message string) {
+	s.send(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"error":   &responseError{Code: code, Message: message},
+	})
+}
+
+func (s *Server) notify(method string, params interface{}) {
+	s.send(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+	})
+}
+
+func (s *Server) send(payload interface{}) {
+	if err := writeMessage(s.writer, payload); err != nil {
+		log.Println(err)
+	}
+}
+
+func (s *Server) initialize(_ json.RawMessage) (interface{}, error) {
+	return map[string]interface{}{
+		"capabilities": map[string]interface{}{
+			"textDocumentSync":           textDocumentSyncFull,
+			"completionProvider":         map[string]interface{}{},
+			"definitionProvider":         true,
+			"referencesProvider":         true,
+			"hoverProvider":              true,
+			"renameProvider":             true,
+			"documentFormattingProvider": true,
+		},
+		"serverInfo": map[string]string{
+			"name": "eskema",
+		},
+	}, nil
+}
+
+func (s *Server) shutdown(_ json.RawMessage) (interface{}, error) {
+	s.isShutdown = true
+
+	return nil, nil
+}
+
+func (s *Server) ignore(_ json.RawMessage) (interface{}, error) {
+	return nil, nil
+}
+
+func (s *Server) didOpen(params json.RawMessage) (interface{}, error) {
+	var p didOpenParams
+
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	s.update(p.TextDocument.Uri, p.TextDocument.Text)
+
+	return nil, nil
+}
+
+func (s *Server) didChange(params json.RawMessage) (interface{}, error) {
+	var p didChangeParams
+
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	if len(p.ContentChanges) == 0 {
+		return nil, nil
+	}
+
+	// Documents are synchronized in full, so the last change holds the whole text
+	s.update(p.TextDocument.Uri, p.ContentChanges[len(p.ContentChanges)-1].Text)
+
+	return nil, nil
+}
+
+func (s *Server) didClose(params json.RawMessage) (interface{}, error) {
+	var p didCloseParams
+
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	delete(s.documents, p.TextDocument.Uri)
+
+	s.notify("textDocument/publishDiagnostics", &publishDiagnosticsParams{
+		Uri:         p.TextDocument.Uri,
+		Diagnostics: make([]Diagnostic, 0),
+	})
+
+	return nil, nil
+}
+
+func (s *Server) update(uri string, text string) {
+	doc := newDocument(uri, text)
+	s.documents[uri] = doc
+
+	s.notify("textDocument/publishDiagnostics", &publishDiagnosticsParams{
+		Uri:         uri,
+		Diagnostics: doc.Diagnostics,
+	})
+}
+
+func (s *Server) completion(params json.RawMessage) (interface{}, error) {
+	doc, _, err := s.documentAt(params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]CompletionItem, 0)
+
+	for _, primitive := range syntax.Primitives() {
+		items = append(items, CompletionItem{Label: primitive, Kind: CompletionKindKeyword, Detail: "primitive"})
+	}
+
+	for _, keyword := range syntax.Keywords() {
+		items = append(items, CompletionItem{Label: keyword, Kind: CompletionKindKeyword, Detail: "keyword"})
+	}
+
+	for _, modifier := range []string{syntax.ExtendsModifier, syntax.IncludesModifier} {
+		items = append(items, CompletionItem{Label: modifier, Kind: CompletionKindKeyword, Detail: "keyword"})
+	}
+
+	names := make([]string, 0, len(doc.Declarations))
+
+	for name := range doc.Declarations {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		switch doc.Kinds[name] {
+		case enumSymbol:
+			items = append(items, CompletionItem{Label: name, Kind: CompletionKindEnum, Detail: "enum"})
+		case unionSymbol:
+			items = append(items, CompletionItem{Label: name, Kind: CompletionKindInterface, Detail: "union"})
+		case aliasSymbol:
+			items = append(items, CompletionItem{Label: name, Kind: CompletionKindAlias, Detail: "alias"})
+		case constSymbol:
+			items = append(items, CompletionItem{Label: name, Kind: CompletionKindConstant, Detail: "const"})
+		default:
+			items = append(items, CompletionItem{Label: name, Kind: CompletionKindClass, Detail: "schema"})
+		}
+	}
+
+	return items, nil
+}
+
+func (s *Server) definition(params json.RawMessage) (interface{}, error) {
+	doc, position, err := s.documentAt(params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	name, _ := doc.symbolAt(position)
+	declaration, exists := doc.Declarations[name]
+
+	if !exists {
+		return nil, nil
+	}
+
+	return &Location{Uri: doc.Uri, Range: tokenRange(declaration)}, nil
+}
+
+func (s *Server) references(params json.RawMessage) (interface{}, error) {
+	doc, position, err := s.documentAt(params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	name, _ := doc.symbolAt(position)
+	locations := make([]Location, 0)
+
+	for _, token := range doc.References[name] {
+		locations = append(locations, Location{Uri: doc.Uri, Range: tokenRange(token)})
+	}
+
+	return locations, nil
+}
+
+func (s *Server) hover(params json.RawMessage) (interface{}, error) {
+	doc, position, err := s.documentAt(params)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if token := doc.tokenAt(position); token != nil && token.Type == syntax.PrimitiveTypeToken {
+		return &Hover{
+			Contents: MarkupContent{Kind: "markdown", Value: fmt.Sprintf("primitive `%s`", token.Value)},
+			Range:    tokenRange(token),
+		}, nil
+	}
+
+	name, token := doc.symbolAt(position)
+
+	if _, isDeclared := doc.Declarations[name]; !isDeclared {
+		return nil, nil
+	}
+
+	for _, declaration := range doc.Tree.Declarations {
+		if declaration.Name() != name {
+			continue
+		}
+
+		source := printer.Print(&parser.EskemaTree{Declarations: []parser.Declaration{declaration}})
+
+		return &Hover{
+			Contents: MarkupContent{Kind: "markdown", Value: "```\n" + strings.TrimSpace(source) + "\n```"},
+			Range:    tokenRange(token),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+func (s *Server) rename(params json.RawMessage) (interface{}, error) {
+	var p renameParams
+
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	doc, exists := s.documents[p.TextDocument.Uri]
+
+	if !exists {
+		return nil, &requestError{Code: ErrInvalidParams, Message: "document is not open"}
+	}
+
+	if !identifierPattern.MatchString(p.NewName) {
+		return nil, &requestError{Code: ErrInvalidParams, Message: fmt.Sprintf("'%s' is not a valid name", p.NewName)}
+	}
+
+	if isReserved, _ := syntax.IsPrimitiveType(p.NewName); isReserved {
+		return nil, &requestError{Code: ErrInvalidParams, Message: fmt.Sprintf("'%s' is a primitive type", p.NewName)}
+	}
+
+	if isReserved, _ := syntax.IsKeyword(p.NewName); isReserved {
+		return nil, &requestError{Code: ErrInvalidParams, Message: fmt.Sprintf("'%s' is a keyword", p.NewName)}
+	}
+
+	name, _ := doc.symbolAt(p.Position)
+
+	if _, isDeclared := doc.Declarations[name]; !isDeclared {
+		return nil, &requestError{Code: ErrInvalidParams, Message: "there is no schema, enum, union or alias to rename here"}
+	}
+
+	if _, isTaken := doc.Declarations[p.NewName]; isTaken && p.NewName != name {
+		return nil, &requestError{Code: ErrInvalidParams, Message: fmt.Sprintf("'%s' is already declared", p.NewName)}
+	}
+
+	edits := make([]TextEdit, 0)
+
+	for _, token := range doc.References[name] {
+		edits = append(edits, TextEdit{Range: tokenRange(token), NewText: p.NewName})
+	}
+
+	return &WorkspaceEdit{Changes: map[string][]TextEdit{doc.Uri: edits}}, nil
+}
+
+func (s *Server) formatting(params json.RawMessage) (interface{}, error) {
+	var p documentFormattingParams
+
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, err
+	}
+
+	doc, exists := s.documents[p.TextDocument.Uri]
+
+	if !exists {
+		return nil, &requestError{Code: ErrInvalidParams, Message: "document is not open"}
+	}
+
+	formatted, err := printer.Format([]byte(doc.Text), uriToPath(doc.Uri))
+
+	// Documents with syntax errors are left untouched, diagnostics already report them
+	if err != nil || formatted == doc.Text {
+		return make([]TextEdit, 0), nil
+	}
+
+	return []TextEdit{{Range: doc.fullRange(), NewText: formatted}}, nil
+}
+
+func (s *Server) documentAt(params json.RawMessage) (*document, Position, error) {
+	var p textDocumentPositionParams
+
+	if err := json.Unmarshal(params, &p); err != nil {
+		return nil, Position{}, err
+	}
+
+	doc, exists := s.documents[p.TextDocument.Uri]
+
+	if !exists {
+		return nil, Position{}, &requestError{Code: ErrInvalidParams, Message: "document is not open"}
+	}
+
+	return doc, p.Position, nil
+}
+
+func NewServer(reader io.Reader, writer io.Writer) *Server {
+	return &Server{
+		reader:    bufio.NewReader(reader),
+		writer:    writer,
+		documents: make(map[string]*document),
+	}
+}
@@ -0,0 +1,298 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testUri = "file:///tmp/example.skm"
+
+const testSource = `enum Status
+{
+    Active
+};
+
+schema Page<T>
+{
+    items: Array<T>,
+    status: Status
+};
+
+schema User
+{
+    page: Page<User>,
+    role: Role
+};
+`
+
+// session feeds the requests to a server and returns every message it wrote back
+func session(t *testing.T, requests ...map[string]interface{}) []map[string]interface{} {
+	input := &bytes.Buffer{}
+	output := &bytes.Buffer{}
+
+	for _, request := range requests {
+		request["jsonrpc"] = "2.0"
+
+		if err := writeMessage(input, request); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := NewServer(input, output).Run(); err != nil {
+		t.Fatalf("got %v, expected no error", err)
+	}
+
+	reader := bufio.NewReader(output)
+	responses := make([]map[string]interface{}, 0)
+
+	for reader.Buffered() > 0 || output.Len() > 0 {
+		body, err := readMessage(reader)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		response := make(map[string]interface{})
+
+		if err := json.Unmarshal(body, &response); err != nil {
+			t.Fatal(err)
+		}
+
+		responses = append(responses, response)
+	}
+
+	return responses
+}
+
+func openDocument() map[string]interface{} {
+	return map[string]interface{}{
+		"method": "textDocument/didOpen",
+		"params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": testUri, "text": testSource},
+		},
+	}
+}
+
+func positionRequest(id int, method string, line int, character int) map[string]interface{} {
+	return map[string]interface{}{
+		"id":     id,
+		"method": method,
+		"params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": testUri},
+			"position":     map[string]interface{}{"line": line, "character": character},
+		},
+	}
+}
+
+func resultOf(t *testing.T, responses []map[string]interface{}, id int) interface{} {
+	for _, response := range responses {
+		if responseId, hasId := response["id"].(float64); hasId && int(responseId) == id {
+			if response["error"] != nil {
+				t.Fatalf("got error %v, expected a result", response["error"])
+			}
+
+			return response["result"]
+		}
+	}
+
+	t.Fatalf("got no response for request %d", id)
+
+	return nil
+}
+
+func TestDiagnostics(t *testing.T) {
+	responses := session(t, openDocument())
+
+	if len(responses) != 1 || responses[0]["method"] != "textDocument/publishDiagnostics" {
+		t.Fatalf("got %v, expected a single publishDiagnostics notification", responses)
+	}
+
+	diagnostics := responses[0]["params"].(map[string]interface{})["diagnostics"].([]interface{})
+
+	if len(diagnostics) != 1 {
+		t.Fatalf("got %d diagnostics, expected 1", len(diagnostics))
+	}
+
+	diagnostic := diagnostics[0].(map[string]interface{})
+	start := diagnostic["range"].(map[string]interface{})["start"].(map[string]interface{})
+
+	if diagnostic["message"] != "unknown type 'Role'" || start["line"] != float64(14) || start["character"] != float64(10) {
+		t.Errorf("got %v, expected unknown type 'Role' at 14:10", diagnostic)
+	}
+}
+
+func TestDefinitionAndReferences(t *testing.T) {
+	responses := session(t,
+		openDocument(),
+		positionRequest(1, "textDocument/definition", 13, 12),
+		positionRequest(2, "textDocument/references", 8, 13),
+		positionRequest(3, "textDocument/definition", 7, 17),
+	)
+
+	definition := resultOf(t, responses, 1).(map[string]interface{})
+	start := definition["range"].(map[string]interface{})["start"].(map[string]interface{})
+
+	if start["line"] != float64(5) || start["character"] != float64(7) {
+		t.Errorf("got %v, expected Page to be declared at 5:7", start)
+	}
+
+	if references := resultOf(t, responses, 2).([]interface{}); len(references) != 2 {
+		t.Errorf("got %d references to Status, expected 2", len(references))
+	}
+
+	if generic := resultOf(t, responses, 3); generic != nil {
+		t.Errorf("got %v, expected generics to have no definition", generic)
+	}
+}
+
+func TestUnionVariantsAreReferences(t *testing.T) {
+	doc := newDocument(testUri, "schema Card { };\nunion Payment: type { Card, Cash };")
+
+	if references := doc.References["Card"]; len(references) != 2 {
+		t.Errorf("got %d references to Card, expected the declaration and the variant", len(references))
+	}
+
+	if doc.Kinds["Payment"] != unionSymbol || len(doc.References["type"]) != 0 {
+		t.Errorf("got %v, expected Payment to be a union and its discriminator not to be a reference", doc.Kinds)
+	}
+
+	if len(doc.Diagnostics) != 2 {
+		t.Errorf("got %v, expected Cash to be reported as unknown and as an invalid variant", doc.Diagnostics)
+	}
+}
+
+func TestParentsAreReferences(t *testing.T) {
+	doc := newDocument(testUri, "schema Base { };\nschema Page<T> extends Base includes Base { items: Array<T> };")
+
+	if references := doc.References["Base"]; len(references) != 3 {
+		t.Errorf("got %d references to Base, expected the declaration and both parents", len(references))
+	}
+
+	if len(doc.References["T"]) != 0 || len(doc.References["extends"]) != 0 {
+		t.Errorf("got %v, expected generics and modifiers not to be references", doc.References)
+	}
+}
+
+func TestAliasTypesAreReferences(t *testing.T) {
+	doc := newDocument(testUri, "alias Id = Int64;\nalias Ids = Array<Id> @length(1, 10);\nschema A { ids: Ids @nonEmpty };")
+
+	if len(doc.References["Id"]) != 2 || len(doc.References["Ids"]) != 2 {
+		t.Errorf("got %v, expected aliases to be referenced by name", doc.References)
+	}
+
+	if doc.Kinds["Ids"] != aliasSymbol || len(doc.References["length"]) != 0 || len(doc.References["nonEmpty"]) != 0 || len(doc.Diagnostics) != 0 {
+		t.Errorf("got %v, expected annotations not to be references", doc.Diagnostics)
+	}
+}
+
+func TestConstTypesAreReferences(t *testing.T) {
+	doc := newDocument(testUri, "alias Size = Int32;\nconst MAX_SIZE: Size = 100;\nconst TOPIC: String = \"orders\";")
+
+	if len(doc.References["Size"]) != 2 || doc.Kinds["MAX_SIZE"] != constSymbol || len(doc.Diagnostics) != 0 {
+		t.Errorf("got %v and %v, expected the type of the constant to be a reference", doc.References, doc.Diagnostics)
+	}
+}
+
+func TestDocumentStartingWithLiteral(t *testing.T) {
+	doc := newDocument(testUri, "Foo")
+
+	if len(doc.Diagnostics) == 0 {
+		t.Errorf("got no diagnostics, expected the incomplete document to be reported")
+	}
+}
+
+func TestRename(t *testing.T) {
+	rename := positionRequest(1, "textDocument/rename", 11, 8)
+	rename["params"].(map[string]interface{})["newName"] = "Account"
+
+	invalid := positionRequest(2, "textDocument/rename", 11, 8)
+	invalid["params"].(map[string]interface{})["newName"] = "Int32"
+
+	responses := session(t, openDocument(), rename, invalid)
+
+	changes := resultOf(t, responses, 1).(map[string]interface{})["changes"].(map[string]interface{})
+
+	if edits := changes[testUri].([]interface{}); len(edits) != 2 {
+		t.Errorf("got %d edits, expected the declaration and the usage of User", len(edits))
+	}
+
+	for _, response := range responses {
+		if response["id"] == float64(2) && response["error"] == nil {
+			t.Errorf("got %v, expected renaming to a primitive to fail", response)
+		}
+	}
+}
+
+func TestCompletion(t *testing.T) {
+	responses := session(t, openDocument(), positionRequest(1, "textDocument/completion", 8, 12))
+	labels := make(map[string]bool)
+
+	for _, item := range resultOf(t, responses, 1).([]interface{}) {
+		labels[item.(map[string]interface{})["label"].(string)] = true
+	}
+
+	for _, expected := range []string{"String", "Int32", "schema", "enum", "Status", "Page", "User"} {
+		if !labels[expected] {
+			t.Errorf("got no completion for '%s'", expected)
+		}
+	}
+}
+
+func TestFormatting(t *testing.T) {
+	responses := session(t,
+		map[string]interface{}{
+			"method": "textDocument/didOpen",
+			"params": map[string]interface{}{
+				"textDocument": map[string]interface{}{"uri": testUri, "text": "schema A { a: String, };"},
+			},
+		},
+		map[string]interface{}{
+			"id":     1,
+			"method": "textDocument/formatting",
+			"params": map[string]interface{}{"textDocument": map[string]interface{}{"uri": testUri}},
+		},
+	)
+
+	edits := resultOf(t, responses, 1).([]interface{})
+
+	if len(edits) != 1 {
+		t.Fatalf("got %d edits, expected 1", len(edits))
+	}
+
+	if newText := edits[0].(map[string]interface{})["newText"]; newText != "schema A\n{\n    a: String\n};\n" {
+		t.Errorf("got %q, expected the canonical source", newText)
+	}
+}
+
+func TestHandlerPanicIsRecovered(t *testing.T) {
+	handlers["test/panic"] = func(s *Server, params json.RawMessage) (interface{}, error) {
+		panic("boom")
+	}
+	defer delete(handlers, "test/panic")
+
+	responses := session(t,
+		map[string]interface{}{"id": 1, "method": "test/panic"},
+		map[string]interface{}{"id": 2, "method": "shutdown"},
+	)
+
+	if len(responses) != 2 {
+		t.Fatalf("got %d responses, expected the server to keep running after the panic", len(responses))
+	}
+
+	if responseError, hasError := responses[0]["error"].(map[string]interface{}); !hasError || int(responseError["code"].(float64)) != ErrInternalError {
+		t.Errorf("got %v, expected an internal error", responses[0])
+	}
+}
+
+func TestReadMessageRejectsInvalidLengths(t *testing.T) {
+	for _, length := range []string{"-1", "1099511627776", "abc"} {
+		reader := bufio.NewReader(strings.NewReader("Content-Length: " + length + "\r\n\r\n{}"))
+
+		if _, err := readMessage(reader); err == nil {
+			t.Errorf("got no error, expected Content-Length %s to be rejected", length)
+		}
+	}
+}
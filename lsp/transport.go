@@ -0,0 +1,58 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+)
+
+// maxContentLength caps the body of a message so a broken header can't make the
+// server allocate more than any schema would need
+const maxContentLength = 64 << 20
+
+// readMessage reads a single base protocol message, a set of headers followed
+// by a body whose size is given by Content-Length
+func readMessage(reader *bufio.Reader) ([]byte, error) {
+	headers, err := textproto.NewReader(reader).ReadMIMEHeader()
+
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(headers.Get("Content-Length"))
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length header: %w", err)
+	}
+
+	if length < 0 || length > maxContentLength {
+		return nil, fmt.Errorf("invalid Content-Length header: %d is not between 0 and %d", length, maxContentLength)
+	}
+
+	body := make([]byte, length)
+
+	if _, err := io.ReadFull(reader, body); err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+func writeMessage(writer io.Writer, payload interface{}) error {
+	body, err := json.Marshal(payload)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(writer, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+
+	_, err = writer.Write(body)
+
+	return err
+}
@@ -1,16 +1,41 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"github.com/Haato3o/eskema/cli"
-	"github.com/Haato3o/eskema/core/parser"
-	"github.com/Haato3o/eskema/core/syntax"
 	"github.com/Haato3o/eskema/core/visualization"
+	"github.com/Haato3o/eskema/pkg/eskema"
 	"log"
 	"os"
+	"path/filepath"
 )
 
 func main() {
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "import":
+			runImport(os.Args[2:])
+			return
+		case "infer":
+			runInfer(os.Args[2:])
+			return
+		case "fmt":
+			runFmt(os.Args[2:])
+			return
+		case "diff":
+			runDiff(os.Args[2:])
+			return
+		case "lsp":
+			runLsp(os.Args[2:])
+			return
+		case "generate":
+			runGenerate(os.Args[2:])
+			return
+		}
+	}
+
 	args := cli.ParseArguments()
 
 	if args.ShouldPrintSupportedLanguages {
@@ -22,29 +47,116 @@ func main() {
 		log.Fatalln(err)
 	}
 
-	lexer := syntax.NewLexerFromFile(args.FileName)
-	tokens := lexer.Lex()
-	eskemaParser := parser.New(tokens)
-	ast := eskemaParser.Parse()
-
-	if hasErrors := eskemaParser.VerifySyntaxErrors(); hasErrors {
+	if args.ShouldWatch {
+		watchFile(args)
 		return
 	}
 
+	source, err := os.ReadFile(args.FileName)
+
+	if err != nil {
+		log.Fatalln(err)
+	}
+
+	ast, found, err := eskema.Parse(eskema.Source{Name: args.FileName, Content: source})
+
+	if len(found) > 0 {
+		reportDiagnostics(args.DiagnosticsFormat, found)
+	}
+
+	if err != nil {
+		os.Exit(1)
+	}
+
 	if args.ShouldPrintAST {
 		visualization.VisualizeTree(ast)
 	}
 
-	emitter, err := cli.GetLanguageEmitter(args.Language)
+	target := newTarget(args)
+	files, found, err := eskema.Emit(context.Background(), filepath.Base(args.FileName), ast, target)
+
+	if len(found) > 0 {
+		reportDiagnostics(args.DiagnosticsFormat, found)
+	}
+
+	if err != nil {
+		if len(found) == 0 {
+			log.Println(err)
+		}
+
+		os.Exit(1)
+	}
+
+	files, err = outputFiles(args.Output, files)
 
 	if err != nil {
 		log.Fatalln(err)
 	}
 
-	code := emitter.Emit(ast)
+	if args.ShouldCheck {
+		staleFiles := 0
+
+		for _, file := range files {
+			isUpToDate, err := checkGeneratedFile(file.Name, file.Content)
+
+			if err != nil {
+				log.Fatalln(err)
+			}
+
+			if !isUpToDate {
+				staleFiles++
+			}
+		}
+
+		if staleFiles > 0 {
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	for _, file := range files {
+		writeOutput(file.Name, file.Content)
+	}
+}
+
+func newTarget(args *cli.EskemaArguments) *eskema.Target {
+	target := &eskema.Target{Language: args.Language}
 
-	if args.Output != "" {
-		file, _ := os.OpenFile(args.Output, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+	if args.Templates != "" {
+		target.Templates = os.DirFS(args.Templates)
+	}
+
+	return target
+}
+
+// outputFiles names the generated files after --output, targets that generate
+// more than one file write them inside of it as a directory
+func outputFiles(output string, files []*eskema.File) ([]*eskema.File, error) {
+	if len(files) == 1 {
+		return []*eskema.File{{Name: output, Content: files[0].Content}}, nil
+	}
+
+	if output == "" {
+		return nil, fmt.Errorf("%d files were generated, pass the directory to write them to with --output", len(files))
+	}
+
+	named := make([]*eskema.File, 0, len(files))
+
+	for _, file := range files {
+		named = append(named, &eskema.File{
+			Name:    filepath.Join(output, filepath.FromSlash(file.Name)),
+			Content: file.Content,
+		})
+	}
+
+	return named, nil
+}
+
+func writeOutput(output string, code string) {
+	if output != "" {
+		_ = os.MkdirAll(filepath.Dir(output), 0755)
+		file, _ := os.OpenFile(output, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
 
 		_, _ = file.WriteString(code)
 
@@ -52,5 +164,4 @@ func main() {
 	} else {
 		println(code)
 	}
-
 }
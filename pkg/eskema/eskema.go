@@ -0,0 +1,192 @@
+// Package eskema compiles eskema schemas into code, it is what the eskema
+// command uses and is meant for tools that want to generate code without
+// running it. Nothing in this package writes to the file system or exits.
+package eskema
+
+import (
+	"context"
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/syntax"
+	"github.com/Haato3o/eskema/emitter"
+	"github.com/Haato3o/eskema/emitter/templates"
+	"path"
+	"strings"
+)
+
+// Source is a schema to compile, Name is used in diagnostics and to name the
+// files generated from it
+type Source struct {
+	Name    string
+	Content []byte
+}
+
+// File is a generated file, its name is the name of the source with the
+// extension of the target or the one chosen by the plugin, inside of the
+// directory of the target
+type File struct {
+	Name    string
+	Content string
+}
+
+type Options struct {
+	// IsWarningFatal fails the compilation on warnings as well as on errors
+	IsWarningFatal bool
+	// Emitter is used by Compile, targets given to Build have their own
+	Emitter emitter.Options
+}
+
+// Parse builds the tree of a single source, the error is a parser.SyntaxErrors
+// with the diagnostics that prevent the tree from being used
+func Parse(source Source) (*parser.EskemaTree, []*syntax.Diagnostic, error) {
+	eskemaParser := parser.New(syntax.NewLexer(source.Content, source.Name).Lex())
+	tree := eskemaParser.Parse()
+
+	if errs := eskemaParser.Errors(); len(errs) > 0 {
+		return nil, eskemaParser.Diagnostics(), parser.SyntaxErrors(errs)
+	}
+
+	return tree, eskemaParser.Diagnostics(), nil
+}
+
+// Compile generates the files of each source, the diagnostics of every source
+// are returned even when the compilation fails. Sources with errors fail the
+// compilation with a parser.SyntaxErrors and no files are returned
+func Compile(ctx context.Context, sources []Source, target string, options *Options) ([]*File, []*syntax.Diagnostic, error) {
+	if options == nil {
+		options = &Options{}
+	}
+
+	return Build(ctx, sources, []*Target{{Language: target, Options: options.Emitter}}, options)
+}
+
+// Build parses every source once and generates its files for each target, in
+// the order of the targets
+func Build(ctx context.Context, sources []Source, targets []*Target, options *Options) ([]*File, []*syntax.Diagnostic, error) {
+	if options == nil {
+		options = &Options{}
+	}
+
+	for _, target := range targets {
+		if err := target.Verify(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	trees := make([]*parser.EskemaTree, 0, len(sources))
+	found := make([]*syntax.Diagnostic, 0)
+	errs := make(parser.SyntaxErrors, 0)
+
+	for _, source := range sources {
+		if err := ctx.Err(); err != nil {
+			return nil, found, err
+		}
+
+		tree, diagnostics, err := Parse(source)
+		found = append(found, diagnostics...)
+		trees = append(trees, tree)
+
+		if err != nil {
+			errs = append(errs, err.(parser.SyntaxErrors)...)
+			continue
+		}
+
+		if options.IsWarningFatal {
+			errs = append(errs, warnings(diagnostics)...)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, found, errs
+	}
+
+	files := make([]*File, 0, len(sources)*len(targets))
+
+	for _, target := range targets {
+		for i, source := range sources {
+			generated, diagnostics, err := Emit(ctx, source.Name, trees[i], target)
+			found = append(found, diagnostics...)
+
+			if err != nil {
+				return nil, found, err
+			}
+
+			if errs := warnings(diagnostics); options.IsWarningFatal && len(errs) > 0 {
+				return nil, found, errs
+			}
+
+			files = append(files, generated...)
+		}
+	}
+
+	return files, found, nil
+}
+
+// Emit generates the files of a single tree that was parsed from sourceName,
+// it lets callers that keep trees around avoid parsing them again. Built in
+// languages generate a single file while plugins and templates may generate
+// any number of them, diagnostics reported by plugins are returned and their
+// errors fail the emission with a parser.SyntaxErrors
+func Emit(ctx context.Context, sourceName string, tree *parser.EskemaTree, target *Target) ([]*File, []*syntax.Diagnostic, error) {
+	if err := target.Verify(); err != nil {
+		return nil, nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if executable, isPlugin := pluginExecutable(target.Language); isPlugin {
+		return emitPlugin(ctx, executable, sourceName, tree, target)
+	}
+
+	if target.Language == TemplateLanguage {
+		files, err := emitTemplates(sourceName, tree, target)
+
+		return files, nil, err
+	}
+
+	language := supportedLanguages[target.Language]
+
+	return []*File{{
+		Name:    path.Join(target.Directory, outputName(sourceName, language.extension)),
+		Content: language.newEmitter(target.Options).Emit(tree),
+	}}, nil, nil
+}
+
+func emitTemplates(sourceName string, tree *parser.EskemaTree, target *Target) ([]*File, error) {
+	templateEmitter, err := templates.New(target.Templates, target.Options)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rendered, err := templateEmitter.Emit(sourceName, tree)
+
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*File, 0, len(rendered))
+
+	for _, file := range rendered {
+		files = append(files, &File{Name: path.Join(target.Directory, file.Name), Content: file.Content})
+	}
+
+	return files, nil
+}
+
+func warnings(diagnostics []*syntax.Diagnostic) parser.SyntaxErrors {
+	errs := make(parser.SyntaxErrors, 0)
+
+	for _, diagnostic := range diagnostics {
+		if diagnostic.Severity == syntax.SeverityWarning {
+			errs = append(errs, diagnostic)
+		}
+	}
+
+	return errs
+}
+
+func outputName(sourceName string, extension string) string {
+	return strings.TrimSuffix(sourceName, path.Ext(sourceName)) + extension
+}
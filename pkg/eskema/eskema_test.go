@@ -0,0 +1,85 @@
+package eskema
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var userSource = Source{Name: "user.skm", Content: []byte("schema User { name: String };")}
+
+func TestCompileRejectsUnsupportedTargets(t *testing.T) {
+	_, _, err := Compile(context.Background(), []Source{userSource}, "cobol", nil)
+
+	if !errors.Is(err, ErrUnsupportedTarget) {
+		t.Errorf("got %v, expected %v", err, ErrUnsupportedTarget)
+	}
+}
+
+func TestCompileStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, _, err := Compile(ctx, []Source{userSource}, "kotlin", nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, expected %v", err, context.Canceled)
+	}
+}
+
+func TestCompileUsesNewEmitters(t *testing.T) {
+	first, _, err := Compile(context.Background(), []Source{userSource}, "golang", nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, _, err := Compile(context.Background(), []Source{userSource, userSource}, "golang", nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range second {
+		if file.Content != first[0].Content {
+			t.Errorf("got\n%s\nexpected\n%s", file.Content, first[0].Content)
+		}
+	}
+}
+
+func TestCompileRunsPlugins(t *testing.T) {
+	goTool, err := exec.LookPath("go")
+
+	if err != nil {
+		t.Skip("building the reference plugin needs the go tool")
+	}
+
+	directory := t.TempDir()
+	build := exec.Command(goTool, "build", "-o", filepath.Join(directory, "eskema-gen-markdown"), "github.com/Haato3o/eskema/examples/plugins/eskema-gen-markdown")
+
+	if output, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, output)
+	}
+
+	t.Setenv("PATH", directory)
+
+	target := &Target{Language: "plugin:eskema-gen-markdown", Directory: "docs"}
+	files, found, err := Build(context.Background(), []Source{userSource}, []*Target{target}, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(files) != 1 || files[0].Name != "docs/user.md" || !strings.Contains(files[0].Content, "| `name` | `String` | yes |") {
+		t.Errorf("got %+v, expected the documentation of user.skm", files)
+	}
+
+	if len(found) != 1 || found[0].Code != "MD0001" || found[0].Span.Start.Filename != "user.skm" {
+		t.Errorf("got %v, expected the plugin to report the undocumented schema", found)
+	}
+
+	if _, _, err := Compile(context.Background(), []Source{userSource}, "plugin:eskema-gen-missing", nil); !errors.Is(err, ErrUnsupportedTarget) {
+		t.Errorf("got %v, expected plugins missing from PATH to be unsupported", err)
+	}
+}
@@ -0,0 +1,87 @@
+package eskema_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/pkg/eskema"
+	"testing/fstest"
+)
+
+func ExampleCompile() {
+	sources := []eskema.Source{
+		{Name: "user.skm", Content: []byte("schema User { name: String, age: Int32? };")},
+	}
+
+	files, _, err := eskema.Compile(context.Background(), sources, "kotlin", nil)
+
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, file := range files {
+		fmt.Println(file.Name)
+		fmt.Print(file.Content)
+	}
+
+	// Output:
+	// user.kt
+	// package com.example
+	//
+	// data class User(
+	//     val name: String,
+	//     val age: Int?
+	// )
+}
+
+func ExampleCompile_diagnostics() {
+	sources := []eskema.Source{
+		{Name: "user.skm", Content: []byte("schema User { name: String }")},
+	}
+
+	_, found, err := eskema.Compile(context.Background(), sources, "kotlin", nil)
+
+	var syntaxErrors parser.SyntaxErrors
+
+	fmt.Println(errors.As(err, &syntaxErrors))
+
+	for _, diagnostic := range found {
+		fmt.Println(diagnostic)
+	}
+
+	// Output:
+	// true
+	// user.skm [1:29] error[E0002]: expected ';', got 'EOF'
+}
+
+func ExampleSourcesFromFS() {
+	fsys := fstest.MapFS{
+		"schemas/user.skm":  {Data: []byte("schema User { name: String };")},
+		"schemas/state.skm": {Data: []byte("enum State { ACTIVE, INACTIVE };")},
+		"schemas/README.md": {Data: []byte("# Schemas")},
+	}
+
+	sources, err := eskema.SourcesFromFS(fsys, "schemas/*.skm")
+
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	files, _, err := eskema.Compile(context.Background(), sources, "zod", nil)
+
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	for _, file := range files {
+		fmt.Println(file.Name)
+	}
+
+	// Output:
+	// schemas/state.ts
+	// schemas/user.ts
+}
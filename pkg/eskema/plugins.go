@@ -0,0 +1,82 @@
+package eskema
+
+import (
+	"context"
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/syntax"
+	"github.com/Haato3o/eskema/emitter/plugin"
+	"path"
+	"strings"
+)
+
+var pluginSeverities = map[string]syntax.Severity{
+	"error":   syntax.SeverityError,
+	"warning": syntax.SeverityWarning,
+	"note":    syntax.SeverityNote,
+}
+
+func pluginExecutable(language string) (string, bool) {
+	if !strings.HasPrefix(language, plugin.Prefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(language, plugin.Prefix), true
+}
+
+// emitPlugin sends the tree to the plugin, the files it generates are put
+// inside of the directory of the target like the ones of built in languages
+func emitPlugin(ctx context.Context, executable string, sourceName string, tree *parser.EskemaTree, target *Target) ([]*File, []*syntax.Diagnostic, error) {
+	response, err := plugin.Run(ctx, executable, plugin.NewRequest(sourceName, tree, target.Options))
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	found := make([]*syntax.Diagnostic, 0, len(response.Diagnostics))
+	errs := make(parser.SyntaxErrors, 0)
+
+	for _, reported := range response.Diagnostics {
+		diagnostic := &syntax.Diagnostic{
+			Severity: pluginSeverities[reported.Severity],
+			Code:     reported.Code,
+			Message:  reported.Message,
+			Span:     sourceSpan(sourceName, reported.Span),
+		}
+
+		found = append(found, diagnostic)
+
+		if diagnostic.Severity == syntax.SeverityError {
+			errs = append(errs, diagnostic)
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, found, errs
+	}
+
+	files := make([]*File, 0, len(response.Files))
+
+	for _, file := range response.Files {
+		files = append(files, &File{Name: path.Join(target.Directory, file.Name), Content: file.Content})
+	}
+
+	return files, found, nil
+}
+
+// sourceSpan points diagnostics without a span at the start of the source, so
+// they are still reported with the name of the file
+func sourceSpan(sourceName string, span *plugin.Span) syntax.Span {
+	if span == nil {
+		return syntax.Span{Start: &syntax.Metadata{Filename: sourceName, Line: 1, Column: 1}}
+	}
+
+	return syntax.Span{
+		Start: &syntax.Metadata{
+			Filename: sourceName,
+			Offset:   span.Offset,
+			Line:     span.Line,
+			Column:   span.Column,
+		},
+		Length: span.Length,
+	}
+}
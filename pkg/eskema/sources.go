@@ -0,0 +1,45 @@
+package eskema
+
+import (
+	"github.com/Haato3o/eskema/core/utils"
+	"io/fs"
+	"sort"
+)
+
+// SourcesFromFS reads every file matching the patterns, see utils.Glob for their
+// syntax. Files matched by more than one pattern are only read once
+func SourcesFromFS(fsys fs.FS, patterns ...string) ([]Source, error) {
+	names := make([]string, 0)
+	isMatched := make(map[string]bool)
+
+	for _, pattern := range patterns {
+		matches, err := utils.Glob(fsys, pattern)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range matches {
+			if !isMatched[name] {
+				isMatched[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+
+	sources := make([]Source, 0, len(names))
+
+	for _, name := range names {
+		content, err := fs.ReadFile(fsys, name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		sources = append(sources, Source{Name: name, Content: content})
+	}
+
+	return sources, nil
+}
@@ -0,0 +1,99 @@
+package eskema
+
+import (
+	"errors"
+	"fmt"
+	"github.com/Haato3o/eskema/emitter"
+	"github.com/Haato3o/eskema/emitter/languages"
+	"io/fs"
+	"os/exec"
+	"sort"
+)
+
+var (
+	ErrUnsupportedTarget = errors.New("target is not supported")
+	ErrMissingTemplates  = errors.New("the template language needs a directory of templates")
+)
+
+// TemplateLanguage renders the templates of the target instead of emitting a
+// language
+const TemplateLanguage = "template"
+
+type language struct {
+	newEmitter func(options emitter.Options) emitter.LanguageCodeEmitter
+	extension  string
+}
+
+// supportedLanguages build a new emitter for every source, emitters keep the
+// code they generate in a buffer and can't be shared
+var supportedLanguages = map[string]language{
+	"kotlin": {languages.NewKotlinEmitter, ".kt"},
+	"csharp": {languages.NewCSharpEmitter, ".cs"},
+	"golang": {languages.NewGoLangEmitter, ".go"},
+	"swift":  {languages.NewSwiftEmitter, ".swift"},
+	"zod":    {languages.NewZodEmitter, ".ts"},
+}
+
+// Target is a language to generate code for and where to put it
+type Target struct {
+	Language string
+	// Directory is prepended to the names of the generated files
+	Directory string
+	Options   emitter.Options
+	// Templates are rendered by the template language, see the templates
+	// package for how they are named
+	Templates fs.FS
+}
+
+func (t *Target) Verify() error {
+	if executable, isPlugin := pluginExecutable(t.Language); isPlugin {
+		if _, err := exec.LookPath(executable); err != nil {
+			return fmt.Errorf("%w: '%s', %v", ErrUnsupportedTarget, t.Language, err)
+		}
+
+		return t.Options.Verify()
+	}
+
+	if t.Language == TemplateLanguage {
+		if t.Templates == nil {
+			return ErrMissingTemplates
+		}
+
+		return t.Options.Verify()
+	}
+
+	if _, isSupported := supportedLanguages[t.Language]; !isSupported {
+		return fmt.Errorf("%w: '%s'", ErrUnsupportedTarget, t.Language)
+	}
+
+	return t.Options.Verify()
+}
+
+// Targets lists the names of every built in language in alphabetical order,
+// plugins are targeted with plugin:<executable>
+func Targets() []string {
+	names := make([]string, 0, len(supportedLanguages)+1)
+	names = append(names, TemplateLanguage)
+
+	for name := range supportedLanguages {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// NewEmitter creates an emitter for the built in language, every call returns a
+// new one. Plugins generate files on their own and have no emitter
+func NewEmitter(name string, options emitter.Options) (emitter.LanguageCodeEmitter, error) {
+	if _, isSupported := supportedLanguages[name]; !isSupported {
+		return nil, fmt.Errorf("%w: '%s'", ErrUnsupportedTarget, name)
+	}
+
+	if err := options.Verify(); err != nil {
+		return nil, err
+	}
+
+	return supportedLanguages[name].newEmitter(options), nil
+}
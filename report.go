@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"github.com/Haato3o/eskema/cli"
+	"github.com/Haato3o/eskema/core/diagnostics"
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/syntax"
+	"log"
+	"os"
+)
+
+// reportDiagnostics prints the diagnostics to STDERR, the text format is only
+// colored when STDERR is a terminal
+func reportDiagnostics(format string, found []*syntax.Diagnostic) {
+	if format == cli.JsonDiagnostics {
+		if err := diagnostics.WriteJson(os.Stderr, found); err != nil {
+			log.Println(err)
+		}
+
+		return
+	}
+
+	diagnostics.NewRenderer(diagnostics.IsTerminal(os.Stderr)).RenderAll(os.Stderr, found)
+}
+
+// reportError renders syntax errors and single diagnostics, like the ones of
+// importers, as diagnostics and logs anything else
+func reportError(format string, err error) {
+	var syntaxErrors parser.SyntaxErrors
+
+	if !errors.As(err, &syntaxErrors) {
+		var diagnostic *syntax.Diagnostic
+
+		if errors.As(err, &diagnostic) {
+			reportDiagnostics(format, []*syntax.Diagnostic{diagnostic})
+		} else {
+			log.Println(err)
+		}
+
+		return
+	}
+
+	found := make([]*syntax.Diagnostic, 0, len(syntaxErrors))
+
+	for _, syntaxError := range syntaxErrors {
+		var diagnostic *syntax.Diagnostic
+
+		if errors.As(syntaxError, &diagnostic) {
+			found = append(found, diagnostic)
+		} else {
+			log.Println(syntaxError)
+		}
+	}
+
+	reportDiagnostics(format, found)
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/Haato3o/eskema/cli"
+	"github.com/Haato3o/eskema/core/parser"
+	"github.com/Haato3o/eskema/core/project"
+	"github.com/Haato3o/eskema/core/watch"
+	"github.com/Haato3o/eskema/pkg/eskema"
+	"io/fs"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+)
+
+// watchSession generates the files of the sources that changed again, schemas
+// don't depend on each other so the other sources are neither parsed nor
+// emitted
+type watchSession struct {
+	diagnosticsFormat string
+	// directory is what the names of the generated files are relative to
+	directory string
+	targets   []*eskema.Target
+	write     func(files []*eskema.File) error
+}
+
+func (s *watchSession) rebuild(changed []string) {
+	for _, fileName := range changed {
+		s.rebuildFile(fileName)
+	}
+}
+
+func (s *watchSession) rebuildFile(fileName string) {
+	content, err := os.ReadFile(fileName)
+
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("%s was removed, the files generated from it were kept\n", fileName)
+		return
+	} else if err != nil {
+		log.Println(err)
+		return
+	}
+
+	tree, found, err := eskema.Parse(eskema.Source{Name: fileName, Content: content})
+
+	if len(found) > 0 {
+		reportDiagnostics(s.diagnosticsFormat, found)
+	}
+
+	if err != nil {
+		log.Printf("%s has errors, the files generated from it were kept\n", fileName)
+		return
+	}
+
+	if err := s.emit(fileName, tree); err != nil {
+		log.Println(err)
+		return
+	}
+
+	log.Printf("generated %s\n", fileName)
+}
+
+func (s *watchSession) emit(fileName string, tree *parser.EskemaTree) error {
+	sourceName, err := filepath.Rel(s.directory, fileName)
+
+	if err != nil {
+		return err
+	}
+
+	for _, target := range s.targets {
+		files, found, err := eskema.Emit(context.Background(), filepath.ToSlash(sourceName), tree, target)
+
+		if len(found) > 0 {
+			reportDiagnostics(s.diagnosticsFormat, found)
+		}
+
+		if err != nil && len(found) > 0 {
+			return fmt.Errorf("%s reported errors for %s", target.Language, fileName)
+		} else if err != nil {
+			return err
+		}
+
+		if err := s.write(files); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// watchFile generates the output of a single schema every time it changes
+func watchFile(args *cli.EskemaArguments) {
+	target := newTarget(args)
+
+	if err := target.Verify(); err != nil {
+		log.Fatalln(err)
+	}
+
+	session := &watchSession{
+		diagnosticsFormat: args.DiagnosticsFormat,
+		directory:         filepath.Dir(args.FileName),
+		targets:           []*eskema.Target{target},
+		write: func(files []*eskema.File) error {
+			files, err := outputFiles(args.Output, files)
+
+			if err != nil {
+				return err
+			}
+
+			for _, file := range files {
+				writeOutput(file.Name, file.Content)
+			}
+
+			return nil
+		},
+	}
+
+	watcher := watch.New(func() ([]string, error) {
+		return []string{args.FileName}, nil
+	})
+
+	runWatcher(watcher, args.FileName, session.rebuild)
+}
+
+// watchProject watches the inputs of the project and the project file itself,
+// changing the project file generates every input again with the new targets
+func watchProject(projectFileName string, diagnosticsFormat string) {
+	var eskemaProject *project.Project
+
+	session := &watchSession{
+		diagnosticsFormat: diagnosticsFormat,
+		write: func(files []*eskema.File) error {
+			for _, file := range files {
+				if err := writeGeneratedFile(eskemaProject, file); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	watcher := watch.New(func() ([]string, error) {
+		if eskemaProject == nil {
+			return []string{projectFileName}, nil
+		}
+
+		inputs, err := projectInputFileNames(eskemaProject)
+
+		return append(inputs, projectFileName), err
+	})
+
+	runWatcher(watcher, projectFileName, func(changed []string) {
+		for _, fileName := range changed {
+			if fileName != projectFileName {
+				continue
+			}
+
+			reloaded, err := project.Load(projectFileName)
+
+			if err != nil {
+				log.Println(err)
+				return
+			}
+
+			inputs, err := projectInputFileNames(reloaded)
+
+			if err != nil {
+				log.Println(err)
+				return
+			}
+
+			eskemaProject = reloaded
+			session.directory = eskemaProject.Directory
+			session.targets = projectTargets(eskemaProject)
+
+			// the targets may have changed, the next poll reports every input
+			// once they are forgotten
+			watcher.Forget(inputs...)
+			return
+		}
+
+		if eskemaProject != nil {
+			session.rebuild(changed)
+		}
+	})
+}
+
+func projectInputFileNames(eskemaProject *project.Project) ([]string, error) {
+	inputs, err := eskemaProject.InputFileNames()
+
+	if err != nil {
+		return nil, err
+	}
+
+	fileNames := make([]string, 0, len(inputs))
+
+	for _, input := range inputs {
+		fileNames = append(fileNames, filepath.Join(eskemaProject.Directory, filepath.FromSlash(input)))
+	}
+
+	return fileNames, nil
+}
+
+// runWatcher blocks until the watcher fails or the process is interrupted
+func runWatcher(watcher *watch.Watcher, name string, onChange func(changed []string)) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	log.Printf("watching %s, press Ctrl+C to stop\n", name)
+
+	if err := watcher.Run(ctx, onChange); err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatalln(err)
+	}
+}